@@ -5,17 +5,27 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/rewired-gh/polyoracle/internal/api"
+	"github.com/rewired-gh/polyoracle/internal/backfill"
 	"github.com/rewired-gh/polyoracle/internal/config"
+	"github.com/rewired-gh/polyoracle/internal/health"
 	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/metrics"
 	"github.com/rewired-gh/polyoracle/internal/models"
 	"github.com/rewired-gh/polyoracle/internal/monitor"
+	"github.com/rewired-gh/polyoracle/internal/notify"
 	"github.com/rewired-gh/polyoracle/internal/polymarket"
+	"github.com/rewired-gh/polyoracle/internal/resolution"
+	"github.com/rewired-gh/polyoracle/internal/status"
 	"github.com/rewired-gh/polyoracle/internal/storage"
+	"github.com/rewired-gh/polyoracle/internal/stream"
 	"github.com/rewired-gh/polyoracle/internal/telegram"
 )
 
@@ -36,7 +46,7 @@ func main() {
 	}
 
 	// Setup logging with level support
-	logger.Init(cfg.Logging.Level, cfg.Logging.Format)
+	logger.Init(cfg.Logging.Level, cfg.Logging.Format, cfg.Logging.Components)
 	logger.Info("Configuration loaded from %s", *configPath)
 
 	// Initialize storage
@@ -54,6 +64,9 @@ func main() {
 		}
 	}()
 
+	// Metrics are always collected; they are served when the HTTP server is enabled
+	mets := metrics.New()
+
 	// Initialize Polymarket client
 	polyClient := polymarket.NewClient(
 		cfg.Polymarket.GammaAPIURL,
@@ -65,16 +78,55 @@ func main() {
 			MaxIdleConns:        cfg.Polymarket.MaxIdleConns,
 			MaxIdleConnsPerHost: cfg.Polymarket.MaxIdleConnsPerHost,
 			IdleConnTimeout:     cfg.Polymarket.IdleConnTimeout,
+			OnResponse:          mets.ObserveAPIResponse,
+			OnRetry:             mets.ObserveAPIRetry,
 		},
 	)
 
 	// Initialize monitor
 	mon := monitor.New(store)
 
+	// Initialize price-history backfill for newly tracked markets
+	var backfiller *backfill.Backfiller
+	if cfg.Backfill.Enabled {
+		backfiller = backfill.New(polyClient, store, backfill.Config{
+			Lookback:     cfg.Backfill.Lookback,
+			Fidelity:     cfg.Polymarket.PollInterval,
+			RequestDelay: cfg.Backfill.RequestDelay,
+			MaxPerCycle:  cfg.Backfill.MaxPerCycle,
+		})
+	}
+
+	// Initialize resolution tracking for markets that leave the active feed
+	var resolver *resolution.Tracker
+	if cfg.Resolution.Enabled {
+		store.KeepPendingResolution(cfg.Resolution.MaxPendingAge)
+		resolver = resolution.New(polyClient, store, resolution.Config{
+			RecheckInterval: cfg.Resolution.RecheckInterval,
+			MaxPerCycle:     cfg.Resolution.MaxPerCycle,
+		})
+	}
+
+	// Track cycle results for /status
+	tracker := status.New(time.Now())
+
 	// Initialize Telegram client
 	var telegramClient *telegram.Client
 	if cfg.Telegram.Enabled {
-		telegramClient, err = telegram.NewClient(cfg.Telegram.BotToken, cfg.Telegram.ChatID, cfg.Telegram.MaxRetries, cfg.Telegram.RetryDelayBase)
+		opts := telegram.Options{
+			MaxRetries:     cfg.Telegram.MaxRetries,
+			RetryDelayBase: cfg.Telegram.RetryDelayBase,
+			AllowedChatIDs: cfg.Telegram.AllowedChatIDs,
+			Store:          store,
+			Status:         tracker,
+			Charts:         cfg.Telegram.Charts,
+			ExplainScores:  cfg.Telegram.ExplainScores,
+		}
+		if cfg.Telegram.Threads {
+			opts.ThreadWindow = cfg.DetectionWindow()
+			opts.FollowUpMinMove = cfg.Monitor.MinAbsChange
+		}
+		telegramClient, err = telegram.NewClient(cfg.Telegram.BotToken, cfg.Telegram.ChatID, opts)
 		if err != nil {
 			logger.Fatal("Failed to initialize Telegram client: %v", err)
 		}
@@ -96,17 +148,86 @@ func main() {
 		cancel()
 	}()
 
+	// Collect every enabled alert sink; each alert goes to all of them.
+	// Webhook retries are abandoned on shutdown
+	notifiers, emailNotifier, err := buildNotifiers(ctx, cfg, telegramClient, store, mon)
+	if err != nil {
+		logger.Fatal("Failed to initialize notifiers: %v", err)
+	}
+	for _, n := range notifiers {
+		logger.Info("Notifications enabled for %s", n.Destination())
+	}
+
 	// Start Telegram command listener
 	if cfg.Telegram.Enabled && telegramClient != nil {
 		telegramClient.ListenForCommands(ctx)
 	}
 
+	// Start the email digest loop (no-op unless digests are enabled). The final
+	// digest is sent on shutdown, before storage is closed.
+	digestDone := make(chan struct{})
+	if emailNotifier != nil {
+		go func() {
+			defer close(digestDone)
+			emailNotifier.Run(ctx)
+		}()
+	} else {
+		close(digestDone)
+	}
+
+	// Start the read-only JSON API, metrics and health endpoints
+	if cfg.Server.Enabled {
+		apiServer := api.New(store)
+		apiServer.Handle("GET /metrics", mets.Handler())
+		checker := health.New(tracker, store, health.Config{
+			PollInterval:    cfg.Polymarket.PollInterval,
+			StaleIntervals:  cfg.Server.ReadyStaleIntervals,
+			MaxEmptyFetches: cfg.Server.ReadyEmptyFetches,
+		})
+		apiServer.Handle("GET /healthz", http.HandlerFunc(checker.Healthz))
+		apiServer.Handle("GET /readyz", http.HandlerFunc(checker.Readyz))
+		go func() {
+			if err := apiServer.ListenAndServe(ctx, cfg.Server.ListenAddr); err != nil {
+				logger.Error("HTTP API stopped: %v", err)
+			}
+		}()
+		logger.Info("HTTP API listening on %s", cfg.Server.ListenAddr)
+	}
+
+	// Start real-time price ingestion; the tracked set is refreshed every cycle
+	var ingestor *stream.Ingestor
+	if cfg.Stream.Enabled {
+		ingestor = stream.New(store, stream.Config{
+			URL:                 cfg.Stream.URL,
+			MinSnapshotInterval: cfg.Stream.MinSnapshotInterval,
+			PingInterval:        cfg.Stream.PingInterval,
+			ReconnectMin:        cfg.Stream.ReconnectMin,
+			ReconnectMax:        cfg.Stream.ReconnectMax,
+		})
+		go ingestor.Run(ctx)
+		logger.Info("WebSocket price stream enabled (%s)", cfg.Stream.URL)
+	}
+
+	// Reload monitor thresholds and polymarket filters on file change or
+	// SIGHUP; each cycle picks up the configuration current at its start
+	watcher := config.NewWatcher(*configPath, cfg)
+	go watcher.Run(ctx)
+
+	// cycleConfig returns the configuration for the next cycle. Telegram's
+	// thread settings derive from hot monitor settings, so they follow it
+	cycleConfig := func() *config.Config {
+		c := watcher.Config()
+		if telegramClient != nil {
+			telegramClient.SetThreadSettings(c.DetectionWindow(), c.Monitor.MinAbsChange)
+		}
+		return c
+	}
+
 	// Start monitoring loop
-	effectiveWindow := time.Duration(cfg.Monitor.DetectionIntervals+1) * cfg.Polymarket.PollInterval
 	logger.Info("Starting monitoring service (interval: %v, detection_intervals: %d, effective_window: %v, sensitivity: %.2f, top_k: %d)",
 		cfg.Polymarket.PollInterval,
 		cfg.Monitor.DetectionIntervals,
-		effectiveWindow,
+		cfg.DetectionWindow(),
 		cfg.Monitor.Sensitivity,
 		cfg.Monitor.TopK,
 	)
@@ -118,49 +239,71 @@ func main() {
 
 	ticker := time.NewTicker(cfg.Polymarket.PollInterval)
 	defer ticker.Stop()
-
-	consecutiveFailures := 0
-
-	handleCycleResult := func(err error) {
+	tracker.SetNextRun(time.Now().Add(cfg.Polymarket.PollInterval))
+
+	handleCycleResult := func(start time.Time, err error) {
+		end := time.Now()
+		previousFailures := tracker.CycleFinished(start, end, err)
+		mets.ObserveCycle(end.Sub(start).Seconds(), err, tracker.Snapshot().ConsecutiveFailures)
+		if statsErr := mets.ObserveStorage(store); statsErr != nil {
+			logger.Warn("Failed to read database size for metrics: %v", statsErr)
+		}
 		if err != nil {
-			consecutiveFailures++
 			logger.Error("Monitoring cycle failed: %v", err)
-			if consecutiveFailures == 1 && cfg.Telegram.Enabled && telegramClient != nil {
-				if sendErr := telegramClient.SendError(err); sendErr != nil {
-					logger.Warn("Failed to send error notification to Telegram: %v", sendErr)
+			if previousFailures == 0 {
+				for _, n := range notifiers {
+					if sendErr := n.SendError(err); sendErr != nil {
+						logger.With(logger.SinkKey, n.Destination()).Warn("Failed to send error notification to %s: %v", n.Destination(), sendErr)
+					}
 				}
 			}
 		} else {
-			if consecutiveFailures > 0 && cfg.Telegram.Enabled && telegramClient != nil {
-				if sendErr := telegramClient.SendRecovery(consecutiveFailures); sendErr != nil {
-					logger.Warn("Failed to send recovery notification to Telegram: %v", sendErr)
+			if previousFailures > 0 {
+				for _, n := range notifiers {
+					if sendErr := n.SendRecovery(previousFailures); sendErr != nil {
+						logger.With(logger.SinkKey, n.Destination()).Warn("Failed to send recovery notification to %s: %v", n.Destination(), sendErr)
+					}
 				}
 			}
-			consecutiveFailures = 0
 		}
 	}
 
 	// Run initial poll immediately
 	logger.Debug("Running initial monitoring cycle")
-	handleCycleResult(runMonitoringCycle(ctx, polyClient, mon, store, telegramClient, cfg, time.Now()))
+	startTime := time.Now()
+	handleCycleResult(startTime, runMonitoringCycle(ctx, polyClient, mon, store, backfiller, ingestor, resolver, notifiers, mets, tracker, cycleConfig(), startTime))
 
 	for {
 		select {
 		case <-ctx.Done():
+			<-digestDone
 			logger.Info("Service stopped")
 			return
 
 		case tickTime := <-ticker.C:
 			logger.Debug("Starting scheduled monitoring cycle")
-			handleCycleResult(runMonitoringCycle(ctx, polyClient, mon, store, telegramClient, cfg, tickTime))
+			tracker.SetNextRun(tickTime.Add(cfg.Polymarket.PollInterval))
+			handleCycleResult(time.Now(), runMonitoringCycle(ctx, polyClient, mon, store, backfiller, ingestor, resolver, notifiers, mets, tracker, cycleConfig(), tickTime))
 
-			// Rotate old data
-			if err := store.RotateSnapshots(); err != nil {
+			// Rotate old data. Streamed snapshots arrive far more often than
+			// polled ones, so they are pruned by age instead of counting
+			// against the per-market cap
+			if err := store.RotateSnapshots(stream.Source); err != nil {
 				logger.Warn("Failed to rotate snapshots: %v", err)
 			}
+			if cfg.Stream.Retention > 0 {
+				if _, err := store.PruneSnapshots(stream.Source, time.Now().Add(-cfg.Stream.Retention)); err != nil {
+					logger.Warn("Failed to prune streamed snapshots: %v", err)
+				}
+			}
 			if err := store.RotateMarkets(); err != nil {
 				logger.Warn("Failed to rotate markets: %v", err)
 			}
+			if cfg.Storage.ChangeRetention > 0 {
+				if _, err := store.PruneChanges(time.Now().Add(-cfg.Storage.ChangeRetention)); err != nil {
+					logger.Warn("Failed to prune alert history: %v", err)
+				}
+			}
 		}
 	}
 }
@@ -170,15 +313,25 @@ func runMonitoringCycle(
 	polyClient *polymarket.Client,
 	mon *monitor.Monitor,
 	store *storage.Storage,
-	telegramClient *telegram.Client,
+	backfiller *backfill.Backfiller, // nil when backfill is disabled
+	ingestor *stream.Ingestor, // nil when streaming is disabled
+	resolver *resolution.Tracker, // nil when resolution tracking is disabled
+	notifiers []notify.Notifier,
+	mets *metrics.Metrics,
+	tracker *status.Tracker,
 	cfg *config.Config,
 	cycleTime time.Time, // tick time (or startup time for the initial cycle)
 ) error {
 	startTime := time.Now()
-	logger.Info("Starting monitoring cycle")
+	// Lines logged here and by the steps that take ctx (detection, scoring,
+	// routing, backfill and resolution) carry the cycle ID. Notifiers log
+	// without it; the delivery result of each sink is logged here with both
+	clog := logger.With(logger.CycleIDKey, newCycleID())
+	ctx = logger.NewContext(ctx, clog)
+	clog.Info("Starting monitoring cycle")
 
 	// Fetch events from Polymarket
-	logger.Debug("Fetching events from Polymarket API (categories: %v, limit: %d)", cfg.Polymarket.Categories, cfg.Polymarket.Limit)
+	clog.Debug("Fetching events from Polymarket API (categories: %v, limit: %d)", cfg.Polymarket.Categories, cfg.Polymarket.Limit)
 	events, err := polyClient.FetchEvents(
 		ctx,
 		cfg.Polymarket.Categories,
@@ -191,85 +344,80 @@ func runMonitoringCycle(
 	if err != nil {
 		return fmt.Errorf("failed to fetch events: %w", err)
 	}
-	logger.Info("Fetched %d events from %d categories", len(events), len(cfg.Polymarket.Categories))
+	clog.Info("Fetched %d events from %d categories", len(events), len(cfg.Polymarket.Categories))
+	tracker.FetchFinished(len(events))
 
-	// Update storage with new events and create snapshots
-	logger.Debug("Processing fetched events and creating snapshots")
-	newEvents := 0
-	updatedEvents := 0
-	for i := range events {
-		event := &events[i]
-
-		// Add or update event
-		existingEvent, err := store.GetMarket(event.ID)
+	// Enrich with CLOB order-book data (non-fatal: scoring falls back to no spread weight)
+	if cfg.Polymarket.FetchOrderBooks {
+		enriched, err := polyClient.EnrichWithOrderBooks(ctx, events)
 		if err != nil {
-			// Event doesn't exist, create it
-			if err := store.AddMarket(event); err != nil {
-				logger.Warn("Failed to add event %s: %v", event.ID, err)
-				continue
-			}
-			newEvents++
+			clog.Warn("Failed to fetch CLOB order books: %v", err)
 		} else {
-			// Update existing event
-			event.CreatedAt = existingEvent.CreatedAt
-			if err := store.UpdateMarket(event); err != nil {
-				logger.Warn("Failed to update event %s: %v", event.ID, err)
-				continue
-			}
-			updatedEvents++
+			clog.Debug("Enriched %d/%d markets with CLOB order-book data", enriched, len(events))
 		}
+	}
 
-		// Create snapshot for current probability.
-		// Use cycleTime (tick time) as the timestamp, not time.Now() after processing.
-		// This ensures snapshot ages are exact multiples of pollInterval, so the
-		// detection window math is not skewed by per-cycle processing latency.
-		snapshot := &models.Snapshot{
-			ID:             generateID(),
-			EventID:        event.ID,
-			YesProbability: event.YesProbability,
-			NoProbability:  event.NoProbability,
-			Timestamp:      cycleTime,
-			Source:         "polymarket-gamma-api",
+	// Upsert events and record one snapshot per tracked outcome in a single
+	// transaction, so a failed cycle leaves no partial data behind.
+	// Snapshots are stamped with cycleTime (tick time), not time.Now() after
+	// processing. This ensures snapshot ages are exact multiples of pollInterval,
+	// so the detection window math is not skewed by per-cycle processing latency.
+	// First-seen markets get CreatedAt = cycleTime so backfilled history (which
+	// ends at CreatedAt) never overlaps live snapshots.
+	clog.Debug("Processing fetched events and creating snapshots")
+	ingest, err := store.IngestCycle(cycleTime, events, cycleSnapshots(events))
+	if err != nil {
+		return fmt.Errorf("failed to store events: %w", err)
+	}
+	for id, rejectErr := range ingest.Rejected {
+		clog.With(logger.MarketIDKey, id).Warn("Skipped event %s: %v", id, rejectErr)
+	}
+	clog.Debug("Event processing complete: %d new, %d updated, %d snapshots",
+		ingest.New, ingest.Updated, ingest.Snapshots)
+	mets.ObserveIngest(len(events), ingest.New, ingest.Updated)
+
+	// Backfill price history for newly tracked markets so SNR and trajectory
+	// have a real baseline from the first cycle (non-fatal; resumes next cycle)
+	if backfiller != nil {
+		result, err := backfiller.Run(ctx)
+		if err != nil {
+			clog.Warn("Backfill interrupted: %v", err)
 		}
+		if result.Markets > 0 || result.Failed > 0 {
+			clog.Info("Backfilled %d markets (%d snapshots, %d failed)", result.Markets, result.Snapshots, result.Failed)
+		}
+	}
 
-		if err := store.AddSnapshot(snapshot); err != nil {
-			logger.Warn("Failed to add snapshot for event %s: %v", event.ID, err)
+	// Record resolutions of markets that were not returned this cycle (non-fatal;
+	// unresolved markets are rechecked after recheck_interval)
+	if resolver != nil {
+		result, err := resolver.Run(ctx, cycleTime)
+		if err != nil {
+			clog.Warn("Resolution check interrupted: %v", err)
+		}
+		if result.Resolved > 0 || result.Failed > 0 {
+			clog.Info("Checked %d markets for resolution (%d resolved, %d failed)", result.Checked, result.Resolved, result.Failed)
 		}
 	}
-	logger.Debug("Event processing complete: %d new, %d updated", newEvents, updatedEvents)
 
 	// Detect significant changes
 	allEvents, err := store.GetAllMarkets()
 	if err != nil {
 		return fmt.Errorf("failed to get events: %w", err)
 	}
-	// Window = (N+1) × pollInterval, not N × pollInterval.
-	// With cycleTime-stamped snapshots, the oldest snapshot from N cycles ago is
-	// exactly N×pollInterval old at tick time, but GetSnapshotsInWindow runs after
-	// processing completes (tick + τ), making it N×pollInterval + τ old. The extra
-	// interval absorbs τ so the boundary snapshot is never accidentally excluded.
-	detectionWindow := time.Duration(cfg.Monitor.DetectionIntervals+1) * cfg.Polymarket.PollInterval
-	logger.Debug("Detecting changes across %d total events (window: %v = (%d+1) × %v)",
+	if ingestor != nil {
+		ingestor.SetMarkets(allEvents)
+	}
+	// Window = (N+1) × pollInterval, not N × pollInterval (see Config.DetectionWindow)
+	detectionWindow := cfg.DetectionWindow()
+	clog.Debug("Detecting changes across %d total events (window: %v = (%d+1) × %v)",
 		len(allEvents), detectionWindow, cfg.Monitor.DetectionIntervals, cfg.Polymarket.PollInterval)
-	changes, detectionErrors, err := mon.DetectChanges(convertMarkets(allEvents), detectionWindow)
+	changes, err := mon.DetectChanges(ctx, convertMarkets(allEvents), detectionWindow)
 	if err != nil {
 		return fmt.Errorf("failed to detect changes: %w", err)
 	}
-	for _, detErr := range detectionErrors {
-		logger.Warn("Failed to detect changes for event %s: %v", detErr.EventID, detErr.Err)
-	}
-
-	// Clear old changes and store new ones
-	if err := store.ClearChanges(); err != nil {
-		logger.Warn("Failed to clear old changes: %v", err)
-	}
-	for i := range changes {
-		if err := store.AddChange(&changes[i]); err != nil {
-			logger.Warn("Failed to add change: %v", err)
-		}
-	}
 
-	logger.Info("Detected %d changes above floor", len(changes))
+	clog.Info("Detected %d changes above floor", len(changes))
 
 	// Score and rank changes using composite signal quality.
 	// The four factors (KL, volume, SNR, trajectory) are already window-agnostic:
@@ -277,42 +425,221 @@ func runMonitoringCycle(
 	// minScore by window duration is incorrect and creates a near-zero bar at 15m.
 	minScore := cfg.Monitor.MinCompositeScore()
 	marketsMap := buildMarketsMap(allEvents)
-	topGroups := mon.ScoreAndRank(changes, marketsMap, minScore, cfg.Monitor.TopK, cfg.Polymarket.Volume24hrMin, cfg.Monitor.MinAbsChange, cfg.Monitor.MinBaseProb)
+	scored := mon.ScoreChanges(ctx, changes, marketsMap, minScore, cfg.Polymarket.Volume24hrMin, cfg.Monitor.MinAbsChange, cfg.Monitor.MinBaseProb)
+
+	// Apply the watchlist (lower per-target bars) and mutes before ranking, so
+	// the alert history records what was actually eligible to alert
+	if watched, muted := mon.ApplyRouting(ctx, scored, time.Now()); watched > 0 || muted > 0 {
+		clog.Info("Routing: %d changes promoted by watches, %d silenced by mutes", watched, muted)
+	}
+
+	// Append every scored change to the alert history, passing or not
+	if err := store.AddChanges(scored); err != nil {
+		clog.Warn("Failed to record scored changes: %v", err)
+	}
+
+	topGroups := monitor.RankChanges(scored, cfg.Monitor.TopK)
+	mets.ObserveDetection(len(changes), len(topGroups))
 
-	// Suppress recently-sent markets (same direction, within cooldown window)
-	topGroups = mon.FilterRecentlySent(topGroups, detectionWindow)
+	// Suppress recently-sent markets (same direction, within cooldown window);
+	// sinks that support it report those continuing moves as follow-ups
+	topGroups, continuing := mon.SplitRecentlySent(topGroups, detectionWindow)
 
 	if len(topGroups) > 0 {
 		totalMarkets := 0
 		for _, g := range topGroups {
 			totalMarkets += len(g.Markets)
 		}
-		logger.Info("Scored changes: %d detected, %d groups (%d markets) passed quality bar (min_score=%.4f)",
-			len(changes), len(topGroups), totalMarkets, minScore)
-
-		if cfg.Telegram.Enabled && telegramClient != nil {
-			logger.Debug("Sending top %d event groups to Telegram", len(topGroups))
-			if err := telegramClient.Send(topGroups); err != nil {
-				logger.Error("Failed to send Telegram notification: %v", err)
-			} else {
-				logger.Info("Sent Telegram notification with top %d event groups", len(topGroups))
-				mon.RecordNotified(topGroups)
-			}
+		clog.Info("Scored changes: %d detected, %d scored, %d groups (%d markets) passed quality bar (min_score=%.4f)",
+			len(changes), len(scored), len(topGroups), totalMarkets, minScore)
+		for _, g := range topGroups {
+			clog.With(logger.EventIDKey, g.ID, logger.ScoreKey, g.BestScore).
+				Debug("Event group %q passed with %d markets", g.Title, len(g.Markets))
+		}
+
+		if len(notifiers) > 0 {
+			sendAlerts(clog, notifiers, topGroups, mon, store, mets)
 		} else {
-			logger.Debug("Changes detected but Telegram notifications disabled or client not initialized")
+			clog.Debug("Changes detected but no notifiers are enabled")
 		}
 	} else {
-		logger.Info("No changes above quality bar this cycle (min_score=%.4f)", minScore)
+		clog.Info("No changes above quality bar this cycle (min_score=%.4f)", minScore)
+	}
+	if len(continuing) > 0 {
+		sendFollowUps(clog, notifiers, continuing)
 	}
 
 	duration := time.Since(startTime)
-	logger.Info("Monitoring cycle completed in %v", duration)
+	clog.Info("Monitoring cycle completed in %v", duration)
 
 	return nil
 }
 
+// buildNotifiers returns every enabled alert sink, and the email notifier when
+// email is enabled so its digest loop can be started. telegramClient is nil
+// when Telegram is disabled.
+func buildNotifiers(ctx context.Context, cfg *config.Config, telegramClient *telegram.Client, store *storage.Storage, mon *monitor.Monitor) ([]notify.Notifier, *notify.Email, error) {
+	var notifiers []notify.Notifier
+	if telegramClient != nil {
+		notifiers = append(notifiers, telegramClient)
+	}
+	if cfg.Slack.Enabled {
+		slack, err := notify.NewSlack(cfg.Slack.WebhookURL, notify.HTTPConfig{
+			Timeout:        cfg.Slack.Timeout,
+			MaxRetries:     cfg.Slack.MaxRetries,
+			RetryDelayBase: cfg.Slack.RetryDelayBase,
+			Context:        ctx,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		notifiers = append(notifiers, slack)
+	}
+	if cfg.Discord.Enabled {
+		discord, err := notify.NewDiscord(cfg.Discord.WebhookURL, notify.HTTPConfig{
+			Timeout:        cfg.Discord.Timeout,
+			MaxRetries:     cfg.Discord.MaxRetries,
+			RetryDelayBase: cfg.Discord.RetryDelayBase,
+			Context:        ctx,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		notifiers = append(notifiers, discord)
+	}
+	if cfg.Webhook.Enabled {
+		webhook, err := notify.NewWebhook(cfg.Webhook.URL, cfg.Webhook.Secret, notify.HTTPConfig{
+			Timeout:        cfg.Webhook.Timeout,
+			MaxRetries:     cfg.Webhook.MaxRetries,
+			RetryDelayBase: cfg.Webhook.RetryDelayBase,
+			Context:        ctx,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		notifiers = append(notifiers, webhook)
+	}
+	var email *notify.Email
+	if cfg.Email.Enabled {
+		var err error
+		email, err = notify.NewEmail(notify.EmailConfig{
+			Host:           cfg.Email.SMTPHost,
+			Port:           cfg.Email.SMTPPort,
+			Username:       cfg.Email.Username,
+			Password:       cfg.Email.Password,
+			From:           cfg.Email.From,
+			To:             cfg.Email.To,
+			StartTLS:       cfg.Email.StartTLS,
+			DigestInterval: cfg.Email.DigestInterval,
+			MaxPending:     cfg.Email.MaxPending,
+			Timeout:        cfg.Email.Timeout,
+			Notified:       mon.RecordNotified,
+		}, store.RecordDelivery)
+		if err != nil {
+			return nil, nil, err
+		}
+		notifiers = append(notifiers, email)
+	}
+	return notifiers, email, nil
+}
+
+// sendAlerts delivers groups to every notifier and records a delivery for each
+// one that succeeds. The groups enter the cooldown if any notifier delivered
+// them; alerts only queued for a digest enter it when the digest is sent.
+func sendAlerts(clog *logger.Logger, notifiers []notify.Notifier, groups []models.Event, mon *monitor.Monitor, store *storage.Storage, mets *metrics.Metrics) {
+	ids := changeIDs(groups)
+	delivered := false
+	for _, n := range notifiers {
+		nlog := clog.With(logger.SinkKey, n.Destination())
+		nlog.Debug("Sending top %d event groups to %s", len(groups), n.Destination())
+		messageID, err := n.Send(groups)
+		mets.ObserveNotification(n.Destination(), err)
+		if err != nil {
+			nlog.Error("Failed to send notification to %s: %v", n.Destination(), err)
+			continue
+		}
+		if messageID == notify.Queued {
+			// Batched notifiers record their own delivery when the batch goes out
+			nlog.Info("Queued top %d event groups for %s", len(groups), n.Destination())
+			continue
+		}
+		delivered = true
+		nlog.Info("Sent notification with top %d event groups to %s", len(groups), n.Destination())
+		if err := store.RecordDelivery(ids, n.Destination(), messageID, time.Now()); err != nil {
+			nlog.Warn("Failed to record delivery: %v", err)
+		}
+	}
+	if delivered {
+		mon.RecordNotified(groups)
+	}
+}
+
+// sendFollowUps passes moves continuing a recent alert to the notifiers that
+// report follow-ups. The cooldown keeps running from the original alert.
+func sendFollowUps(clog *logger.Logger, notifiers []notify.Notifier, groups []models.Event) {
+	for _, n := range notifiers {
+		f, ok := n.(notify.FollowUpper)
+		if !ok {
+			continue
+		}
+		if err := f.FollowUp(groups); err != nil {
+			clog.With(logger.SinkKey, n.Destination()).Warn("Failed to send follow-ups to %s: %v", n.Destination(), err)
+		}
+	}
+}
+
 func generateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	return uuid.NewString()
+}
+
+// newCycleID returns a short random ID correlating the log lines of one
+// monitoring cycle.
+func newCycleID() string {
+	return uuid.NewString()[:8]
+}
+
+// cycleSnapshots builds one snapshot per tracked outcome of each event (a single
+// Yes series for Yes/No markets). Timestamps are set by Storage.IngestCycle.
+func cycleSnapshots(events []models.Market) []models.Snapshot {
+	var snapshots []models.Snapshot
+	for i := range events {
+		event := &events[i]
+		for j, outcome := range event.TrackedOutcomes() {
+			price, _ := event.OutcomePrice(outcome)
+			snapshot := models.Snapshot{
+				ID:             generateID(),
+				EventID:        event.ID,
+				Outcome:        outcome,
+				YesProbability: price,
+				NoProbability:  1 - price,
+				Source:         "polymarket-gamma-api",
+			}
+			if outcome == "" {
+				snapshot.NoProbability = event.NoProbability
+			}
+			// Order-book data belongs to ClobTokenID: the Yes token, or the first outcome
+			if j == 0 {
+				snapshot.BestBid = event.BestBid
+				snapshot.BestAsk = event.BestAsk
+				snapshot.Spread = event.Spread
+				snapshot.Midpoint = event.Midpoint
+				snapshot.BidDepth = event.BidDepth
+				snapshot.AskDepth = event.AskDepth
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots
+}
+
+func changeIDs(groups []models.Event) []string {
+	var ids []string
+	for _, group := range groups {
+		for _, change := range group.Markets {
+			ids = append(ids, change.ID)
+		}
+	}
+	return ids
 }
 
 func convertMarkets(markets []*models.Market) []models.Market {
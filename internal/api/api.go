@@ -0,0 +1,280 @@
+// Package api serves a read-only JSON API over what polyoracle has stored:
+// tracked markets, their probability snapshots and the alert history.
+//
+// Endpoints (all GET):
+//
+//	/markets                  tracked markets, most liquid first
+//	/markets/{id}/snapshots   snapshot series of one market (composite ID)
+//	/changes                  alert history, newest first
+//	/events/{id}              one Polymarket event: its markets and recent changes
+//
+// Times in query parameters are RFC 3339 timestamps or Go durations counted
+// back from now (since=24h). Every handler reads through storage.Storage.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+const (
+	maxLimit          = 1000 // largest page a request may ask for
+	eventChangesLimit = 50   // alert history entries shown per event
+	shutdownTimeout   = 5 * time.Second
+)
+
+// Server is the HTTP API. It implements http.Handler.
+type Server struct {
+	store *storage.Storage
+	mux   *http.ServeMux
+}
+
+// New creates a Server reading from store.
+func New(store *storage.Storage) *Server {
+	s := &Server{store: store, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /markets", s.handleMarkets)
+	s.mux.HandleFunc("GET /markets/{id}/snapshots", s.handleSnapshots)
+	s.mux.HandleFunc("GET /changes", s.handleChanges)
+	s.mux.HandleFunc("GET /events/{id}", s.handleEvent)
+	return s
+}
+
+// Handle registers an additional handler on the server's mux.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled, then shuts
+// down gracefully. It returns nil after a shutdown.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve HTTP on %s: %w", addr, err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down HTTP server: %w", err)
+		}
+		return nil
+	}
+}
+
+// handleMarkets serves GET /markets?category=&min_volume=&q=&limit=&offset=.
+func (s *Server) handleMarkets(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := storage.MarketFilter{Category: q.Get("category"), Search: q.Get("q")}
+	var err error
+	if f.MinVolume, err = parseFloat(q.Get("min_volume")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid min_volume: "+err.Error())
+		return
+	}
+	if f.Limit, f.Offset, err = parsePage(q.Get("limit"), q.Get("offset")); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	markets, err := s.store.QueryMarkets(f)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, markets)
+}
+
+// handleSnapshots serves GET /markets/{id}/snapshots?since=&until=&outcome=.
+// Without outcome, every series of the market is returned.
+func (s *Server) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	now := time.Now()
+	since, err := parseTime(q.Get("since"), now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+		return
+	}
+	until, err := parseTime(q.Get("until"), now)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid until: "+err.Error())
+		return
+	}
+	snapshots, err := s.store.GetSnapshotsInRange(r.PathValue("id"), since, until)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	if q.Has("outcome") {
+		outcome := q.Get("outcome")
+		series := []models.Snapshot{}
+		for _, snap := range snapshots {
+			if snap.Outcome == outcome {
+				series = append(series, snap)
+			}
+		}
+		snapshots = series
+	}
+	writeJSON(w, http.StatusOK, snapshots)
+}
+
+// handleChanges serves GET /changes?since=&until=&min_score=&passed=&market_id=&event_id=&limit=&offset=.
+func (s *Server) handleChanges(w http.ResponseWriter, r *http.Request) {
+	f, err := changeFilter(r, time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	changes, err := s.store.QueryChanges(f)
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, changes)
+}
+
+// eventView is one Polymarket event with its tracked markets: the grouped
+// view alerts are built from.
+type eventView struct {
+	ID         string           `json:"id"`
+	Title      string           `json:"title"`
+	URL        string           `json:"url"`
+	Category   string           `json:"category"`
+	Volume24hr float64          `json:"volume_24hr"` // total over the tracked markets
+	Markets    []*models.Market `json:"markets"`     // most liquid first
+	Changes    []models.Change  `json:"changes"`     // latest alert history entries, newest first
+}
+
+// handleEvent serves GET /events/{id}.
+func (s *Server) handleEvent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	markets, err := s.store.QueryMarkets(storage.MarketFilter{EventID: id, Limit: maxLimit})
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+	if len(markets) == 0 {
+		writeError(w, http.StatusNotFound, "event not found: "+id)
+		return
+	}
+	changes, err := s.store.QueryChanges(storage.ChangeFilter{EventID: id, Limit: eventChangesLimit})
+	if err != nil {
+		s.internalError(w, r, err)
+		return
+	}
+
+	view := eventView{
+		ID:       id,
+		Title:    markets[0].Title,
+		URL:      markets[0].EventURL,
+		Category: markets[0].Category,
+		Markets:  markets,
+		Changes:  changes,
+	}
+	for _, m := range markets {
+		view.Volume24hr += m.Volume24hr
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// changeFilter builds the alert history filter from the request's query.
+func changeFilter(r *http.Request, now time.Time) (storage.ChangeFilter, error) {
+	q := r.URL.Query()
+	f := storage.ChangeFilter{MarketID: q.Get("market_id"), EventID: q.Get("event_id")}
+	var err error
+	if f.Since, err = parseTime(q.Get("since"), now); err != nil {
+		return f, fmt.Errorf("invalid since: %w", err)
+	}
+	if f.Until, err = parseTime(q.Get("until"), now); err != nil {
+		return f, fmt.Errorf("invalid until: %w", err)
+	}
+	if f.MinScore, err = parseFloat(q.Get("min_score")); err != nil {
+		return f, fmt.Errorf("invalid min_score: %w", err)
+	}
+	if v := q.Get("passed"); v != "" {
+		passed, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid passed: %w", err)
+		}
+		f.Passed = &passed
+	}
+	if f.Limit, f.Offset, err = parsePage(q.Get("limit"), q.Get("offset")); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// parseTime parses an RFC 3339 timestamp, or a duration counted back from
+// now. "" yields the zero time (no bound).
+func parseTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return time.Time{}, errors.New("want an RFC 3339 time or a positive duration such as 24h")
+	}
+	return now.Add(-d), nil
+}
+
+// parseFloat parses a non-negative number; "" yields 0 (no filter).
+func parseFloat(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		return 0, errors.New("want a non-negative number")
+	}
+	return f, nil
+}
+
+// parsePage parses the limit and offset parameters; limit is capped at maxLimit.
+func parsePage(limit, offset string) (int, int, error) {
+	var l, o int
+	var err error
+	if limit != "" {
+		if l, err = strconv.Atoi(limit); err != nil || l < 1 {
+			return 0, 0, errors.New("invalid limit: want a positive integer")
+		}
+	}
+	if offset != "" {
+		if o, err = strconv.Atoi(offset); err != nil || o < 0 {
+			return 0, 0, errors.New("invalid offset: want a non-negative integer")
+		}
+	}
+	return min(l, maxLimit), o, nil
+}
+
+func (s *Server) internalError(w http.ResponseWriter, r *http.Request, err error) {
+	logger.Warn("API request %s failed: %v", r.URL.Path, err)
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debug("Failed to write API response: %v", err)
+	}
+}
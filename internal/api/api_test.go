@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+func mustStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	s, err := storage.New(100, 100, ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+// seed stores two markets of event e1, one of event e2, snapshots and changes.
+func seed(t *testing.T, store *storage.Storage, now time.Time) {
+	t.Helper()
+	for _, m := range []*models.Market{
+		{ID: "e1:m1", EventID: "e1", MarketID: "m1", Title: "Fed decision", MarketQuestion: "Cut 25bp?",
+			EventURL: "https://polymarket.com/event/fed", Category: "economy", YesProbability: 0.6, NoProbability: 0.4,
+			Volume24hr: 5000, Active: true, LastUpdated: now, CreatedAt: now},
+		{ID: "e1:m2", EventID: "e1", MarketID: "m2", Title: "Fed decision", MarketQuestion: "Hold?",
+			EventURL: "https://polymarket.com/event/fed", Category: "economy", YesProbability: 0.3, NoProbability: 0.7,
+			Volume24hr: 1000, Active: true, LastUpdated: now, CreatedAt: now},
+		{ID: "e2:m3", EventID: "e2", MarketID: "m3", Title: "Bitcoin above 100k?", Category: "crypto",
+			YesProbability: 0.5, NoProbability: 0.5, Volume24hr: 9000, Active: true, LastUpdated: now, CreatedAt: now},
+	} {
+		if err := store.AddMarket(m); err != nil {
+			t.Fatalf("AddMarket: %v", err)
+		}
+	}
+	for i, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, time.Hour} {
+		snap := &models.Snapshot{ID: fmt.Sprintf("s%d", i), EventID: "e1:m1", YesProbability: 0.5, NoProbability: 0.5,
+			Timestamp: now.Add(-age), Source: "test"}
+		if err := store.AddSnapshot(snap); err != nil {
+			t.Fatalf("AddSnapshot: %v", err)
+		}
+	}
+	for _, c := range []*models.Change{
+		{ID: "c1", EventID: "e1:m1", OriginalEventID: "e1", SignalScore: 0.3, PassedThreshold: true,
+			Direction: "increase", TimeWindow: time.Hour, DetectedAt: now.Add(-time.Hour)},
+		{ID: "c2", EventID: "e2:m3", OriginalEventID: "e2", SignalScore: 0.01,
+			Direction: "decrease", TimeWindow: time.Hour, DetectedAt: now.Add(-48 * time.Hour)},
+	} {
+		if err := store.AddChange(c); err != nil {
+			t.Fatalf("AddChange: %v", err)
+		}
+	}
+}
+
+func get(t *testing.T, srv *Server, target string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s: Content-Type = %q", target, ct)
+	}
+	if v != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: decode %q: %v", target, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func TestMarkets(t *testing.T) {
+	store := mustStorage(t)
+	seed(t, store, time.Now())
+	srv := New(store)
+
+	var markets []models.Market
+	if code := get(t, srv, "/markets?category=Economy&min_volume=2000", &markets); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if len(markets) != 1 || markets[0].ID != "e1:m1" {
+		t.Errorf("unexpected markets: %+v", markets)
+	}
+	if get(t, srv, "/markets?q=bitcoin", &markets); len(markets) != 1 || markets[0].ID != "e2:m3" {
+		t.Errorf("search should find the bitcoin market, got %+v", markets)
+	}
+	if get(t, srv, "/markets?category=sports", &markets); markets == nil || len(markets) != 0 {
+		t.Errorf("no match should be an empty array, got %+v", markets)
+	}
+	if code := get(t, srv, "/markets?limit=0", nil); code != http.StatusBadRequest {
+		t.Errorf("limit=0: status %d, want 400", code)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	store := mustStorage(t)
+	seed(t, store, time.Now())
+	srv := New(store)
+
+	var snaps []models.Snapshot
+	if code := get(t, srv, "/markets/e1:m1/snapshots?since=150m", &snaps); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if len(snaps) != 2 || snaps[0].ID != "s1" || snaps[1].ID != "s2" {
+		t.Errorf("unexpected snapshots: %+v", snaps)
+	}
+	until := time.Now().Add(-150 * time.Minute).Format(time.RFC3339)
+	if get(t, srv, "/markets/e1:m1/snapshots?until="+until, &snaps); len(snaps) != 1 || snaps[0].ID != "s0" {
+		t.Errorf("until should bound the range, got %+v", snaps)
+	}
+	if get(t, srv, "/markets/e1:m1/snapshots?outcome=Trump", &snaps); len(snaps) != 0 {
+		t.Errorf("unknown outcome should have no snapshots, got %+v", snaps)
+	}
+	if code := get(t, srv, "/markets/e1:m1/snapshots?since=yesterday", nil); code != http.StatusBadRequest {
+		t.Errorf("bad since: status %d, want 400", code)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	store := mustStorage(t)
+	seed(t, store, time.Now())
+	srv := New(store)
+
+	var changes []models.Change
+	if code := get(t, srv, "/changes", &changes); code != http.StatusOK || len(changes) != 2 {
+		t.Fatalf("status %d, %d changes; want 200, 2", code, len(changes))
+	}
+	if get(t, srv, "/changes?since=24h", &changes); len(changes) != 1 || changes[0].ID != "c1" {
+		t.Errorf("since should drop the old change, got %+v", changes)
+	}
+	if get(t, srv, "/changes?min_score=0.1", &changes); len(changes) != 1 || changes[0].ID != "c1" {
+		t.Errorf("min_score should drop the weak change, got %+v", changes)
+	}
+	if get(t, srv, "/changes?passed=true&event_id=e2", &changes); len(changes) != 0 {
+		t.Errorf("passed and event filters should combine, got %+v", changes)
+	}
+	if get(t, srv, "/changes?passed=false", &changes); len(changes) != 1 || changes[0].ID != "c2" {
+		t.Errorf("passed=false should keep only the change below the bar, got %+v", changes)
+	}
+	if code := get(t, srv, "/changes?passed=maybe", nil); code != http.StatusBadRequest {
+		t.Errorf("bad passed: status %d, want 400", code)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	store := mustStorage(t)
+	seed(t, store, time.Now())
+	srv := New(store)
+
+	var event eventView
+	if code := get(t, srv, "/events/e1", &event); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if event.Title != "Fed decision" || event.Volume24hr != 6000 || len(event.Markets) != 2 || event.Markets[0].ID != "e1:m1" {
+		t.Errorf("unexpected event view: %+v", event)
+	}
+	if len(event.Changes) != 1 || event.Changes[0].ID != "c1" {
+		t.Errorf("event should carry its changes, got %+v", event.Changes)
+	}
+	if code := get(t, srv, "/events/missing", nil); code != http.StatusNotFound {
+		t.Errorf("missing event: status %d, want 404", code)
+	}
+}
@@ -0,0 +1,149 @@
+// Package backfill seeds snapshot history for newly tracked markets from the
+// Polymarket CLOB prices-history endpoint.
+//
+// Without history, HistoricalSNR and TrajectoryConsistency fall back to 1.0
+// until enough polling cycles have accumulated. Backfilling gives a new market
+// a real noise baseline from its first cycle.
+//
+// Backfill is resumable: snapshot IDs are derived from the market ID and point
+// timestamp and inserted with INSERT OR IGNORE, and a market is only marked
+// complete after all of its points are written. An interrupted run simply
+// repeats the unfinished markets on the next cycle.
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/polymarket"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+// Source is the snapshot source identifier for backfilled history.
+const Source = "polymarket-clob-history"
+
+// Config controls how much history is fetched and how fast.
+type Config struct {
+	Lookback     time.Duration // how far before a market was first seen to backfill
+	Fidelity     time.Duration // sampling interval of the history (typically poll_interval)
+	RequestDelay time.Duration // minimum delay between prices-history requests
+	MaxPerCycle  int           // maximum markets backfilled per call to Run
+}
+
+// Result summarises one Run.
+type Result struct {
+	Markets   int // markets completed
+	Snapshots int // snapshot rows inserted
+	Failed    int // markets that failed and will be retried
+}
+
+// Backfiller fetches price history for markets pending backfill.
+type Backfiller struct {
+	client *polymarket.Client
+	store  *storage.Storage
+	cfg    Config
+}
+
+// New creates a Backfiller.
+func New(client *polymarket.Client, store *storage.Storage, cfg Config) *Backfiller {
+	return &Backfiller{client: client, store: store, cfg: cfg}
+}
+
+// Run backfills up to MaxPerCycle pending markets, waiting RequestDelay between
+// requests. Per-market failures are logged and counted, not returned; the market
+// stays pending and is retried on the next run. Returns an error only when the
+// pending set cannot be read or ctx is cancelled.
+func (b *Backfiller) Run(ctx context.Context) (Result, error) {
+	var result Result
+
+	pending, err := b.store.MarketsPendingBackfill(b.cfg.MaxPerCycle)
+	if err != nil {
+		return result, err
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+	logger.FromContext(ctx).Debug("Backfill: %d markets pending (lookback: %v, fidelity: %v)", len(pending), b.cfg.Lookback, b.cfg.Fidelity)
+
+	for i, market := range pending {
+		if i > 0 && b.cfg.RequestDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return result, fmt.Errorf("backfill cancelled: %w", ctx.Err())
+			case <-time.After(b.cfg.RequestDelay):
+			}
+		}
+
+		inserted, err := b.backfillMarket(ctx, market)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, fmt.Errorf("backfill cancelled: %w", ctx.Err())
+			}
+			logger.FromContext(ctx).With(logger.MarketIDKey, market.ID).Warn("Backfill failed for market %s: %v", market.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Markets++
+		result.Snapshots += inserted
+	}
+
+	return result, nil
+}
+
+// backfillMarket writes history for the window [CreatedAt − Lookback, CreatedAt)
+// so backfilled points never interleave with live polled snapshots. Named-outcome
+// markets get one history per outcome token.
+func (b *Backfiller) backfillMarket(ctx context.Context, market *models.Market) (int, error) {
+	end := market.CreatedAt
+	start := end.Add(-b.cfg.Lookback)
+
+	type target struct{ tokenID, outcome string }
+	targets := []target{{tokenID: market.ClobTokenID}}
+	if !market.IsYesNo() {
+		targets = targets[:0]
+		for _, o := range market.NamedSeries() {
+			if o.TokenID != "" {
+				targets = append(targets, target{tokenID: o.TokenID, outcome: o.Name})
+			}
+		}
+	}
+
+	var snapshots []models.Snapshot
+	for _, t := range targets {
+		points, err := b.client.FetchPriceHistory(ctx, t.tokenID, start, end, b.cfg.Fidelity)
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range points {
+			ts := time.Unix(p.T, 0)
+			if !ts.Before(end) || ts.Before(start) || p.P < 0 || p.P > 1 {
+				continue
+			}
+			id := fmt.Sprintf("%s:%s:%d", Source, market.ID, p.T)
+			if t.outcome != "" {
+				id = fmt.Sprintf("%s:%s:%s:%d", Source, market.ID, t.outcome, p.T)
+			}
+			snapshots = append(snapshots, models.Snapshot{
+				ID:             id,
+				EventID:        market.ID,
+				Outcome:        t.outcome,
+				YesProbability: p.P,
+				NoProbability:  1 - p.P,
+				Timestamp:      ts,
+				Source:         Source,
+			})
+		}
+	}
+
+	inserted, err := b.store.AddSnapshots(snapshots)
+	if err != nil {
+		return 0, err
+	}
+	if err := b.store.MarkBackfilled(market.ID, time.Now()); err != nil {
+		return inserted, err
+	}
+	return inserted, nil
+}
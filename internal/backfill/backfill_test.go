@@ -0,0 +1,168 @@
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/polymarket"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+func mustStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	s, err := storage.New(100, 100, ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func addMarket(t *testing.T, s *storage.Storage, id, tokenID string, createdAt time.Time) {
+	t.Helper()
+	m := &models.Market{
+		ID:             id,
+		EventID:        "event",
+		MarketID:       id,
+		ClobTokenID:    tokenID,
+		Title:          "Test",
+		Category:       "test",
+		YesProbability: 0.5,
+		NoProbability:  0.5,
+		LastUpdated:    createdAt,
+		CreatedAt:      createdAt,
+	}
+	if err := s.AddMarket(m); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+}
+
+// historyServer serves one point every 5 minutes for the hour before createdAt.
+// Requests for tokens in failTokens return 400 (not retried by the client).
+func historyServer(t *testing.T, createdAt time.Time, failTokens map[string]bool) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/prices-history" {
+			t.Errorf("Expected path /prices-history, got %s", r.URL.Path)
+		}
+		if failTokens[r.URL.Query().Get("market")] {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("fidelity") != "5" {
+			t.Errorf("Expected fidelity=5, got %s", r.URL.Query().Get("fidelity"))
+		}
+		var history []polymarket.PricePoint
+		for i := 12; i >= 1; i-- {
+			history = append(history, polymarket.PricePoint{
+				T: createdAt.Add(-time.Duration(i) * 5 * time.Minute).Unix(),
+				P: 0.40 + float64(12-i)*0.01,
+			})
+		}
+		// A point at/after createdAt must be ignored
+		history = append(history, polymarket.PricePoint{T: createdAt.Unix(), P: 0.9})
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"history": history})
+	}))
+}
+
+func TestBackfiller_Run(t *testing.T) {
+	store := mustStorage(t)
+	createdAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	addMarket(t, store, "e:m1", "token-1", createdAt)
+	addMarket(t, store, "e:m2", "", createdAt) // no CLOB token: never pending
+
+	server := historyServer(t, createdAt, nil)
+	defer server.Close()
+
+	client := polymarket.NewClient("https://gamma-api.polymarket.com", server.URL, 10*time.Second)
+	b := New(client, store, Config{Lookback: time.Hour, Fidelity: 5 * time.Minute, MaxPerCycle: 10})
+
+	result, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Markets != 1 || result.Snapshots != 12 || result.Failed != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	snaps, err := store.GetSnapshots("e:m1")
+	if err != nil {
+		t.Fatalf("GetSnapshots: %v", err)
+	}
+	if len(snaps) != 12 {
+		t.Fatalf("Expected 12 backfilled snapshots, got %d", len(snaps))
+	}
+	for _, s := range snaps {
+		if s.Source != Source {
+			t.Errorf("Expected source %q, got %q", Source, s.Source)
+		}
+		if !s.Timestamp.Before(createdAt) {
+			t.Errorf("Backfilled snapshot at %v is not before first-seen time %v", s.Timestamp, createdAt)
+		}
+	}
+
+	// Nothing left pending: a second run is a no-op
+	result, err = b.Run(context.Background())
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if result.Markets != 0 {
+		t.Errorf("Expected no pending markets on second run, got %+v", result)
+	}
+}
+
+func TestBackfiller_ResumesAfterFailure(t *testing.T) {
+	store := mustStorage(t)
+	createdAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	addMarket(t, store, "e:m1", "token-1", createdAt)
+
+	failing := historyServer(t, createdAt, map[string]bool{"token-1": true})
+	client := polymarket.NewClient("https://gamma-api.polymarket.com", failing.URL, 10*time.Second)
+	b := New(client, store, Config{Lookback: time.Hour, Fidelity: 5 * time.Minute, MaxPerCycle: 10})
+
+	result, err := b.Run(context.Background())
+	failing.Close()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Failed != 1 {
+		t.Errorf("Expected 1 failed market, got %+v", result)
+	}
+
+	// Simulate a partial previous attempt: one point already written
+	partial := models.Snapshot{
+		ID:             Source + ":e:m1:" + strconv.FormatInt(createdAt.Add(-60*time.Minute).Unix(), 10),
+		EventID:        "e:m1",
+		YesProbability: 0.40,
+		NoProbability:  0.60,
+		Timestamp:      createdAt.Add(-60 * time.Minute),
+		Source:         Source,
+	}
+	if _, err := store.AddSnapshots([]models.Snapshot{partial}); err != nil {
+		t.Fatalf("AddSnapshots: %v", err)
+	}
+
+	healthy := historyServer(t, createdAt, nil)
+	defer healthy.Close()
+	b = New(polymarket.NewClient("https://gamma-api.polymarket.com", healthy.URL, 10*time.Second), store,
+		Config{Lookback: time.Hour, Fidelity: 5 * time.Minute, MaxPerCycle: 10})
+
+	result, err = b.Run(context.Background())
+	if err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	if result.Markets != 1 || result.Snapshots != 11 {
+		t.Errorf("Expected resumed run to insert the 11 missing points, got %+v", result)
+	}
+	snaps, _ := store.GetSnapshots("e:m1")
+	if len(snaps) != 12 {
+		t.Errorf("Expected 12 snapshots without duplicates, got %d", len(snaps))
+	}
+}
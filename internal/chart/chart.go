@@ -0,0 +1,216 @@
+// Package chart renders the probability history of a market as a PNG line
+// chart, using only the standard library. The detection window of an alert is
+// shaded so a reader can tell a spike from a steady climb or a reversal.
+//
+// Labels are limited to numbers (percentages and times); titles belong in the
+// message caption.
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// ErrNotEnoughData is returned when the series has fewer than two snapshots.
+var ErrNotEnoughData = errors.New("at least two snapshots are needed to draw a chart")
+
+// Options controls the rendered image.
+type Options struct {
+	Width  int           // pixels; 0 = 800
+	Height int           // pixels; 0 = 400
+	Window time.Duration // detection window shaded before End; 0 = no shading
+	End    time.Time     // right edge of the time axis; zero = last snapshot
+}
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	gridColor  = color.RGBA{0xe6, 0xe6, 0xe6, 0xff}
+	axisColor  = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	labelColor = color.RGBA{0x55, 0x55, 0x55, 0xff}
+	shadeColor = color.RGBA{0xff, 0xf1, 0xc7, 0xff}
+	upColor    = color.RGBA{0x1a, 0x9e, 0x5a, 0xff}
+	downColor  = color.RGBA{0xd6, 0x3b, 0x3b, 0xff}
+)
+
+const (
+	marginLeft   = 56
+	marginRight  = 24
+	marginTop    = 20
+	marginBottom = 36
+	labelScale   = 2
+)
+
+// Render draws the YesProbability of series (oldest first) against time and
+// encodes it as PNG. The line is green when the price ended at or above where
+// the detection window (or the series) started, red otherwise.
+func Render(series []models.Snapshot, opts Options) ([]byte, error) {
+	if len(series) < 2 {
+		return nil, ErrNotEnoughData
+	}
+	width, height := opts.Width, opts.Height
+	if width <= 0 {
+		width = 800
+	}
+	if height <= 0 {
+		height = 400
+	}
+	if width < marginLeft+marginRight+10 || height < marginTop+marginBottom+10 {
+		return nil, fmt.Errorf("chart size %dx%d is too small", width, height)
+	}
+
+	start := series[0].Timestamp
+	end := opts.End
+	if end.IsZero() || end.Before(series[len(series)-1].Timestamp) {
+		end = series[len(series)-1].Timestamp
+	}
+	if !end.After(start) {
+		end = start.Add(time.Second)
+	}
+	lo, hi, step := probabilityRange(series)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	plot := image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom)
+
+	x := func(t time.Time) int {
+		f := float64(t.Sub(start)) / float64(end.Sub(start))
+		return plot.Min.X + int(math.Round(f*float64(plot.Dx()-1)))
+	}
+	y := func(p float64) int {
+		f := (p - lo) / (hi - lo)
+		return plot.Max.Y - 1 - int(math.Round(f*float64(plot.Dy()-1)))
+	}
+
+	// Detection window
+	windowStart := start
+	if opts.Window > 0 {
+		windowStart = end.Add(-opts.Window)
+		if windowStart.Before(start) {
+			windowStart = start
+		}
+		shade := image.Rect(x(windowStart), plot.Min.Y, plot.Max.X, plot.Max.Y)
+		draw.Draw(img, shade, image.NewUniform(shadeColor), image.Point{}, draw.Src)
+	}
+
+	// Horizontal grid with percentage labels
+	for p := lo; p <= hi+step/2; p += step {
+		py := y(p)
+		hline(img, plot.Min.X, plot.Max.X, py, gridColor)
+		label := fmt.Sprintf("%.0f%%", p*100)
+		drawText(img, plot.Min.X-8-textWidth(label), py-glyphHeight*labelScale/2, label, labelColor)
+	}
+
+	// Time ticks
+	layout := "15:04"
+	if end.Sub(start) > 36*time.Hour {
+		layout = "01-02"
+	}
+	const ticks = 4
+	for i := 0; i <= ticks; i++ {
+		t := start.Add(time.Duration(float64(end.Sub(start)) * float64(i) / ticks))
+		px := x(t)
+		vline(img, px, plot.Max.Y, plot.Max.Y+4, axisColor)
+		label := t.Format(layout)
+		lx := min(max(px-textWidth(label)/2, 0), width-textWidth(label))
+		drawText(img, lx, plot.Max.Y+10, label, labelColor)
+	}
+
+	// Axes
+	hline(img, plot.Min.X, plot.Max.X, plot.Max.Y, axisColor)
+	vline(img, plot.Min.X, plot.Min.Y, plot.Max.Y, axisColor)
+
+	// Series
+	base := series[0].YesProbability
+	for _, s := range series {
+		if !s.Timestamp.Before(windowStart) {
+			base = s.YesProbability
+			break
+		}
+	}
+	lineColor := upColor
+	if series[len(series)-1].YesProbability < base {
+		lineColor = downColor
+	}
+	for i := 1; i < len(series); i++ {
+		a, b := series[i-1], series[i]
+		thickLine(img, x(a.Timestamp), y(a.YesProbability), x(b.Timestamp), y(b.YesProbability), lineColor)
+	}
+	last := series[len(series)-1]
+	disc(img, x(last.Timestamp), y(last.YesProbability), 4, lineColor)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// probabilityRange returns the y-axis bounds, padded around the series and
+// aligned to a round grid step, clamped to [0, 1].
+func probabilityRange(series []models.Snapshot) (lo, hi, step float64) {
+	lo, hi = series[0].YesProbability, series[0].YesProbability
+	for _, s := range series {
+		lo, hi = min(lo, s.YesProbability), max(hi, s.YesProbability)
+	}
+	pad := max((hi-lo)*0.1, 0.02)
+	lo, hi = max(lo-pad, 0), min(hi+pad, 1)
+
+	step = 0.25
+	for _, s := range []float64{0.01, 0.02, 0.05, 0.1, 0.2} {
+		if (hi-lo)/s <= 6 {
+			step = s
+			break
+		}
+	}
+	lo = math.Floor(lo/step+1e-9) * step
+	hi = math.Min(math.Ceil(hi/step-1e-9)*step, 1)
+	return lo, hi, step
+}
+
+func hline(img *image.RGBA, x0, x1, y int, c color.RGBA) {
+	for x := x0; x < x1; x++ {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+func vline(img *image.RGBA, x, y0, y1 int, c color.RGBA) {
+	for y := y0; y < y1; y++ {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+// thickLine draws a segment about three pixels wide.
+func thickLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	n := max(abs(x1-x0), abs(y1-y0), 1)
+	for i := 0; i <= n; i++ {
+		x := x0 + (x1-x0)*i/n
+		y := y0 + (y1-y0)*i/n
+		disc(img, x, y, 1, c)
+	}
+}
+
+func disc(img *image.RGBA, cx, cy, r int, c color.RGBA) {
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy <= r*r+r {
+				img.SetRGBA(cx+dx, cy+dy, c)
+			}
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
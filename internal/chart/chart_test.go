@@ -0,0 +1,71 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+func series(start time.Time, step time.Duration, probs ...float64) []models.Snapshot {
+	snaps := make([]models.Snapshot, len(probs))
+	for i, p := range probs {
+		snaps[i] = models.Snapshot{YesProbability: p, NoProbability: 1 - p, Timestamp: start.Add(time.Duration(i) * step)}
+	}
+	return snaps
+}
+
+func TestRender(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	snaps := series(start, 15*time.Minute, 0.41, 0.42, 0.40, 0.41, 0.47, 0.53)
+	end := snaps[len(snaps)-1].Timestamp
+
+	data, err := Render(snaps, Options{Width: 400, Height: 200, Window: 45 * time.Minute, End: end})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not a PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 400 || b.Dy() != 200 {
+		t.Errorf("unexpected size %v", b)
+	}
+
+	// The last 45 of 75 minutes are shaded; the area just inside the right
+	// edge of the plot, near the top, is the shade colour.
+	if got := img.At(400-marginRight-3, marginTop+2); got != shadeColor {
+		t.Errorf("expected the detection window to be shaded, got %v", got)
+	}
+	if got := img.At(marginLeft+3, marginTop+2); got == shadeColor {
+		t.Error("the area before the detection window should not be shaded")
+	}
+
+	// The move ended up: the last point is drawn in the up colour.
+	lo, hi, _ := probabilityRange(snaps)
+	plotH := 200 - marginTop - marginBottom
+	py := marginTop + plotH - 1 - int((0.53-lo)/(hi-lo)*float64(plotH-1)+0.5)
+	if got := img.At(400-marginRight-1, py); got != upColor {
+		t.Errorf("expected the last point in the up colour, got %v", got)
+	}
+}
+
+func TestRender_NotEnoughData(t *testing.T) {
+	if _, err := Render(series(time.Now(), time.Minute, 0.5), Options{}); !errors.Is(err, ErrNotEnoughData) {
+		t.Errorf("expected ErrNotEnoughData, got %v", err)
+	}
+}
+
+func TestProbabilityRange(t *testing.T) {
+	lo, hi, step := probabilityRange(series(time.Now(), time.Minute, 0.41, 0.53))
+	if step != 0.05 || lo > 0.39 || hi < 0.55 || hi > 0.6+1e-9 {
+		t.Errorf("probabilityRange = %v, %v, %v", lo, hi, step)
+	}
+	lo, hi, _ = probabilityRange(series(time.Now(), time.Minute, 0.99, 1))
+	if hi != 1 || lo < 0.9 {
+		t.Errorf("range should be clamped to 1, got %v–%v", lo, hi)
+	}
+}
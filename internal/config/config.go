@@ -19,7 +19,15 @@ type Config struct {
 	Polymarket PolymarketConfig `mapstructure:"polymarket"`
 	Monitor    MonitorConfig    `mapstructure:"monitor"`
 	Telegram   TelegramConfig   `mapstructure:"telegram"`
+	Slack      SlackConfig      `mapstructure:"slack"`
+	Discord    DiscordConfig    `mapstructure:"discord"`
+	Webhook    WebhookConfig    `mapstructure:"webhook"`
+	Email      EmailConfig      `mapstructure:"email"`
 	Storage    StorageConfig    `mapstructure:"storage"`
+	Backfill   BackfillConfig   `mapstructure:"backfill"`
+	Stream     StreamConfig     `mapstructure:"stream"`
+	Resolution ResolutionConfig `mapstructure:"resolution"`
+	Server     ServerConfig     `mapstructure:"server"`
 	Logging    LoggingConfig    `mapstructure:"logging"`
 }
 
@@ -40,6 +48,7 @@ type PolymarketConfig struct {
 	MaxIdleConns        int           `mapstructure:"max_idle_conns"`
 	MaxIdleConnsPerHost int           `mapstructure:"max_idle_conns_per_host"`
 	IdleConnTimeout     time.Duration `mapstructure:"idle_conn_timeout"`
+	FetchOrderBooks     bool          `mapstructure:"fetch_order_books"` // fetch CLOB bid/ask, spread and depth each cycle
 }
 
 // MonitorConfig holds monitoring behavior configuration
@@ -58,26 +67,121 @@ func (m MonitorConfig) MinCompositeScore() float64 {
 	return m.Sensitivity * m.Sensitivity * 0.05
 }
 
+// DetectionWindow returns the span changes are detected over, which is also
+// the alert cooldown: (detection_intervals+1) × poll_interval.
+// With cycleTime-stamped snapshots, the oldest snapshot from N cycles ago is
+// exactly N×pollInterval old at tick time, but GetSnapshotsInWindow runs after
+// processing completes (tick + τ), making it N×pollInterval + τ old. The extra
+// interval absorbs τ so the boundary snapshot is never accidentally excluded.
+func (c *Config) DetectionWindow() time.Duration {
+	return time.Duration(c.Monitor.DetectionIntervals+1) * c.Polymarket.PollInterval
+}
+
 // TelegramConfig holds Telegram notification configuration
 type TelegramConfig struct {
 	BotToken       string        `mapstructure:"bot_token"`
 	ChatID         string        `mapstructure:"chat_id"`
+	AllowedChatIDs []int64       `mapstructure:"allowed_chat_ids"` // other chats that may subscribe and use data commands
+	Enabled        bool          `mapstructure:"enabled"`
+	MaxRetries     int           `mapstructure:"max_retries"`
+	RetryDelayBase time.Duration `mapstructure:"retry_delay_base"`
+	Charts         bool          `mapstructure:"charts"`         // attach a price chart per event group to alerts
+	ExplainScores  bool          `mapstructure:"explain_scores"` // add a score breakdown line per market to alerts
+	Threads        bool          `mapstructure:"threads"`        // post later moves of alerted markets as replies to their alert
+}
+
+// SlackConfig holds Slack incoming-webhook notification configuration
+type SlackConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	WebhookURL     string        `mapstructure:"webhook_url"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	MaxRetries     int           `mapstructure:"max_retries"`
+	RetryDelayBase time.Duration `mapstructure:"retry_delay_base"`
+}
+
+// DiscordConfig holds Discord webhook notification configuration
+type DiscordConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	WebhookURL     string        `mapstructure:"webhook_url"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	MaxRetries     int           `mapstructure:"max_retries"`
+	RetryDelayBase time.Duration `mapstructure:"retry_delay_base"`
+}
+
+// WebhookConfig holds signed generic JSON webhook notification configuration
+type WebhookConfig struct {
 	Enabled        bool          `mapstructure:"enabled"`
+	URL            string        `mapstructure:"url"`
+	Secret         string        `mapstructure:"secret"` // HMAC-SHA256 key for the X-Polyoracle-Signature header
+	Timeout        time.Duration `mapstructure:"timeout"`
 	MaxRetries     int           `mapstructure:"max_retries"`
 	RetryDelayBase time.Duration `mapstructure:"retry_delay_base"`
 }
 
+// EmailConfig holds SMTP email notification configuration
+type EmailConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	SMTPHost       string        `mapstructure:"smtp_host"`
+	SMTPPort       int           `mapstructure:"smtp_port"`
+	Username       string        `mapstructure:"username"` // empty = no SMTP AUTH
+	Password       string        `mapstructure:"password"`
+	From           string        `mapstructure:"from"`
+	To             []string      `mapstructure:"to"`
+	StartTLS       bool          `mapstructure:"starttls"`        // require STARTTLS before AUTH
+	DigestInterval time.Duration `mapstructure:"digest_interval"` // 0 = one email per alert
+	MaxPending     int           `mapstructure:"max_pending"`     // alerts queued for a digest; the oldest are dropped beyond it
+	Timeout        time.Duration `mapstructure:"timeout"`
+}
+
 // StorageConfig holds storage configuration
 type StorageConfig struct {
-	MaxEvents            int    `mapstructure:"max_events"`
-	MaxSnapshotsPerEvent int    `mapstructure:"max_snapshots_per_event"`
-	DBPath               string `mapstructure:"db_path"`
+	MaxEvents            int           `mapstructure:"max_events"`
+	MaxSnapshotsPerEvent int           `mapstructure:"max_snapshots_per_event"`
+	DBPath               string        `mapstructure:"db_path"`
+	ChangeRetention      time.Duration `mapstructure:"change_retention"` // alert history older than this is deleted (0 = keep forever)
+}
+
+// BackfillConfig holds price-history backfill configuration for newly tracked markets
+type BackfillConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Lookback     time.Duration `mapstructure:"lookback"`      // history fetched before a market was first seen
+	RequestDelay time.Duration `mapstructure:"request_delay"` // minimum delay between CLOB history requests
+	MaxPerCycle  int           `mapstructure:"max_per_cycle"` // markets backfilled per cycle; the rest resume next cycle
+}
+
+// StreamConfig holds real-time WebSocket price ingestion configuration
+type StreamConfig struct {
+	Enabled             bool          `mapstructure:"enabled"`
+	URL                 string        `mapstructure:"url"`                   // CLOB market channel URL
+	MinSnapshotInterval time.Duration `mapstructure:"min_snapshot_interval"` // per-market throttle for streamed snapshots
+	PingInterval        time.Duration `mapstructure:"ping_interval"`         // keep-alive PING interval
+	ReconnectMin        time.Duration `mapstructure:"reconnect_min"`         // initial reconnect backoff
+	ReconnectMax        time.Duration `mapstructure:"reconnect_max"`         // maximum reconnect backoff
+	Retention           time.Duration `mapstructure:"retention"`             // streamed snapshots older than this are deleted
+}
+
+// ResolutionConfig holds closed-market resolution tracking configuration
+type ResolutionConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	RecheckInterval time.Duration `mapstructure:"recheck_interval"` // minimum time between lookups of one unresolved market
+	MaxPerCycle     int           `mapstructure:"max_per_cycle"`    // markets looked up per cycle
+	MaxPendingAge   time.Duration `mapstructure:"max_pending_age"`  // unresolved markets are kept from rotation this long after leaving the feed
+}
+
+// ServerConfig holds the embedded HTTP server configuration (read-only JSON
+// API, metrics and health checks)
+type ServerConfig struct {
+	Enabled             bool   `mapstructure:"enabled"`
+	ListenAddr          string `mapstructure:"listen_addr"`           // host:port to listen on
+	ReadyStaleIntervals int    `mapstructure:"ready_stale_intervals"` // /readyz fails when the last success is older than this many poll intervals
+	ReadyEmptyFetches   int    `mapstructure:"ready_empty_fetches"`   // /readyz fails after this many consecutive empty fetches (0 = never)
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
+	Level      string            `mapstructure:"level"`
+	Format     string            `mapstructure:"format"`
+	Components map[string]string `mapstructure:"components"` // package name → level, overriding Level
 }
 
 // Load reads configuration from file and environment variables
@@ -111,6 +215,7 @@ func Load(path string) (*Config, error) {
 	_ = v.BindEnv("polymarket.max_idle_conns", "POLY_ORACLE_POLYMARKET_MAX_IDLE_CONNS")
 	_ = v.BindEnv("polymarket.max_idle_conns_per_host", "POLY_ORACLE_POLYMARKET_MAX_IDLE_CONNS_PER_HOST")
 	_ = v.BindEnv("polymarket.idle_conn_timeout", "POLY_ORACLE_POLYMARKET_IDLE_CONN_TIMEOUT")
+	_ = v.BindEnv("polymarket.fetch_order_books", "POLY_ORACLE_POLYMARKET_FETCH_ORDER_BOOKS")
 
 	// Monitor
 	_ = v.BindEnv("monitor.sensitivity", "POLY_ORACLE_MONITOR_SENSITIVITY")
@@ -123,14 +228,81 @@ func Load(path string) (*Config, error) {
 	// Telegram
 	_ = v.BindEnv("telegram.bot_token", "POLY_ORACLE_TELEGRAM_BOT_TOKEN")
 	_ = v.BindEnv("telegram.chat_id", "POLY_ORACLE_TELEGRAM_CHAT_ID")
+	_ = v.BindEnv("telegram.allowed_chat_ids", "POLY_ORACLE_TELEGRAM_ALLOWED_CHAT_IDS")
 	_ = v.BindEnv("telegram.enabled", "POLY_ORACLE_TELEGRAM_ENABLED")
 	_ = v.BindEnv("telegram.max_retries", "POLY_ORACLE_TELEGRAM_MAX_RETRIES")
 	_ = v.BindEnv("telegram.retry_delay_base", "POLY_ORACLE_TELEGRAM_RETRY_DELAY_BASE")
+	_ = v.BindEnv("telegram.charts", "POLY_ORACLE_TELEGRAM_CHARTS")
+	_ = v.BindEnv("telegram.explain_scores", "POLY_ORACLE_TELEGRAM_EXPLAIN_SCORES")
+	_ = v.BindEnv("telegram.threads", "POLY_ORACLE_TELEGRAM_THREADS")
+
+	// Slack
+	_ = v.BindEnv("slack.enabled", "POLY_ORACLE_SLACK_ENABLED")
+	_ = v.BindEnv("slack.webhook_url", "POLY_ORACLE_SLACK_WEBHOOK_URL")
+	_ = v.BindEnv("slack.timeout", "POLY_ORACLE_SLACK_TIMEOUT")
+	_ = v.BindEnv("slack.max_retries", "POLY_ORACLE_SLACK_MAX_RETRIES")
+	_ = v.BindEnv("slack.retry_delay_base", "POLY_ORACLE_SLACK_RETRY_DELAY_BASE")
+
+	// Discord
+	_ = v.BindEnv("discord.enabled", "POLY_ORACLE_DISCORD_ENABLED")
+	_ = v.BindEnv("discord.webhook_url", "POLY_ORACLE_DISCORD_WEBHOOK_URL")
+	_ = v.BindEnv("discord.timeout", "POLY_ORACLE_DISCORD_TIMEOUT")
+	_ = v.BindEnv("discord.max_retries", "POLY_ORACLE_DISCORD_MAX_RETRIES")
+	_ = v.BindEnv("discord.retry_delay_base", "POLY_ORACLE_DISCORD_RETRY_DELAY_BASE")
+
+	// Generic webhook
+	_ = v.BindEnv("webhook.enabled", "POLY_ORACLE_WEBHOOK_ENABLED")
+	_ = v.BindEnv("webhook.url", "POLY_ORACLE_WEBHOOK_URL")
+	_ = v.BindEnv("webhook.secret", "POLY_ORACLE_WEBHOOK_SECRET")
+	_ = v.BindEnv("webhook.timeout", "POLY_ORACLE_WEBHOOK_TIMEOUT")
+	_ = v.BindEnv("webhook.max_retries", "POLY_ORACLE_WEBHOOK_MAX_RETRIES")
+	_ = v.BindEnv("webhook.retry_delay_base", "POLY_ORACLE_WEBHOOK_RETRY_DELAY_BASE")
+
+	// Email
+	_ = v.BindEnv("email.enabled", "POLY_ORACLE_EMAIL_ENABLED")
+	_ = v.BindEnv("email.smtp_host", "POLY_ORACLE_EMAIL_SMTP_HOST")
+	_ = v.BindEnv("email.smtp_port", "POLY_ORACLE_EMAIL_SMTP_PORT")
+	_ = v.BindEnv("email.username", "POLY_ORACLE_EMAIL_USERNAME")
+	_ = v.BindEnv("email.password", "POLY_ORACLE_EMAIL_PASSWORD")
+	_ = v.BindEnv("email.from", "POLY_ORACLE_EMAIL_FROM")
+	_ = v.BindEnv("email.to", "POLY_ORACLE_EMAIL_TO")
+	_ = v.BindEnv("email.starttls", "POLY_ORACLE_EMAIL_STARTTLS")
+	_ = v.BindEnv("email.digest_interval", "POLY_ORACLE_EMAIL_DIGEST_INTERVAL")
+	_ = v.BindEnv("email.max_pending", "POLY_ORACLE_EMAIL_MAX_PENDING")
+	_ = v.BindEnv("email.timeout", "POLY_ORACLE_EMAIL_TIMEOUT")
 
 	// Storage
 	_ = v.BindEnv("storage.max_events", "POLY_ORACLE_STORAGE_MAX_EVENTS")
 	_ = v.BindEnv("storage.max_snapshots_per_event", "POLY_ORACLE_STORAGE_MAX_SNAPSHOTS_PER_EVENT")
 	_ = v.BindEnv("storage.db_path", "POLY_ORACLE_STORAGE_DB_PATH")
+	_ = v.BindEnv("storage.change_retention", "POLY_ORACLE_STORAGE_CHANGE_RETENTION")
+
+	// Backfill
+	_ = v.BindEnv("backfill.enabled", "POLY_ORACLE_BACKFILL_ENABLED")
+	_ = v.BindEnv("backfill.lookback", "POLY_ORACLE_BACKFILL_LOOKBACK")
+	_ = v.BindEnv("backfill.request_delay", "POLY_ORACLE_BACKFILL_REQUEST_DELAY")
+	_ = v.BindEnv("backfill.max_per_cycle", "POLY_ORACLE_BACKFILL_MAX_PER_CYCLE")
+
+	// Stream
+	_ = v.BindEnv("stream.enabled", "POLY_ORACLE_STREAM_ENABLED")
+	_ = v.BindEnv("stream.url", "POLY_ORACLE_STREAM_URL")
+	_ = v.BindEnv("stream.min_snapshot_interval", "POLY_ORACLE_STREAM_MIN_SNAPSHOT_INTERVAL")
+	_ = v.BindEnv("stream.ping_interval", "POLY_ORACLE_STREAM_PING_INTERVAL")
+	_ = v.BindEnv("stream.reconnect_min", "POLY_ORACLE_STREAM_RECONNECT_MIN")
+	_ = v.BindEnv("stream.reconnect_max", "POLY_ORACLE_STREAM_RECONNECT_MAX")
+	_ = v.BindEnv("stream.retention", "POLY_ORACLE_STREAM_RETENTION")
+
+	// Resolution
+	_ = v.BindEnv("resolution.enabled", "POLY_ORACLE_RESOLUTION_ENABLED")
+	_ = v.BindEnv("resolution.recheck_interval", "POLY_ORACLE_RESOLUTION_RECHECK_INTERVAL")
+	_ = v.BindEnv("resolution.max_per_cycle", "POLY_ORACLE_RESOLUTION_MAX_PER_CYCLE")
+	_ = v.BindEnv("resolution.max_pending_age", "POLY_ORACLE_RESOLUTION_MAX_PENDING_AGE")
+
+	// Server
+	_ = v.BindEnv("server.enabled", "POLY_ORACLE_SERVER_ENABLED")
+	_ = v.BindEnv("server.listen_addr", "POLY_ORACLE_SERVER_LISTEN_ADDR")
+	_ = v.BindEnv("server.ready_stale_intervals", "POLY_ORACLE_SERVER_READY_STALE_INTERVALS")
+	_ = v.BindEnv("server.ready_empty_fetches", "POLY_ORACLE_SERVER_READY_EMPTY_FETCHES")
 
 	// Logging
 	_ = v.BindEnv("logging.level", "POLY_ORACLE_LOGGING_LEVEL")
@@ -170,6 +342,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("polymarket.max_idle_conns", 100)
 	v.SetDefault("polymarket.max_idle_conns_per_host", 10)
 	v.SetDefault("polymarket.idle_conn_timeout", "90s")
+	v.SetDefault("polymarket.fetch_order_books", true)
 
 	// Monitor defaults
 	v.SetDefault("monitor.sensitivity", 0.5) // medium quality bar
@@ -183,12 +356,59 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("telegram.enabled", false)
 	v.SetDefault("telegram.max_retries", 3)
 	v.SetDefault("telegram.retry_delay_base", "1s")
+	v.SetDefault("telegram.charts", true)
+	v.SetDefault("telegram.explain_scores", false)
+	v.SetDefault("telegram.threads", true)
+
+	// Slack, Discord and generic webhook defaults
+	for _, sink := range []string{"slack", "discord", "webhook"} {
+		v.SetDefault(sink+".enabled", false)
+		v.SetDefault(sink+".timeout", "10s")
+		v.SetDefault(sink+".max_retries", 3)
+		v.SetDefault(sink+".retry_delay_base", "1s")
+	}
+
+	// Email defaults
+	v.SetDefault("email.enabled", false)
+	v.SetDefault("email.smtp_port", 587)
+	v.SetDefault("email.starttls", true)
+	v.SetDefault("email.digest_interval", "1h")
+	v.SetDefault("email.max_pending", 100)
+	v.SetDefault("email.timeout", "30s")
 
 	// Storage defaults
 	v.SetDefault("storage.max_events", 10000)
 	v.SetDefault("storage.max_snapshots_per_event", 672) // 7 days of 15-min snapshots
+	v.SetDefault("storage.change_retention", "2160h")    // 90 days of alert history
 	v.SetDefault("storage.db_path", "")                  // empty = OS tmp dir
 
+	// Backfill defaults
+	v.SetDefault("backfill.enabled", true)
+	v.SetDefault("backfill.lookback", "24h")
+	v.SetDefault("backfill.request_delay", "200ms") // ≤5 req/s against the CLOB
+	v.SetDefault("backfill.max_per_cycle", 100)
+
+	// Stream defaults
+	v.SetDefault("stream.enabled", false)
+	v.SetDefault("stream.url", "wss://ws-subscriptions-clob.polymarket.com/ws/market")
+	v.SetDefault("stream.min_snapshot_interval", "1m")
+	v.SetDefault("stream.ping_interval", "10s")
+	v.SetDefault("stream.reconnect_min", "1s")
+	v.SetDefault("stream.reconnect_max", "2m")
+	v.SetDefault("stream.retention", "24h")
+
+	// Resolution defaults
+	v.SetDefault("resolution.enabled", true)
+	v.SetDefault("resolution.recheck_interval", "6h")
+	v.SetDefault("resolution.max_per_cycle", 50)
+	v.SetDefault("resolution.max_pending_age", "168h")
+
+	// Server defaults
+	v.SetDefault("server.enabled", false)
+	v.SetDefault("server.listen_addr", "127.0.0.1:8080")
+	v.SetDefault("server.ready_stale_intervals", 3)
+	v.SetDefault("server.ready_empty_fetches", 3)
+
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "json")
@@ -249,6 +469,41 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate webhook sinks
+	if c.Slack.Enabled && c.Slack.WebhookURL == "" {
+		return fmt.Errorf("slack.webhook_url is required when slack is enabled")
+	}
+	if c.Discord.Enabled && c.Discord.WebhookURL == "" {
+		return fmt.Errorf("discord.webhook_url is required when discord is enabled")
+	}
+	if c.Webhook.Enabled {
+		if c.Webhook.URL == "" {
+			return fmt.Errorf("webhook.url is required when webhook is enabled")
+		}
+		if c.Webhook.Secret == "" {
+			return fmt.Errorf("webhook.secret is required when webhook is enabled")
+		}
+	}
+
+	// Validate Email config
+	if c.Email.Enabled {
+		if c.Email.SMTPHost == "" {
+			return fmt.Errorf("email.smtp_host is required when email is enabled")
+		}
+		if c.Email.From == "" || len(c.Email.To) == 0 {
+			return fmt.Errorf("email.from and email.to are required when email is enabled")
+		}
+		if c.Email.SMTPPort < 1 || c.Email.SMTPPort > 65535 {
+			return fmt.Errorf("email.smtp_port must be between 1 and 65535")
+		}
+		if c.Email.DigestInterval < 0 {
+			return fmt.Errorf("email.digest_interval must not be negative")
+		}
+		if c.Email.MaxPending < 1 {
+			return fmt.Errorf("email.max_pending must be at least 1")
+		}
+	}
+
 	// Validate Storage config
 	if c.Storage.MaxEvents < 1 {
 		return fmt.Errorf("storage.max_events must be at least 1")
@@ -256,8 +511,66 @@ func (c *Config) Validate() error {
 	if c.Storage.MaxSnapshotsPerEvent < 10 {
 		return fmt.Errorf("storage.max_snapshots_per_event must be at least 10")
 	}
+	if c.Storage.ChangeRetention < 0 {
+		return fmt.Errorf("storage.change_retention must not be negative")
+	}
 	// DBPath can be empty — storage layer defaults to OS tmp directory
 
+	// Validate Backfill config
+	if c.Backfill.Enabled {
+		if c.Backfill.Lookback <= 0 {
+			return fmt.Errorf("backfill.lookback must be positive when backfill is enabled")
+		}
+		if c.Backfill.RequestDelay < 0 {
+			return fmt.Errorf("backfill.request_delay must not be negative")
+		}
+		if c.Backfill.MaxPerCycle < 1 {
+			return fmt.Errorf("backfill.max_per_cycle must be at least 1")
+		}
+	}
+
+	// Validate Stream config
+	if c.Stream.Enabled {
+		if c.Stream.URL == "" {
+			return fmt.Errorf("stream.url is required when stream is enabled")
+		}
+		if c.Stream.MinSnapshotInterval < 0 {
+			return fmt.Errorf("stream.min_snapshot_interval must not be negative")
+		}
+		if c.Stream.ReconnectMin <= 0 || c.Stream.ReconnectMax < c.Stream.ReconnectMin {
+			return fmt.Errorf("stream.reconnect_min must be positive and not exceed stream.reconnect_max")
+		}
+		if c.Stream.Retention <= 0 {
+			return fmt.Errorf("stream.retention must be positive")
+		}
+	}
+
+	// Validate Resolution config
+	if c.Resolution.Enabled {
+		if c.Resolution.RecheckInterval <= 0 {
+			return fmt.Errorf("resolution.recheck_interval must be positive when resolution tracking is enabled")
+		}
+		if c.Resolution.MaxPerCycle < 1 {
+			return fmt.Errorf("resolution.max_per_cycle must be at least 1")
+		}
+		if c.Resolution.MaxPendingAge < 0 {
+			return fmt.Errorf("resolution.max_pending_age must not be negative")
+		}
+	}
+
+	// Validate Server config
+	if c.Server.Enabled {
+		if c.Server.ListenAddr == "" {
+			return fmt.Errorf("server.listen_addr is required when server is enabled")
+		}
+		if c.Server.ReadyStaleIntervals < 1 {
+			return fmt.Errorf("server.ready_stale_intervals must be at least 1")
+		}
+		if c.Server.ReadyEmptyFetches < 0 {
+			return fmt.Errorf("server.ready_empty_fetches must not be negative")
+		}
+	}
+
 	// Validate Logging config
 	validLogLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLogLevels[c.Logging.Level] {
@@ -267,6 +580,11 @@ func (c *Config) Validate() error {
 	if !validFormats[c.Logging.Format] {
 		return fmt.Errorf("logging.format must be one of: json, text")
 	}
+	for component, level := range c.Logging.Components {
+		if !validLogLevels[level] {
+			return fmt.Errorf("logging.components.%s must be one of: debug, info, warn, error", component)
+		}
+	}
 
 	return nil
 }
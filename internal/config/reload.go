@@ -0,0 +1,210 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rewired-gh/polyoracle/internal/logger"
+)
+
+// reloadDebounce coalesces the burst of events an editor or a ConfigMap
+// update produces for a single save.
+const reloadDebounce = 500 * time.Millisecond
+
+// isHotKey reports whether a setting takes effect without a restart: the
+// monitor thresholds and the polymarket filters, which are read at the start
+// of every cycle. Everything else is wired into long-lived components at startup.
+func isHotKey(key string) bool {
+	switch key {
+	case "polymarket.categories",
+		"polymarket.volume_24hr_min",
+		"polymarket.volume_1wk_min",
+		"polymarket.volume_1mo_min",
+		"polymarket.volume_filter_or",
+		"polymarket.limit",
+		"polymarket.fetch_order_books":
+		return true
+	}
+	return strings.HasPrefix(key, "monitor.")
+}
+
+// Changes describes the outcome of a reload.
+type Changes struct {
+	Applied []string // hot settings that changed, e.g. "monitor.top_k: 5 → 3"
+	Ignored []string // keys of changed settings that need a restart
+}
+
+// Watcher holds the live configuration and reloads it when the file changes
+// or the process receives SIGHUP. Reloads swap in the hot settings only; the
+// configuration returned by Config is never modified in place.
+type Watcher struct {
+	path string
+	mu   sync.Mutex // serializes reloads
+	cfg  atomic.Pointer[Config]
+}
+
+// NewWatcher creates a Watcher for the file at path, starting from cfg.
+func NewWatcher(path string, cfg *Config) *Watcher {
+	w := &Watcher{path: path}
+	w.cfg.Store(cfg)
+	return w
+}
+
+// Config returns the current configuration. Callers should load it once per
+// cycle so a reload never takes effect halfway through one.
+func (w *Watcher) Config() *Config {
+	return w.cfg.Load()
+}
+
+// Reload reads and validates the file and swaps in its hot settings. On any
+// error the current configuration is kept.
+func (w *Watcher) Reload() (Changes, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	loaded, err := Load(w.path)
+	if err != nil {
+		return Changes{}, err
+	}
+	if err := loaded.Validate(); err != nil {
+		return Changes{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	current := w.cfg.Load()
+	next := *current
+	var changes Changes
+	diff("", reflect.ValueOf(&next).Elem(), reflect.ValueOf(loaded).Elem(), func(key string, old, updated reflect.Value) {
+		if !isHotKey(key) {
+			changes.Ignored = append(changes.Ignored, key)
+			return
+		}
+		changes.Applied = append(changes.Applied, fmt.Sprintf("%s: %v → %v", key, old.Interface(), updated.Interface()))
+		old.Set(updated)
+	})
+	if len(changes.Applied) > 0 {
+		if err := next.Validate(); err != nil {
+			return Changes{}, fmt.Errorf("invalid configuration: %w", err)
+		}
+		w.cfg.Store(&next)
+	}
+	return changes, nil
+}
+
+// diff calls fn with the dotted mapstructure key of every leaf setting that
+// differs between a and b.
+func diff(prefix string, a, b reflect.Value, fn func(key string, a, b reflect.Value)) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		fa, fb := a.Field(i), b.Field(i)
+		if fa.Kind() == reflect.Struct {
+			diff(key, fa, fb, fn)
+			continue
+		}
+		if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			fn(key, fa, fb)
+		}
+	}
+}
+
+// Run reloads the configuration on SIGHUP and whenever the file is written or
+// replaced, until ctx is cancelled. When the file cannot be watched, it logs a
+// warning and still reloads on SIGHUP.
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// Nil channels never fire, which leaves SIGHUP as the only trigger
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if fw, err := w.watchFile(); err != nil {
+		logger.Warn("Config file watching disabled, send SIGHUP to reload: %v", err)
+	} else {
+		defer fw.Close()
+		events, errs = fw.Events, fw.Errors
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			w.reloadAndLog("SIGHUP")
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if w.affects(ev) {
+				debounce = time.After(reloadDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+			w.reloadAndLog("file change")
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			logger.Warn("Config file watcher error: %v", err)
+		}
+	}
+}
+
+// watchFile starts watching the directory of the config file.
+func (w *Watcher) watchFile() (*fsnotify.Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config file watcher: %w", err)
+	}
+	// Watch the directory: editors and Kubernetes ConfigMap updates replace
+	// the file instead of writing it, which drops a watch on the file itself
+	if err := fw.Add(filepath.Dir(w.path)); err != nil {
+		fw.Close()
+		return nil, fmt.Errorf("failed to watch config directory: %w", err)
+	}
+	return fw, nil
+}
+
+// affects reports whether ev may have changed the config file. ConfigMap
+// volumes swap a "..data" symlink rather than touching the file.
+func (w *Watcher) affects(ev fsnotify.Event) bool {
+	if ev.Op == fsnotify.Chmod {
+		return false
+	}
+	return filepath.Clean(ev.Name) == filepath.Clean(w.path) || filepath.Base(ev.Name) == "..data"
+}
+
+func (w *Watcher) reloadAndLog(trigger string) {
+	changes, err := w.Reload()
+	if err != nil {
+		logger.Error("Config reload (%s) failed, keeping the current configuration: %v", trigger, err)
+		return
+	}
+	if len(changes.Applied) > 0 {
+		logger.Info("Config reloaded (%s), applying from the next cycle: %s", trigger, strings.Join(changes.Applied, "; "))
+	} else {
+		logger.Debug("Config reload (%s): no hot settings changed", trigger)
+	}
+	if len(changes.Ignored) > 0 {
+		logger.Warn("Config changes to %s need a restart and were not applied", strings.Join(changes.Ignored, ", "))
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const reloadBase = `
+polymarket:
+  poll_interval: 15m
+  categories: [politics]
+monitor:
+  sensitivity: 0.5
+  top_k: 5
+storage:
+  db_path: "%DB%"
+`
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	content = strings.ReplaceAll(content, "%DB%", filepath.Join(filepath.Dir(path), "test.db"))
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestWatcher(t *testing.T) (*Watcher, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, reloadBase)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return NewWatcher(path, cfg), path
+}
+
+func TestWatcher_ReloadAppliesHotSettings(t *testing.T) {
+	w, path := newTestWatcher(t)
+	before := w.Config()
+
+	writeConfig(t, path, `
+polymarket:
+  poll_interval: 30m
+  categories: [politics, crypto]
+monitor:
+  sensitivity: 0.8
+  top_k: 3
+storage:
+  db_path: "%DB%"
+`)
+	changes, err := w.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	after := w.Config()
+	if after.Monitor.TopK != 3 || after.Monitor.Sensitivity != 0.8 || len(after.Polymarket.Categories) != 2 {
+		t.Errorf("hot settings not applied: %+v %+v", after.Monitor, after.Polymarket.Categories)
+	}
+	if after.Polymarket.PollInterval != 15*time.Minute {
+		t.Errorf("poll_interval needs a restart, got %v", after.Polymarket.PollInterval)
+	}
+	if before.Monitor.TopK != 5 {
+		t.Errorf("previous config was modified in place: top_k = %d", before.Monitor.TopK)
+	}
+
+	applied := strings.Join(changes.Applied, "\n")
+	for _, want := range []string{"monitor.top_k: 5 → 3", "monitor.sensitivity: 0.5 → 0.8", "polymarket.categories: [politics] → [politics crypto]"} {
+		if !strings.Contains(applied, want) {
+			t.Errorf("missing %q in applied changes:\n%s", want, applied)
+		}
+	}
+	if len(changes.Ignored) != 1 || changes.Ignored[0] != "polymarket.poll_interval" {
+		t.Errorf("Ignored = %v, want [polymarket.poll_interval]", changes.Ignored)
+	}
+}
+
+func TestWatcher_InvalidReloadKeepsConfig(t *testing.T) {
+	w, path := newTestWatcher(t)
+	before := w.Config()
+
+	writeConfig(t, path, strings.Replace(reloadBase, "sensitivity: 0.5", "sensitivity: 1.5", 1))
+	if _, err := w.Reload(); err == nil {
+		t.Fatal("want validation error")
+	}
+	writeConfig(t, path, "monitor: [not, a, map")
+	if _, err := w.Reload(); err == nil {
+		t.Fatal("want parse error")
+	}
+	if w.Config() != before {
+		t.Error("an invalid reload should keep the current configuration")
+	}
+}
+
+func TestWatcher_RunReloadsOnFileChange(t *testing.T) {
+	w, path := newTestWatcher(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() { w.Run(ctx); close(done) }()
+
+	// Give the watcher time to register before writing
+	time.Sleep(100 * time.Millisecond)
+	writeConfig(t, path, strings.Replace(reloadBase, "top_k: 5", "top_k: 7", 1))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Config().Monitor.TopK != 7 {
+		if time.Now().After(deadline) {
+			t.Fatal("config was not reloaded after the file changed")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestWatcher_RunHandlesSIGHUPWithoutFileWatch(t *testing.T) {
+	w := NewWatcher(filepath.Join(t.TempDir(), "missing", "config.yaml"), &Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() { w.Run(ctx); close(done) }()
+
+	// Without a handler, SIGHUP would terminate the test binary
+	time.Sleep(100 * time.Millisecond)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Run returned although the config directory cannot be watched")
+	default:
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,110 @@
+// Package health serves liveness and readiness checks for orchestrators.
+//
+// /healthz reports that the process is up and serving HTTP. /readyz also
+// checks that the monitoring loop is making progress: a cycle succeeded
+// recently, the database answers, and the Gamma API has not returned empty
+// fetches several times in a row (which happens when it silently serves
+// empty pages).
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/status"
+)
+
+// pingTimeout bounds the database check. The database has a single
+// connection, so a long cycle transaction delays the check.
+const pingTimeout = 2 * time.Second
+
+// Config sets the readiness thresholds.
+type Config struct {
+	PollInterval    time.Duration // monitoring cycle interval
+	StaleIntervals  int           // not ready when the last success is older than StaleIntervals × PollInterval
+	MaxEmptyFetches int           // not ready after this many consecutive empty fetches; 0 disables the check
+}
+
+// Pinger checks that a database is reachable; storage.Storage implements it.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Checker evaluates liveness and readiness from the loop's status tracker.
+type Checker struct {
+	tracker *status.Tracker
+	db      Pinger
+	cfg     Config
+	now     func() time.Time
+}
+
+// New creates a Checker.
+func New(tracker *status.Tracker, db Pinger, cfg Config) *Checker {
+	return &Checker{tracker: tracker, db: db, cfg: cfg, now: time.Now}
+}
+
+// report is the JSON body of both endpoints.
+type report struct {
+	Status string            `json:"status"`           // "ok" or "unavailable"
+	Checks map[string]string `json:"checks,omitempty"` // check name → "ok" or why it failed
+}
+
+// Healthz serves the liveness check: 200 while the process serves requests.
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, report{Status: "ok"})
+}
+
+// Readyz serves the readiness check: 200 when every check passes, 503 otherwise.
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	checks, ready := c.Ready(r.Context())
+	rep := report{Status: "ok", Checks: checks}
+	if !ready {
+		rep.Status = "unavailable"
+	}
+	writeReport(w, rep)
+}
+
+// Ready runs the readiness checks and returns each one's result.
+func (c *Checker) Ready(ctx context.Context) (checks map[string]string, ready bool) {
+	s := c.tracker.Snapshot()
+	now := c.now()
+	checks = map[string]string{"cycle": "ok", "database": "ok", "fetch": "ok"}
+	ready = true
+
+	// Before the first success, the service start is the reference, so a
+	// fresh instance gets the same grace as a healthy one
+	limit := time.Duration(c.cfg.StaleIntervals) * c.cfg.PollInterval
+	last, what := s.LastSuccess, "last successful cycle"
+	if last.IsZero() {
+		last, what = s.StartedAt, "no successful cycle since start"
+	}
+	if age := now.Sub(last); limit > 0 && age > limit {
+		checks["cycle"] = fmt.Sprintf("%s %s ago (limit %s)", what, age.Round(time.Second), limit)
+		ready = false
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := c.db.Ping(pingCtx); err != nil {
+		checks["database"] = err.Error()
+		ready = false
+	}
+
+	if c.cfg.MaxEmptyFetches > 0 && s.EmptyFetches >= c.cfg.MaxEmptyFetches {
+		checks["fetch"] = fmt.Sprintf("last %d fetches returned no markets", s.EmptyFetches)
+		ready = false
+	}
+	return checks, ready
+}
+
+func writeReport(w http.ResponseWriter, rep report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if rep.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(rep)
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/status"
+)
+
+type fakeDB struct{ err error }
+
+func (f fakeDB) Ping(context.Context) error { return f.err }
+
+func readyz(t *testing.T, c *Checker) (int, report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var rep report
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, rep
+}
+
+func TestReadyz(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := Config{PollInterval: 15 * time.Minute, StaleIntervals: 3, MaxEmptyFetches: 2}
+
+	tests := []struct {
+		name   string
+		setup  func(tr *status.Tracker)
+		now    time.Duration // since start
+		db     error
+		failed string // failing check; "" = ready
+	}{
+		{"fresh instance within grace", func(*status.Tracker) {}, 10 * time.Minute, nil, ""},
+		{"no success since start", func(*status.Tracker) {}, 50 * time.Minute, nil, "cycle"},
+		{"recent success", func(tr *status.Tracker) {
+			tr.CycleFinished(start.Add(55*time.Minute), start.Add(time.Hour), nil)
+		}, 90 * time.Minute, nil, ""},
+		{"stale success", func(tr *status.Tracker) {
+			tr.CycleFinished(start, start.Add(time.Minute), nil)
+			tr.CycleFinished(start.Add(15*time.Minute), start.Add(16*time.Minute), errors.New("boom"))
+		}, time.Hour, nil, "cycle"},
+		{"database down", func(*status.Tracker) {}, time.Minute, errors.New("disk I/O error"), "database"},
+		{"empty fetches", func(tr *status.Tracker) {
+			tr.CycleFinished(start, start.Add(time.Minute), nil)
+			tr.FetchFinished(0)
+			tr.FetchFinished(0)
+		}, 2 * time.Minute, nil, "fetch"},
+		{"one empty fetch is tolerated", func(tr *status.Tracker) {
+			tr.FetchFinished(0)
+		}, time.Minute, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := status.New(start)
+			tt.setup(tr)
+			c := New(tr, fakeDB{err: tt.db}, cfg)
+			c.now = func() time.Time { return start.Add(tt.now) }
+
+			code, rep := readyz(t, c)
+			if tt.failed == "" {
+				if code != http.StatusOK || rep.Status != "ok" {
+					t.Errorf("want ready, got %d %+v", code, rep)
+				}
+				return
+			}
+			if code != http.StatusServiceUnavailable || rep.Status != "unavailable" || rep.Checks[tt.failed] == "ok" {
+				t.Errorf("want %s check to fail, got %d %+v", tt.failed, code, rep)
+			}
+			for name, result := range rep.Checks {
+				if name != tt.failed && result != "ok" {
+					t.Errorf("only %s should fail, %s: %s", tt.failed, name, result)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	c := New(status.New(time.Now()), fakeDB{err: errors.New("down")}, Config{})
+	rec := httptest.NewRecorder()
+	c.Healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"status":"ok"`) {
+		t.Errorf("liveness should not depend on readiness checks, got %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -1,106 +1,208 @@
-// Package logger provides leveled logging with support for debug, info, warn, and error levels.
-// It wraps the standard log package to provide level-based filtering and formatted output.
+// Package logger provides leveled, structured logging on log/slog.
+//
+// Messages are printf-formatted; structured fields are attached with With
+// using the keys below, so every sink of a log pipeline can filter on them.
+// Output is JSON (one object per line) or slog's key=value text. Each line
+// carries the component (Go package) that logged it, and the level can be
+// overridden per component.
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"runtime"
 	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Structured field keys shared across packages.
+const (
+	CycleIDKey   = "cycle_id"  // correlation ID of one monitoring cycle
+	MarketIDKey  = "market_id" // composite market ID (EventID:MarketID)
+	EventIDKey   = "event_id"  // parent Polymarket event ID
+	ScoreKey     = "score"     // composite signal score
+	SinkKey      = "sink"      // notifier destination, e.g. "telegram:-100123"
+	ComponentKey = "component" // package that logged the line, added automatically
 )
 
 // Level represents a logging level
-type Level int
+type Level = slog.Level
 
 const (
 	// DebugLevel logs are typically voluminous, and are usually disabled in production.
-	DebugLevel Level = iota
+	DebugLevel = slog.LevelDebug
 	// InfoLevel is the default logging priority.
-	InfoLevel
+	InfoLevel = slog.LevelInfo
 	// WarnLevel logs are more important than Info, but don't need individual human review.
-	WarnLevel
+	WarnLevel = slog.LevelWarn
 	// ErrorLevel logs are high-priority. If an application is running smoothly, it shouldn't generate any error-level logs.
-	ErrorLevel
+	ErrorLevel = slog.LevelError
+	// fatalLevel is used by Fatal.
+	fatalLevel = slog.LevelError + 4
 )
 
-// Logger provides leveled logging
-type Logger struct {
-	level  Level
-	logger *log.Logger
+// state is the active configuration; it is replaced as a whole by Init.
+type state struct {
+	handler    slog.Handler
+	level      Level
+	components map[string]Level // per-component overrides
 }
 
-var (
-	// Global logger instance
-	defaultLogger *Logger
-)
+// current is nil until Init is called; until then log lines are discarded.
+var current atomic.Pointer[state]
+
+// Init configures logging: level is the default level, format "json" or
+// "text", and components maps component names (Go package names such as
+// "telegram" or "main") to their own level. Unknown levels fall back to info.
+// Init may be called again to reconfigure logging.
+func Init(level, format string, components map[string]string) {
+	initWriter(os.Stderr, level, format, components)
+}
 
-// Init initializes the default logger with the specified level and format
-func Init(level string, format string) {
-	var l Level
+func initWriter(w io.Writer, level, format string, components map[string]string) {
+	// Filtering is done before records are built, so the handler accepts everything
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: replaceLevel}
+	var h slog.Handler
+	if strings.ToLower(format) == "text" {
+		opts.AddSource = true
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	s := &state{handler: h, level: ParseLevel(level), components: make(map[string]Level, len(components))}
+	for name, l := range components {
+		s.components[strings.ToLower(name)] = ParseLevel(l)
+	}
+	current.Store(s)
+}
+
+// ParseLevel parses debug, info, warn or error (case-insensitive); anything
+// else is info.
+func ParseLevel(level string) Level {
 	switch strings.ToLower(level) {
 	case "debug":
-		l = DebugLevel
-	case "info":
-		l = InfoLevel
+		return DebugLevel
 	case "warn":
-		l = WarnLevel
+		return WarnLevel
 	case "error":
-		l = ErrorLevel
+		return ErrorLevel
 	default:
-		l = InfoLevel
+		return InfoLevel
 	}
+}
 
-	// Set log flags based on format
-	flags := log.LstdFlags | log.Lmicroseconds
-	if strings.ToLower(format) == "text" {
-		flags |= log.Lshortfile
+// replaceLevel names the fatal level.
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if l, ok := a.Value.Any().(slog.Level); ok && l == fatalLevel {
+			return slog.String(slog.LevelKey, "FATAL")
+		}
 	}
+	return a
+}
 
-	defaultLogger = &Logger{
-		level:  l,
-		logger: log.New(os.Stderr, "", flags),
-	}
+// Logger logs with a fixed set of structured fields. The zero value logs
+// without fields.
+type Logger struct {
+	attrs []any
 }
 
-// Debug logs a message at DebugLevel
-func Debug(format string, args ...interface{}) {
-	if defaultLogger != nil && defaultLogger.level <= DebugLevel {
-		msg := fmt.Sprintf("[DEBUG] "+format, args...)
-		_ = defaultLogger.logger.Output(2, msg)
-	}
+// With returns a Logger that adds the given key-value pairs to every line.
+func With(args ...any) *Logger {
+	return (&Logger{}).With(args...)
 }
 
-// Info logs a message at InfoLevel
-func Info(format string, args ...interface{}) {
-	if defaultLogger != nil && defaultLogger.level <= InfoLevel {
-		msg := fmt.Sprintf("[INFO] "+format, args...)
-		_ = defaultLogger.logger.Output(2, msg)
-	}
+// With returns a Logger with the fields of l plus the given key-value pairs.
+func (l *Logger) With(args ...any) *Logger {
+	attrs := make([]any, 0, len(l.attrs)+len(args))
+	attrs = append(attrs, l.attrs...)
+	return &Logger{attrs: append(attrs, args...)}
 }
 
-// Warn logs a message at WarnLevel
-func Warn(format string, args ...interface{}) {
-	if defaultLogger != nil && defaultLogger.level <= WarnLevel {
-		msg := fmt.Sprintf("[WARN] "+format, args...)
-		_ = defaultLogger.logger.Output(2, msg)
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Logger carried by ctx, or one without fields.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
 	}
+	return &Logger{}
 }
 
+// Debug logs a message at DebugLevel
+func (l *Logger) Debug(format string, args ...any) { l.log(DebugLevel, format, args) }
+
+// Info logs a message at InfoLevel
+func (l *Logger) Info(format string, args ...any) { l.log(InfoLevel, format, args) }
+
+// Warn logs a message at WarnLevel
+func (l *Logger) Warn(format string, args ...any) { l.log(WarnLevel, format, args) }
+
+// Error logs a message at ErrorLevel
+func (l *Logger) Error(format string, args ...any) { l.log(ErrorLevel, format, args) }
+
+// Debug logs a message at DebugLevel
+func Debug(format string, args ...any) { (&Logger{}).log(DebugLevel, format, args) }
+
+// Info logs a message at InfoLevel
+func Info(format string, args ...any) { (&Logger{}).log(InfoLevel, format, args) }
+
+// Warn logs a message at WarnLevel
+func Warn(format string, args ...any) { (&Logger{}).log(WarnLevel, format, args) }
+
 // Error logs a message at ErrorLevel
-func Error(format string, args ...interface{}) {
-	if defaultLogger != nil && defaultLogger.level <= ErrorLevel {
-		msg := fmt.Sprintf("[ERROR] "+format, args...)
-		_ = defaultLogger.logger.Output(2, msg)
+func Error(format string, args ...any) { (&Logger{}).log(ErrorLevel, format, args) }
+
+// Fatal logs a message at FATAL level, regardless of configured levels, and exits
+func Fatal(format string, args ...any) {
+	(&Logger{}).log(fatalLevel, format, args)
+	os.Exit(1)
+}
+
+// log builds and writes one record. It must be called directly by the
+// exported logging functions, so the caller is two frames up.
+func (l *Logger) log(level Level, format string, args []any) {
+	s := current.Load()
+	if s == nil {
+		return
 	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // runtime.Callers, log, Debug/Info/...
+	component := componentOf(pcs[0])
+
+	minLevel, ok := s.components[component]
+	if !ok {
+		minLevel = s.level
+	}
+	if level < minLevel {
+		return
+	}
+
+	r := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, args...), pcs[0])
+	r.AddAttrs(slog.String(ComponentKey, component))
+	r.Add(l.attrs...)
+	_ = s.handler.Handle(context.Background(), r)
 }
 
-// Fatal logs a message at ErrorLevel and exits
-func Fatal(format string, args ...interface{}) {
-	msg := fmt.Sprintf("[FATAL] "+format, args...)
-	if defaultLogger != nil {
-		_ = defaultLogger.logger.Output(2, msg)
+// componentOf returns the package name of the function at pc, e.g.
+// "telegram" for github.com/rewired-gh/polyoracle/internal/telegram.(*Client).Send.
+func componentOf(pc uintptr) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	name := frame.Function
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
 	}
-	// Use os.Exit directly instead of log.Fatal to avoid double-logging.
-	// The message has already been written to defaultLogger above.
-	os.Exit(1)
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	return name
 }
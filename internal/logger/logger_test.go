@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("line is not JSON: %q: %v", line, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestJSONWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	initWriter(&buf, "info", "json", nil)
+	t.Cleanup(func() { current.Store(nil) })
+
+	log := With(CycleIDKey, "c1")
+	log.With(MarketIDKey, "e1:m1", ScoreKey, 0.42).Warn("market %s moved", "m1")
+	log.Debug("filtered out")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 1 {
+		t.Fatalf("want 1 line, got %d: %s", len(lines), buf.String())
+	}
+	got := lines[0]
+	for key, want := range map[string]any{
+		"level":      "WARN",
+		"msg":        "market m1 moved",
+		CycleIDKey:   "c1",
+		MarketIDKey:  "e1:m1",
+		ScoreKey:     0.42,
+		ComponentKey: "logger",
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+}
+
+func TestComponentOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	initWriter(&buf, "warn", "json", map[string]string{"Logger": "debug"})
+	t.Cleanup(func() { current.Store(nil) })
+
+	Debug("enabled by the override")
+	if n := len(decodeLines(t, &buf)); n != 1 {
+		t.Fatalf("component override should enable debug, got %d lines", n)
+	}
+
+	buf.Reset()
+	initWriter(&buf, "debug", "json", map[string]string{"logger": "error"})
+	Warn("suppressed by the override")
+	if buf.Len() != 0 {
+		t.Errorf("component override should suppress warn, got %s", buf.String())
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	initWriter(&buf, "info", "text", nil)
+	t.Cleanup(func() { current.Store(nil) })
+
+	With(SinkKey, "slack").Error("delivery failed")
+	out := buf.String()
+	for _, want := range []string{"level=ERROR", `msg="delivery failed"`, "sink=slack", "component=logger", "source=", "logger_test.go"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %s", want, out)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	if l := FromContext(context.Background()); l == nil || len(l.attrs) != 0 {
+		t.Errorf("empty context should give a logger without fields, got %+v", l)
+	}
+	l := With(CycleIDKey, "c1")
+	if got := FromContext(NewContext(context.Background(), l)); got != l {
+		t.Errorf("FromContext = %p, want %p", got, l)
+	}
+}
+
+func TestUninitializedDiscards(t *testing.T) {
+	current.Store(nil)
+	Info("no handler, no panic")
+}
@@ -0,0 +1,170 @@
+// Package metrics exposes the monitoring loop's health and throughput in the
+// Prometheus text format: how long cycles take, how many markets and changes
+// they handle, how alert delivery and the Polymarket APIs behave, and how
+// large the database has grown.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics is the set of metrics exported by polyoracle. Fields are safe for
+// concurrent use; a nil *Metrics ignores every update.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	CycleDuration       prometheus.Histogram   // polyoracle_cycle_duration_seconds
+	Cycles              *prometheus.CounterVec // polyoracle_cycles_total{result}
+	ConsecutiveFailures prometheus.Gauge       // polyoracle_consecutive_failures
+	MarketsFetched      prometheus.Gauge       // polyoracle_markets_fetched: markets in the latest fetch
+	MarketsNew          prometheus.Counter     // polyoracle_markets_new_total
+	MarketsUpdated      prometheus.Counter     // polyoracle_markets_updated_total
+	ChangesDetected     prometheus.Counter     // polyoracle_changes_detected_total
+	GroupsPassed        prometheus.Counter     // polyoracle_groups_passed_total
+	Notifications       *prometheus.CounterVec // polyoracle_notifications_total{sink,result}
+	APIResponses        *prometheus.CounterVec // polyoracle_polymarket_responses_total{api,code}
+	APIRetries          *prometheus.CounterVec // polyoracle_polymarket_retries_total{api}
+	DBSize              prometheus.Gauge       // polyoracle_db_size_bytes
+	SnapshotRows        prometheus.Gauge       // polyoracle_snapshot_rows
+}
+
+// cycleBuckets are the cycle duration histogram bounds, in seconds.
+var cycleBuckets = []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// New creates the polyoracle metrics in a fresh registry.
+func New() *Metrics {
+	r := prometheus.NewRegistry()
+	f := promauto.With(r)
+	return &Metrics{
+		registry: r,
+		CycleDuration: f.NewHistogram(prometheus.HistogramOpts{Name: "polyoracle_cycle_duration_seconds",
+			Help: "Duration of monitoring cycles.", Buckets: cycleBuckets}),
+		Cycles: f.NewCounterVec(prometheus.CounterOpts{Name: "polyoracle_cycles_total",
+			Help: "Monitoring cycles by result (success or failure)."}, []string{"result"}),
+		ConsecutiveFailures: f.NewGauge(prometheus.GaugeOpts{Name: "polyoracle_consecutive_failures",
+			Help: "Monitoring cycles failed in a row; 0 after a success."}),
+		MarketsFetched: f.NewGauge(prometheus.GaugeOpts{Name: "polyoracle_markets_fetched",
+			Help: "Markets returned by the latest Gamma API fetch."}),
+		MarketsNew: f.NewCounter(prometheus.CounterOpts{Name: "polyoracle_markets_new_total",
+			Help: "Markets stored for the first time."}),
+		MarketsUpdated: f.NewCounter(prometheus.CounterOpts{Name: "polyoracle_markets_updated_total",
+			Help: "Updates of already tracked markets."}),
+		ChangesDetected: f.NewCounter(prometheus.CounterOpts{Name: "polyoracle_changes_detected_total",
+			Help: "Probability changes detected above the floor, before scoring."}),
+		GroupsPassed: f.NewCounter(prometheus.CounterOpts{Name: "polyoracle_groups_passed_total",
+			Help: "Event groups ranked into the top-K above the quality bar, before cooldown deduplication."}),
+		Notifications: f.NewCounterVec(prometheus.CounterOpts{Name: "polyoracle_notifications_total",
+			Help: "Alert deliveries per sink by result (sent or failed)."}, []string{"sink", "result"}),
+		APIResponses: f.NewCounterVec(prometheus.CounterOpts{Name: "polyoracle_polymarket_responses_total",
+			Help: "Polymarket API responses by API (gamma or clob) and HTTP status code; code is \"error\" when no response arrived."}, []string{"api", "code"}),
+		APIRetries: f.NewCounterVec(prometheus.CounterOpts{Name: "polyoracle_polymarket_retries_total",
+			Help: "Polymarket API requests retried after a network error or 5xx response."}, []string{"api"}),
+		DBSize: f.NewGauge(prometheus.GaugeOpts{Name: "polyoracle_db_size_bytes",
+			Help: "Size of the SQLite database file, as of the latest cycle."}),
+		SnapshotRows: f.NewGauge(prometheus.GaugeOpts{Name: "polyoracle_snapshot_rows",
+			Help: "Probability snapshots stored, as of the latest cycle."}),
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveCycle records a finished monitoring cycle and the resulting number
+// of consecutive failures.
+func (m *Metrics) ObserveCycle(seconds float64, err error, consecutiveFailures int) {
+	if m == nil {
+		return
+	}
+	m.CycleDuration.Observe(seconds)
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.Cycles.WithLabelValues(result).Inc()
+	m.ConsecutiveFailures.Set(float64(consecutiveFailures))
+}
+
+// ObserveIngest records the markets of one fetch and how many were new or updated.
+func (m *Metrics) ObserveIngest(fetched, added, updated int) {
+	if m == nil {
+		return
+	}
+	m.MarketsFetched.Set(float64(fetched))
+	m.MarketsNew.Add(float64(added))
+	m.MarketsUpdated.Add(float64(updated))
+}
+
+// ObserveDetection records the changes detected and groups passed in one cycle.
+func (m *Metrics) ObserveDetection(changes, groups int) {
+	if m == nil {
+		return
+	}
+	m.ChangesDetected.Add(float64(changes))
+	m.GroupsPassed.Add(float64(groups))
+}
+
+// ObserveNotification records one delivery attempt to sink.
+func (m *Metrics) ObserveNotification(sink string, err error) {
+	if m == nil {
+		return
+	}
+	result := "sent"
+	if err != nil {
+		result = "failed"
+	}
+	m.Notifications.WithLabelValues(sink, result).Inc()
+}
+
+// ObserveAPIResponse records a Polymarket API response; status 0 means the
+// request failed without one. It matches polymarket.ClientConfig.OnResponse.
+func (m *Metrics) ObserveAPIResponse(api string, status int) {
+	if m == nil {
+		return
+	}
+	code := "error"
+	if status > 0 {
+		code = strconv.Itoa(status)
+	}
+	m.APIResponses.WithLabelValues(api, code).Inc()
+}
+
+// ObserveAPIRetry records a retried Polymarket API request. It matches
+// polymarket.ClientConfig.OnRetry.
+func (m *Metrics) ObserveAPIRetry(api string) {
+	if m == nil {
+		return
+	}
+	m.APIRetries.WithLabelValues(api).Inc()
+}
+
+// StorageStats reports database size; storage.Storage implements it.
+type StorageStats interface {
+	DBSize() (int64, error)
+	CountSnapshots() (int, error)
+}
+
+// ObserveStorage records the size of store. It runs queries on the database,
+// so it is called once per cycle rather than on every scrape. When a query
+// fails, its gauge keeps the previous value.
+func (m *Metrics) ObserveStorage(store StorageStats) error {
+	if m == nil {
+		return nil
+	}
+	size, sizeErr := store.DBSize()
+	if sizeErr == nil {
+		m.DBSize.Set(float64(size))
+	}
+	rows, rowsErr := store.CountSnapshots()
+	if rowsErr == nil {
+		m.SnapshotRows.Set(float64(rows))
+	}
+	return errors.Join(sizeErr, rowsErr)
+}
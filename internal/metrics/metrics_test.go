@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeStats struct{ err error }
+
+func (f fakeStats) DBSize() (int64, error)       { return 4096, f.err }
+func (f fakeStats) CountSnapshots() (int, error) { return 12, f.err }
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	return rec.Body.String()
+}
+
+func TestMetrics_Exposition(t *testing.T) {
+	m := New()
+	if err := m.ObserveStorage(fakeStats{}); err != nil {
+		t.Fatalf("ObserveStorage: %v", err)
+	}
+	m.ObserveCycle(0.7, nil, 0)
+	m.ObserveCycle(42, errors.New("boom"), 1)
+	m.ObserveIngest(120, 3, 117)
+	m.ObserveDetection(9, 2)
+	m.ObserveNotification("telegram:100", nil)
+	m.ObserveNotification("slack", errors.New("down"))
+	m.ObserveAPIResponse("gamma", 200)
+	m.ObserveAPIResponse("gamma", 0)
+	m.ObserveAPIRetry("gamma")
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		"# TYPE polyoracle_cycle_duration_seconds histogram\n",
+		`polyoracle_cycle_duration_seconds_bucket{le="0.5"} 0` + "\n",
+		`polyoracle_cycle_duration_seconds_bucket{le="1"} 1` + "\n",
+		`polyoracle_cycle_duration_seconds_bucket{le="60"} 2` + "\n",
+		`polyoracle_cycle_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"polyoracle_cycle_duration_seconds_sum 42.7\n",
+		"polyoracle_cycle_duration_seconds_count 2\n",
+		`polyoracle_cycles_total{result="failure"} 1` + "\n",
+		`polyoracle_cycles_total{result="success"} 1` + "\n",
+		"polyoracle_consecutive_failures 1\n",
+		"polyoracle_markets_fetched 120\n",
+		"polyoracle_markets_new_total 3\n",
+		"polyoracle_markets_updated_total 117\n",
+		"polyoracle_changes_detected_total 9\n",
+		"polyoracle_groups_passed_total 2\n",
+		`polyoracle_notifications_total{result="failed",sink="slack"} 1` + "\n",
+		`polyoracle_notifications_total{result="sent",sink="telegram:100"} 1` + "\n",
+		`polyoracle_polymarket_responses_total{api="gamma",code="200"} 1` + "\n",
+		`polyoracle_polymarket_responses_total{api="gamma",code="error"} 1` + "\n",
+		`polyoracle_polymarket_retries_total{api="gamma"} 1` + "\n",
+		"polyoracle_db_size_bytes 4096\n",
+		"polyoracle_snapshot_rows 12\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_ObserveStorageKeepsLastValueOnError(t *testing.T) {
+	m := New()
+	if err := m.ObserveStorage(fakeStats{}); err != nil {
+		t.Fatalf("ObserveStorage: %v", err)
+	}
+	if err := m.ObserveStorage(fakeStats{err: errors.New("database is locked")}); err == nil {
+		t.Error("expected the query error to be returned")
+	}
+	body := scrape(t, m)
+	if !strings.Contains(body, "polyoracle_db_size_bytes 4096\n") {
+		t.Errorf("a failed update should keep the previous value:\n%s", body)
+	}
+	// Unlabelled counters are exposed from the start
+	if !strings.Contains(body, "polyoracle_changes_detected_total 0\n") {
+		t.Errorf("unlabelled counter should start at zero:\n%s", body)
+	}
+}
+
+func TestMetrics_NilIgnoresUpdates(t *testing.T) {
+	var m *Metrics
+	m.ObserveCycle(1, nil, 0)
+	m.ObserveNotification("slack", nil)
+	m.ObserveAPIRetry("clob")
+	if err := m.ObserveStorage(fakeStats{}); err != nil {
+		t.Errorf("ObserveStorage on nil: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"math"
+	"strings"
 	"time"
 )
 
@@ -14,21 +15,63 @@ import (
 // along with the old and new probabilities, enabling users to understand
 // market sentiment changes over time.
 type Change struct {
-	ID              string        `json:"id"`
-	EventID         string        `json:"event_id"`          // Composite market ID: "EventID:MarketID"
-	OriginalEventID string        `json:"original_event_id"` // Parent Polymarket event ID
-	EventTitle      string        `json:"event_title"`       // Parent event title (e.g. "IPOs before 2027?")
-	EventURL        string        `json:"event_url"`         // URL to the parent Polymarket event page
-	MarketID        string        `json:"market_id"`         // Polymarket market ID
-	MarketQuestion  string        `json:"market_question"`   // Yes/no question for this market
-	Magnitude       float64       `json:"magnitude"`         // Absolute probability change (0.0 to 1.0)
-	Direction       string        `json:"direction"`         // "increase" or "decrease"
-	OldProbability  float64       `json:"old_probability"`
-	NewProbability  float64       `json:"new_probability"`
-	TimeWindow      time.Duration `json:"time_window"` // Duration over which change was detected
-	DetectedAt      time.Time     `json:"detected_at"`
-	Notified        bool          `json:"notified"`               // Whether notification was sent
-	SignalScore     float64       `json:"signal_score,omitempty"` // composite score from scoring algorithm; 0 = unscored
+	ID              string          `json:"id"`
+	EventID         string          `json:"event_id"`          // Composite market ID: "EventID:MarketID"
+	OriginalEventID string          `json:"original_event_id"` // Parent Polymarket event ID
+	EventTitle      string          `json:"event_title"`       // Parent event title (e.g. "IPOs before 2027?")
+	EventURL        string          `json:"event_url"`         // URL to the parent Polymarket event page
+	MarketID        string          `json:"market_id"`         // Polymarket market ID
+	MarketQuestion  string          `json:"market_question"`   // Question for this market
+	Category        string          `json:"category,omitempty"`
+	Outcome         string          `json:"outcome,omitempty"` // Outcome that moved; "" = Yes of a Yes/No market
+	Magnitude       float64         `json:"magnitude"`         // Absolute probability change (0.0 to 1.0)
+	Direction       string          `json:"direction"`         // "increase" or "decrease"
+	OldProbability  float64         `json:"old_probability"`
+	NewProbability  float64         `json:"new_probability"`
+	TimeWindow      time.Duration   `json:"time_window"` // Duration over which change was detected
+	DetectedAt      time.Time       `json:"detected_at"`
+	Notified        bool            `json:"notified"`               // Whether notification was sent
+	SignalScore     float64         `json:"signal_score,omitempty"` // composite score from scoring algorithm; 0 = unscored
+	PassedThreshold bool            `json:"passed_threshold"`       // Whether SignalScore cleared min_score
+	Breakdown       *ScoreBreakdown `json:"breakdown,omitempty"`    // How SignalScore was computed; nil for changes scored before it was recorded
+	Deliveries      []Delivery      `json:"deliveries,omitempty"`   // Where the alert was sent (populated by history queries)
+}
+
+// ScoreBreakdown records the factors of a change's SignalScore
+// (KL × VolumeWeight × SNR × TrajectoryConsistency × SpreadWeight), the
+// thresholds it was judged against and the filters and routing rules that
+// affected it.
+type ScoreBreakdown struct {
+	KL                    float64 `json:"kl"`
+	VolumeWeight          float64 `json:"volume_weight"`
+	SNR                   float64 `json:"snr"`
+	TrajectoryConsistency float64 `json:"trajectory_consistency"`
+	SpreadWeight          float64 `json:"spread_weight"`
+
+	MinScore     float64 `json:"min_score"`      // alert bar applied
+	MinAbsChange float64 `json:"min_abs_change"` // pre-score filter; 0 = disabled
+	MinBaseProb  float64 `json:"min_base_prob"`  // pre-score filter; 0 = disabled
+
+	ConfirmationBypass bool `json:"confirmation_bypass,omitempty"` // min_abs_change skipped: entered >95% or <5%
+	WatchBypass        bool `json:"watch_bypass,omitempty"`        // a pre-score filter skipped: the event or market is watched
+	HistoryUnavailable bool `json:"history_unavailable,omitempty"` // SNR and TC defaulted to 1: history failed to load
+
+	Routing        string  `json:"routing,omitempty"`         // RoutingWatch or RoutingMute when a rule decided the outcome
+	WatchThreshold float64 `json:"watch_threshold,omitempty"` // threshold of the watch that promoted the change
+}
+
+// Routing rules recorded in ScoreBreakdown.Routing.
+const (
+	RoutingWatch = "watch" // passed through a watch below the global bar
+	RoutingMute  = "mute"  // silenced by a mute
+)
+
+// Delivery records one successful send of an alert containing a change.
+type Delivery struct {
+	ChangeID    string    `json:"change_id"`
+	Destination string    `json:"destination"` // e.g. "telegram:-1001234567890"
+	MessageID   string    `json:"message_id"`  // Destination-specific message identifier
+	SentAt      time.Time `json:"sent_at"`
 }
 
 // Event represents a Polymarket event — a group of related markets sharing the
@@ -38,10 +81,33 @@ type Event struct {
 	ID        string   // Polymarket event ID
 	Title     string   // Event title
 	URL       string   // URL to the Polymarket event page
+	Category  string   // Category of the event's markets
 	BestScore float64  // Highest signal score among markets in this event
 	Markets   []Change // Individual market changes, sorted by score desc
 }
 
+// Slug returns the event's URL slug (the path segment after /event/), or ""
+// when the URL has none.
+func (e *Event) Slug() string {
+	_, slug, ok := strings.Cut(e.URL, "/event/")
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexAny(slug, "/?#"); i >= 0 {
+		slug = slug[:i]
+	}
+	return slug
+}
+
+// Key identifies the price series this change was detected on: the composite
+// market ID, suffixed with the outcome name for named-outcome markets.
+func (c *Change) Key() string {
+	if c.Outcome == "" {
+		return c.EventID
+	}
+	return c.EventID + "|" + c.Outcome
+}
+
 // Validate checks that all change fields are valid
 func (c *Change) Validate() error {
 	if c.ID == "" {
@@ -4,7 +4,9 @@
 //
 // Terminology (matching Polymarket's own naming):
 //   - Event: a Polymarket event page, which groups one or more related markets.
-//   - Market: a single yes/no question within an event. This is the unit we track.
+//   - Market: a single question within an event. This is the unit we track.
+//   - Outcome: one tradable answer to a market ("Yes"/"No", or named outcomes
+//     such as "Trump"/"Harris" or "Over"/"Under").
 package models
 
 import (
@@ -12,17 +14,22 @@ import (
 	"time"
 )
 
-// Market represents a single yes/no prediction market being monitored from Polymarket.
+// Market represents a single prediction market being monitored from Polymarket.
 // Each market belongs to a parent Polymarket event (identified by EventID).
 // Probability data, volume metrics, and metadata are used to detect significant moves.
 //
 // When a Polymarket event has multiple markets, each market is tracked independently
 // using a composite ID (EventID:MarketID), allowing per-market change detection.
+//
+// For markets whose outcomes are not named Yes/No, YesProbability holds the price
+// of the first outcome and NoProbability its complement; per-outcome prices are
+// in Outcomes and are snapshotted and scored individually.
 type Market struct {
 	ID             string    `json:"id"`              // Composite ID: "EventID:MarketID"
 	EventID        string    `json:"event_id"`        // Parent Polymarket event ID
 	MarketID       string    `json:"market_id"`       // Polymarket market ID
 	MarketQuestion string    `json:"market_question"` // Yes/no question for this market
+	ClobTokenID    string    `json:"clob_token_id"`   // CLOB token ID of the Yes outcome
 	Title          string    `json:"title"`           // Parent event title (from Polymarket API)
 	EventURL       string    `json:"event_url"`       // URL to the parent Polymarket event page
 	Description    string    `json:"description,omitempty"`
@@ -34,6 +41,13 @@ type Market struct {
 	Volume1wk      float64   `json:"volume_1wk"`      // 1-week volume in USD (market-level from API)
 	Volume1mo      float64   `json:"volume_1mo"`      // 1-month volume in USD (market-level from API)
 	Liquidity      float64   `json:"liquidity"`       // Current liquidity in USD (event-level)
+	BestBid        float64   `json:"best_bid"`        // CLOB best bid for the Yes token (0 = no book data)
+	BestAsk        float64   `json:"best_ask"`        // CLOB best ask for the Yes token (0 = no book data)
+	Spread         float64   `json:"spread"`          // BestAsk − BestBid (0 = no book data)
+	Midpoint       float64   `json:"midpoint"`        // (BestBid + BestAsk) / 2 (0 = no book data)
+	BidDepth       float64   `json:"bid_depth"`       // USD notional resting at the best bid
+	AskDepth       float64   `json:"ask_depth"`       // USD notional resting at the best ask
+	Outcomes       []Outcome `json:"outcomes,omitempty"`
 	Active         bool      `json:"active"`
 	Closed         bool      `json:"closed"`
 	LastUpdated    time.Time `json:"last_updated"`
@@ -77,6 +91,17 @@ func (m *Market) Validate() error {
 	if m.Liquidity < 0 {
 		return errors.New("liquidity must not be negative")
 	}
+	if err := validateBook(m.BestBid, m.BestAsk, m.Spread, m.BidDepth, m.AskDepth); err != nil {
+		return err
+	}
+	for _, o := range m.Outcomes {
+		if o.Name == "" {
+			return errors.New("outcome name must not be empty")
+		}
+		if o.Price < 0.0 || o.Price > 1.0 {
+			return errors.New("outcome price must be between 0.0 and 1.0")
+		}
+	}
 	if m.LastUpdated.After(time.Now()) {
 		return errors.New("last updated must not be in the future")
 	}
@@ -85,3 +110,21 @@ func (m *Market) Validate() error {
 	}
 	return nil
 }
+
+// validateBook checks order-book fields shared by Market and Snapshot.
+// All-zero values mean no CLOB data and are valid.
+func validateBook(bestBid, bestAsk, spread, bidDepth, askDepth float64) error {
+	if bestBid < 0.0 || bestBid > 1.0 {
+		return errors.New("best bid must be between 0.0 and 1.0")
+	}
+	if bestAsk < 0.0 || bestAsk > 1.0 {
+		return errors.New("best ask must be between 0.0 and 1.0")
+	}
+	if spread < 0.0 || spread > 1.0 {
+		return errors.New("spread must be between 0.0 and 1.0")
+	}
+	if bidDepth < 0 || askDepth < 0 {
+		return errors.New("book depth must not be negative")
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -224,3 +225,105 @@ func TestChangeValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestSubscription_Filter(t *testing.T) {
+	groups := []Event{
+		{ID: "1", Category: "crypto", URL: "https://polymarket.com/event/btc-100k", BestScore: 0.9},
+		{ID: "2", Category: "geopolitics", BestScore: 0.8},
+		{ID: "3", Category: "crypto", BestScore: 0.05},
+		{ID: "4", Category: "crypto", BestScore: 0.5},
+	}
+	ids := func(gs []Event) []string {
+		var out []string
+		for _, g := range gs {
+			out = append(out, g.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		sub  Subscription
+		want []string
+	}{
+		{"no filters", Subscription{ChatID: 1}, []string{"1", "2", "3", "4"}},
+		{"category", Subscription{ChatID: 1, Categories: []string{"crypto"}}, []string{"1", "3", "4"}},
+		{"min score", Subscription{ChatID: 1, Categories: []string{"crypto"}, MinScore: 0.1}, []string{"1", "4"}},
+		{"top k", Subscription{ChatID: 1, Categories: []string{"crypto"}, TopK: 2}, []string{"1", "3"}},
+		{"event by slug", Subscription{ChatID: 1, EventIDs: []string{"btc-100k"}}, []string{"1"}},
+		{"event by id", Subscription{ChatID: 1, EventIDs: []string{"2"}}, []string{"2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(tt.sub.Filter(groups))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchAndMute_Matches(t *testing.T) {
+	c := &Change{
+		EventID: "e1:m1", OriginalEventID: "e1", MarketID: "m1", Category: "crypto",
+		EventURL: "https://polymarket.com/event/btc-100k",
+	}
+	tests := []struct {
+		kind, target string
+		want         bool
+	}{
+		{TargetEvent, "e1", true},
+		{TargetEvent, "btc-100k", true},
+		{TargetEvent, "e2", false},
+		{TargetMarket, "m1", true},
+		{TargetMarket, "e1:m1", true},
+		{TargetMarket, "e1", false},
+		{TargetCategory, "Crypto", true},
+		{TargetCategory, "sports", false},
+	}
+	for _, tt := range tests {
+		m := &Mute{Kind: tt.kind, Target: tt.target}
+		if got := m.Matches(c); got != tt.want {
+			t.Errorf("mute %s %q matches = %v, want %v", tt.kind, tt.target, got, tt.want)
+		}
+	}
+	if w := (&Watch{Kind: TargetCategory, Target: "crypto"}); w.Validate() == nil {
+		t.Error("category watches should be rejected")
+	}
+	if w := (&Watch{Kind: TargetEvent, Target: "e1"}); w.Validate() != nil || !w.Matches(c) {
+		t.Error("expected a valid matching event watch")
+	}
+
+	now := time.Now()
+	if (&Mute{Until: now}).Active(now) || !(&Mute{Until: now.Add(time.Minute)}).Active(now) {
+		t.Error("mute should be active strictly before Until")
+	}
+}
+
+func TestMarket_TrackedOutcomes(t *testing.T) {
+	named := func(names ...string) *Market {
+		m := &Market{}
+		for i, n := range names {
+			m.Outcomes = append(m.Outcomes, Outcome{Index: i, Name: n})
+		}
+		return m
+	}
+	tests := []struct {
+		name   string
+		market *Market
+		want   []string
+	}{
+		{"no outcome data", &Market{}, []string{""}},
+		{"yes/no", named("Yes", "No"), []string{""}},
+		{"two named outcomes track only the first", named("Trump", "Harris"), []string{"Trump"}},
+		{"three named outcomes", named("Over", "Under", "Push"), []string{"Over", "Under", "Push"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.market.TrackedOutcomes()
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("TrackedOutcomes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
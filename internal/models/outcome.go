@@ -0,0 +1,65 @@
+package models
+
+// Outcome is one tradable outcome of a market, e.g. "Yes", "Trump" or "Over".
+// Outcomes are stored in their own table and index-aligned with Polymarket's
+// outcomes / outcomePrices / clobTokenIds arrays.
+type Outcome struct {
+	Index   int     `json:"index"`
+	Name    string  `json:"name"`
+	TokenID string  `json:"token_id"` // CLOB token ID ("" when unknown)
+	Price   float64 `json:"price"`    // Current price (probability) of this outcome
+}
+
+// IsYesNo reports whether the market is a plain Yes/No market. Markets without
+// outcome data are treated as Yes/No for backward compatibility.
+func (m *Market) IsYesNo() bool {
+	for _, o := range m.Outcomes {
+		if o.Name != "Yes" && o.Name != "No" {
+			return false
+		}
+	}
+	return true
+}
+
+// TrackedOutcomes returns the outcome series that are snapshotted and scored for
+// this market. Yes/No markets track a single unnamed series (the Yes
+// probability); other markets track their NamedSeries by name.
+func (m *Market) TrackedOutcomes() []string {
+	if m.IsYesNo() {
+		return []string{""}
+	}
+	series := m.NamedSeries()
+	names := make([]string, len(series))
+	for i, o := range series {
+		names[i] = o.Name
+	}
+	return names
+}
+
+// NamedSeries returns the outcomes of a named-outcome market that are tracked
+// as their own series. A two-outcome market (Trump/Harris, Up/Down) tracks
+// only its first outcome: the second is its complement, and tracking both
+// would report every move twice, mirrored. Yes/No markets have none.
+func (m *Market) NamedSeries() []Outcome {
+	if m.IsYesNo() {
+		return nil
+	}
+	if len(m.Outcomes) == 2 {
+		return m.Outcomes[:1]
+	}
+	return m.Outcomes
+}
+
+// OutcomePrice returns the current price of the named outcome. The unnamed
+// series of a Yes/No market is the Yes probability.
+func (m *Market) OutcomePrice(name string) (float64, bool) {
+	if name == "" {
+		return m.YesProbability, true
+	}
+	for _, o := range m.Outcomes {
+		if o.Name == name {
+			return o.Price, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Resolution records how a tracked market settled after it closed.
+type Resolution struct {
+	MarketID       string    `json:"market_id"`       // Composite market ID (EventID:MarketID)
+	EventID        string    `json:"event_id"`        // Parent Polymarket event ID
+	WinningOutcome string    `json:"winning_outcome"` // "Yes", "No" or a named outcome
+	ResolvedAt     time.Time `json:"resolved_at"`     // When the market closed
+	RecordedAt     time.Time `json:"recorded_at"`     // When we observed the resolution
+}
+
+// Validate checks that all resolution fields are valid
+func (r *Resolution) Validate() error {
+	if r.MarketID == "" {
+		return errors.New("market ID must not be empty")
+	}
+	if r.WinningOutcome == "" {
+		return errors.New("winning outcome must not be empty")
+	}
+	if r.ResolvedAt.IsZero() {
+		return errors.New("resolved at must be set")
+	}
+	return nil
+}
+
+// LedgerEntry pairs an alerted change with the final resolution of its market.
+type LedgerEntry struct {
+	Change     Change     `json:"change"`
+	Resolution Resolution `json:"resolution"`
+}
+
+// Correct reports whether the alert pointed the right way: an increase in an
+// outcome that went on to win, or a decrease in one that lost. The primary
+// series of a Yes/No market (Outcome "") refers to "Yes".
+func (e LedgerEntry) Correct() bool {
+	target := e.Change.Outcome
+	if target == "" {
+		target = "Yes"
+	}
+	won := e.Resolution.WinningOutcome == target
+	return won == (e.Change.Direction == "increase")
+}
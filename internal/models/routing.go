@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Kinds of target a watch or mute applies to.
+const (
+	TargetEvent    = "event"    // Polymarket event, by ID or URL slug
+	TargetMarket   = "market"   // Polymarket market, by market ID or composite "EventID:MarketID"
+	TargetCategory = "category" // every market of a category (mutes only)
+)
+
+// Watch follows an event or market at its own alert bar: its changes alert
+// when their signal score reaches Threshold, even below the global min score.
+type Watch struct {
+	Kind      string    `json:"kind"` // TargetEvent or TargetMarket
+	Target    string    `json:"target"`
+	Threshold float64   `json:"threshold"` // 0 = every scored change
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Mute silences an event, market or category until Until. Mutes win over
+// watches and the global bar.
+type Mute struct {
+	Kind      string    `json:"kind"` // TargetEvent, TargetMarket or TargetCategory
+	Target    string    `json:"target"`
+	Until     time.Time `json:"until"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate checks that all watch fields are valid.
+func (w *Watch) Validate() error {
+	if w.Kind != TargetEvent && w.Kind != TargetMarket {
+		return errors.New("watch kind must be 'event' or 'market'")
+	}
+	if w.Target == "" {
+		return errors.New("watch target must not be empty")
+	}
+	if w.Threshold < 0 {
+		return errors.New("watch threshold must not be negative")
+	}
+	return nil
+}
+
+// Matches reports whether the change belongs to the watched event or market.
+func (w *Watch) Matches(c *Change) bool {
+	return matchesTarget(w.Kind, w.Target, c)
+}
+
+// Validate checks that all mute fields are valid.
+func (m *Mute) Validate() error {
+	if m.Kind != TargetEvent && m.Kind != TargetMarket && m.Kind != TargetCategory {
+		return errors.New("mute kind must be 'event', 'market' or 'category'")
+	}
+	if m.Target == "" {
+		return errors.New("mute target must not be empty")
+	}
+	if m.Until.IsZero() {
+		return errors.New("mute end time must be set")
+	}
+	return nil
+}
+
+// Active reports whether the mute is still in effect at now.
+func (m *Mute) Active(now time.Time) bool {
+	return now.Before(m.Until)
+}
+
+// Matches reports whether the change belongs to the muted event, market or category.
+func (m *Mute) Matches(c *Change) bool {
+	return matchesTarget(m.Kind, m.Target, c)
+}
+
+func matchesTarget(kind, target string, c *Change) bool {
+	switch kind {
+	case TargetEvent:
+		return target == c.OriginalEventID || target == (&Event{URL: c.EventURL}).Slug()
+	case TargetMarket:
+		return target == c.MarketID || target == c.EventID
+	case TargetCategory:
+		return strings.EqualFold(target, c.Category)
+	}
+	return false
+}
@@ -9,13 +9,25 @@ import (
 // Snapshots are recorded periodically to track probability changes over time
 // and enable change detection within configurable time windows.
 //
+// For named-outcome markets there is one snapshot series per outcome: Outcome
+// holds the name, YesProbability the outcome's price and NoProbability its
+// complement.
+//
 // Each snapshot captures the Yes/No probabilities at a specific moment,
-// along with metadata about when it was recorded and the data source.
+// the CLOB top-of-book state when available, and metadata about when it was
+// recorded and the data source.
 type Snapshot struct {
 	ID             string    `json:"id"`
 	EventID        string    `json:"event_id"`
+	Outcome        string    `json:"outcome,omitempty"` // Outcome name; "" = Yes series of a Yes/No market
 	YesProbability float64   `json:"yes_probability"`
 	NoProbability  float64   `json:"no_probability"`
+	BestBid        float64   `json:"best_bid"`  // CLOB best bid at snapshot time (0 = no book data)
+	BestAsk        float64   `json:"best_ask"`  // CLOB best ask at snapshot time (0 = no book data)
+	Spread         float64   `json:"spread"`    // BestAsk − BestBid (0 = no book data)
+	Midpoint       float64   `json:"midpoint"`  // (BestBid + BestAsk) / 2 (0 = no book data)
+	BidDepth       float64   `json:"bid_depth"` // USD notional resting at the best bid
+	AskDepth       float64   `json:"ask_depth"` // USD notional resting at the best ask
 	Timestamp      time.Time `json:"timestamp"`
 	Source         string    `json:"source"` // Data source identifier (e.g., "polymarket-gamma-api")
 }
@@ -34,6 +46,9 @@ func (s *Snapshot) Validate() error {
 	if s.NoProbability < 0.0 || s.NoProbability > 1.0 {
 		return errors.New("no probability must be between 0.0 and 1.0")
 	}
+	if err := validateBook(s.BestBid, s.BestAsk, s.Spread, s.BidDepth, s.AskDepth); err != nil {
+		return err
+	}
 	if s.Timestamp.After(time.Now()) {
 		return errors.New("timestamp must not be in the future")
 	}
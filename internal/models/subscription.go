@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Subscription holds the alert filters of one Telegram chat (user or group).
+// Every filter narrows the alerts the chat receives; an empty filter does not
+// restrict. Alerts reaching a subscriber have already passed the global
+// quality bar, so MinScore can only raise it.
+type Subscription struct {
+	ChatID     int64     `json:"chat_id"`
+	Categories []string  `json:"categories,omitempty"` // only events in these categories
+	EventIDs   []string  `json:"event_ids,omitempty"`  // only these Polymarket events (IDs or URL slugs)
+	MinScore   float64   `json:"min_score"`            // minimum BestScore of an event group (0 = global bar)
+	TopK       int       `json:"top_k"`                // maximum event groups per alert (0 = all)
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Validate checks that all subscription fields are valid.
+func (s *Subscription) Validate() error {
+	if s.ChatID == 0 {
+		return errors.New("chat ID must not be zero")
+	}
+	if s.MinScore < 0 {
+		return errors.New("min score must not be negative")
+	}
+	if s.TopK < 0 {
+		return errors.New("top k must not be negative")
+	}
+	return nil
+}
+
+// Matches reports whether an event group passes every filter.
+func (s *Subscription) Matches(group Event) bool {
+	if len(s.Categories) > 0 && !slices.Contains(s.Categories, group.Category) {
+		return false
+	}
+	if len(s.EventIDs) > 0 && !slices.Contains(s.EventIDs, group.ID) && !slices.Contains(s.EventIDs, group.Slug()) {
+		return false
+	}
+	return group.BestScore >= s.MinScore
+}
+
+// Filter returns the groups matching the subscription, in order, truncated to TopK.
+func (s *Subscription) Filter(groups []Event) []Event {
+	var result []Event
+	for _, g := range groups {
+		if !s.Matches(g) {
+			continue
+		}
+		result = append(result, g)
+		if s.TopK > 0 && len(result) == s.TopK {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// AlertThread links a market to the Telegram message that first alerted on it
+// in a chat, so later moves can be posted as replies to that message instead
+// of as unrelated alerts.
+type AlertThread struct {
+	ChatID    int64     `json:"chat_id"`
+	Key       string    `json:"key"`        // Change.Key() of the market series
+	MessageID int       `json:"message_id"` // Telegram message replies attach to
+	Direction string    `json:"direction"`  // direction of the latest reported move
+	StartProb float64   `json:"start_prob"` // probability before the first alerted move
+	LastProb  float64   `json:"last_prob"`  // probability at the latest report
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"` // time of the latest report
+}
+
+// Validate checks that all thread fields are valid.
+func (t *AlertThread) Validate() error {
+	if t.ChatID == 0 {
+		return errors.New("chat ID must not be zero")
+	}
+	if t.Key == "" {
+		return errors.New("market key must not be empty")
+	}
+	if t.MessageID <= 0 {
+		return errors.New("message ID must be positive")
+	}
+	return nil
+}
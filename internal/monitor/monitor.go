@@ -10,20 +10,27 @@
 // Historical SNR measures how unusual this move is relative to the market's noise floor.
 // Trajectory consistency rewards clean directional moves over oscillating noise.
 //
+// When CLOB order-book data is available, the composite is further scaled by a
+// spread weight so that moves inside a wide bid/ask spread count for less.
+//
 // Use ScoreAndRank to apply quality filtering, group by event, and return the
 // top-K highest-signal event groups.
 package monitor
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rewired-gh/polyoracle/internal/logger"
 	"github.com/rewired-gh/polyoracle/internal/models"
 	"github.com/rewired-gh/polyoracle/internal/storage"
+	"github.com/rewired-gh/polyoracle/internal/stream"
 )
 
 // notifiedRecord tracks a previously sent notification for cooldown deduplication.
@@ -35,26 +42,30 @@ type notifiedRecord struct {
 
 // Monitor handles event monitoring and change detection
 type Monitor struct {
-	storage         *storage.Storage
-	notifiedMarkets map[string]notifiedRecord // key = composite event ID
+	storage *storage.Storage
+
+	// mu guards the cooldown state: the email digest records its alerts as
+	// notified from its own goroutine.
+	mu              sync.Mutex
+	notifiedMarkets map[string]notifiedRecord // key = Change.Key() (composite ID, plus outcome)
+	maxCooldown     time.Duration             // longest cooldown passed to FilterRecentlySent
 }
 
-// New creates a new Monitor instance
+// New creates a new Monitor instance. Cooldown state persisted by earlier runs
+// is loaded from storage so a restart does not re-alert recent moves.
 func New(s *storage.Storage) *Monitor {
-	return &Monitor{
+	m := &Monitor{
 		storage:         s,
 		notifiedMarkets: make(map[string]notifiedRecord),
 	}
-}
-
-// DetectionError represents a per-event error during change detection
-type DetectionError struct {
-	EventID string
-	Err     error
-}
-
-func (e DetectionError) Error() string {
-	return fmt.Sprintf("detection error for event %s: %v", e.EventID, e.Err)
+	records, err := s.LoadCooldowns()
+	if err != nil {
+		logger.Warn("Failed to load notification cooldowns: %v", err)
+	}
+	for _, r := range records {
+		m.notifiedMarkets[r.Key] = notifiedRecord{Direction: r.Direction, NewProb: r.NewProb, SentAt: r.SentAt}
+	}
+	return m
 }
 
 // minProbabilityChange is the hardcoded floor for change detection.
@@ -66,14 +77,27 @@ const probEpsilon = 1e-7
 
 // DetectChanges identifies probability changes within a time window that exceed the
 // minimum floor (0.1%). Scoring via ScoreAndRank is responsible for quality filtering.
-// Returns changes, per-event errors (non-fatal), and a fatal error if window is invalid.
-func (m *Monitor) DetectChanges(markets []models.Market, window time.Duration) ([]models.Change, []DetectionError, error) {
+// Named-outcome markets are checked once per outcome; the resulting Change carries
+// the outcome name. The move measured is the largest one within the window, so a
+// streamed spike that reverses between two polls is still reported.
+// Snapshots for all markets are loaded in a single storage query.
+// Returns an error if window is invalid or the snapshots cannot be loaded. Lines
+// are logged with the logger carried by ctx.
+func (m *Monitor) DetectChanges(ctx context.Context, markets []models.Market, window time.Duration) ([]models.Change, error) {
 	if window <= 0 {
-		return nil, nil, fmt.Errorf("invalid window %v: must be positive", window)
+		return nil, fmt.Errorf("invalid window %v: must be positive", window)
+	}
+
+	marketIDs := make([]string, len(markets))
+	for i := range markets {
+		marketIDs[i] = markets[i].ID
+	}
+	series, err := m.storage.GetSnapshotsInWindowForMarkets(marketIDs, window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshots: %w", err)
 	}
 
 	var changes []models.Change
-	var detectionErrors []DetectionError
 	now := time.Now()
 
 	eventsWithZeroSnapshots := 0
@@ -83,64 +107,88 @@ func (m *Monitor) DetectChanges(markets []models.Market, window time.Duration) (
 	maxChangeSeen := 0.0
 
 	for _, market := range markets {
-		snapshots, err := m.storage.GetSnapshotsInWindow(market.ID, window)
-		if err != nil {
-			detectionErrors = append(detectionErrors, DetectionError{EventID: market.ID, Err: err})
-			continue
-		}
+		for _, outcome := range market.TrackedOutcomes() {
+			snapshots := series[storage.SeriesKey{MarketID: market.ID, Outcome: outcome}]
 
-		if len(snapshots) == 0 {
-			eventsWithZeroSnapshots++
-			continue
-		}
-		if len(snapshots) == 1 {
-			eventsWithOneSnapshot++
-			continue
-		}
-
-		eventsWithEnoughSnapshots++
+			if len(snapshots) == 0 {
+				eventsWithZeroSnapshots++
+				continue
+			}
+			if len(snapshots) == 1 {
+				eventsWithOneSnapshot++
+				continue
+			}
 
-		oldest := snapshots[0]
-		current := snapshots[len(snapshots)-1]
+			eventsWithEnoughSnapshots++
 
-		change := math.Abs(current.YesProbability - oldest.YesProbability)
+			oldest, current := largestMove(snapshots)
 
-		if change > maxChangeSeen {
-			maxChangeSeen = change
-		}
+			change := math.Abs(current.YesProbability - oldest.YesProbability)
 
-		if change >= minProbabilityChange {
-			direction := "increase"
-			if current.YesProbability < oldest.YesProbability {
-				direction = "decrease"
+			if change > maxChangeSeen {
+				maxChangeSeen = change
 			}
 
-			changes = append(changes, models.Change{
-				ID:              uuid.New().String(),
-				EventID:         market.ID,
-				OriginalEventID: market.EventID,
-				EventTitle:      market.Title,
-				EventURL:        market.EventURL,
-				MarketID:        market.MarketID,
-				MarketQuestion:  market.MarketQuestion,
-				Magnitude:       change,
-				Direction:       direction,
-				OldProbability:  oldest.YesProbability,
-				NewProbability:  current.YesProbability,
-				TimeWindow:      window,
-				DetectedAt:      now,
-				Notified:        false,
-			})
-		} else if change > 0 {
-			eventsWithChangeBelowFloor++
+			if change >= minProbabilityChange {
+				direction := "increase"
+				if current.YesProbability < oldest.YesProbability {
+					direction = "decrease"
+				}
+
+				changes = append(changes, models.Change{
+					ID:              uuid.New().String(),
+					EventID:         market.ID,
+					OriginalEventID: market.EventID,
+					EventTitle:      market.Title,
+					EventURL:        market.EventURL,
+					MarketID:        market.MarketID,
+					MarketQuestion:  market.MarketQuestion,
+					Category:        market.Category,
+					Outcome:         outcome,
+					Magnitude:       change,
+					Direction:       direction,
+					OldProbability:  oldest.YesProbability,
+					NewProbability:  current.YesProbability,
+					TimeWindow:      window,
+					DetectedAt:      now,
+					Notified:        false,
+				})
+			} else if change > 0 {
+				eventsWithChangeBelowFloor++
+			}
 		}
 	}
 
 	// Debug logging for understanding detection behavior
-	logger.Debug("DetectChanges: 0 snapshots=%d, 1 snapshot=%d, >=2 snapshots=%d, below floor=%d, max_change=%.6f",
+	logger.FromContext(ctx).Debug("DetectChanges: 0 snapshots=%d, 1 snapshot=%d, >=2 snapshots=%d, below floor=%d, max_change=%.6f",
 		eventsWithZeroSnapshots, eventsWithOneSnapshot, eventsWithEnoughSnapshots, eventsWithChangeBelowFloor, maxChangeSeen)
 
-	return changes, detectionErrors, nil
+	return changes, nil
+}
+
+// largestMove returns the pair of snapshots (earlier, later) with the largest
+// absolute probability move in snaps, which must be sorted by timestamp
+// ascending and hold at least two entries. Ties keep the earliest pair, so a
+// monotonic series yields its two endpoints.
+func largestMove(snaps []models.Snapshot) (from, to models.Snapshot) {
+	from, to = snaps[0], snaps[len(snaps)-1]
+	best := math.Abs(to.YesProbability - from.YesProbability)
+	low, high := snaps[0], snaps[0]
+	for _, snap := range snaps[1:] {
+		if d := snap.YesProbability - low.YesProbability; d > best {
+			from, to, best = low, snap, d
+		}
+		if d := high.YesProbability - snap.YesProbability; d > best {
+			from, to, best = high, snap, d
+		}
+		if snap.YesProbability < low.YesProbability {
+			low = snap
+		}
+		if snap.YesProbability > high.YesProbability {
+			high = snap
+		}
+	}
+	return from, to
 }
 
 // KLDivergence computes KL(pNew || pOld) for a binary (YES/NO) distribution.
@@ -226,6 +274,20 @@ func TrajectoryConsistency(windowSnapshots []models.Snapshot) float64 {
 	return math.Abs(sumSigned) / sumAbs
 }
 
+// SpreadWeight returns magnitude / (magnitude + spread), floored at 0.1.
+// A move that is small relative to the bid/ask spread is mostly quote noise:
+// a 10pp move in a 20-cent book weighs 0.33, the same move in a 1-cent book 0.91.
+// Returns 1.0 when spread <= 0 (no order-book data).
+func SpreadWeight(magnitude, spread float64) float64 {
+	if spread <= 0 {
+		return 1.0
+	}
+	if magnitude <= 0 {
+		return 0.1
+	}
+	return math.Max(0.1, magnitude/(magnitude+spread))
+}
+
 // CompositeScore multiplies the four factors into a single signal quality scalar.
 func CompositeScore(kl, vw, snr, tc float64) float64 {
 	return kl * vw * snr * tc
@@ -245,10 +307,11 @@ func groupByEvent(changes []models.Change) []models.Event {
 		}
 		if _, exists := groupMap[id]; !exists {
 			groupMap[id] = &models.Event{
-				ID:      id,
-				Title:   change.EventTitle,
-				URL:     change.EventURL,
-				Markets: []models.Change{},
+				ID:       id,
+				Title:    change.EventTitle,
+				URL:      change.EventURL,
+				Category: change.Category,
+				Markets:  []models.Change{},
 			}
 			order = append(order, id)
 		}
@@ -270,19 +333,24 @@ func groupByEvent(changes []models.Change) []models.Event {
 	return result
 }
 
-// ScoreAndRank scores each change using the four-factor composite signal score,
-// filters out changes below minScore, groups them by original event ID, and
+// ScoreAndRank scores each change using the four-factor composite signal score
+// (scaled by SpreadWeight when the market has order-book data), filters out
+// changes below minScore, groups them by original event ID, and
 // returns at most k event groups sorted by BestScore descending. Ties are broken
 // by EventID lexicographic descending for determinism. Returns an empty (non-nil)
 // slice when nothing clears the quality bar.
 // vRef is the reference volume for log-volume weighting (typically volume_24hr_min
 // from config); markets at this volume receive weight ≈ 1.0.
 // minAbsChange is the minimum absolute probability change (fraction); changes below
-// this are discarded before scoring regardless of KL or volume.
+// this are discarded before scoring regardless of KL or volume, unless watched.
 // minBaseProb is the minimum base (old) probability; markets below this are in
 // the tail-probability zone where KL divergence is unreliable.
 // Pass 0.0 for either filter to disable it.
+//
+// ScoreAndRank is ScoreChanges followed by RankChanges; call those directly to
+// keep the scored changes that did not pass the threshold.
 func (m *Monitor) ScoreAndRank(
+	ctx context.Context,
 	changes []models.Change,
 	markets map[string]*models.Market,
 	minScore float64,
@@ -291,13 +359,39 @@ func (m *Monitor) ScoreAndRank(
 	minAbsChange float64,
 	minBaseProb float64,
 ) []models.Event {
+	return RankChanges(m.ScoreChanges(ctx, changes, markets, minScore, vRef, minAbsChange, minBaseProb), k)
+}
+
+// ScoreChanges applies the pre-score filters (see ScoreAndRank) and scores every
+// remaining change, setting SignalScore, PassedThreshold (score ≥ minScore) and
+// a Breakdown of the factors, thresholds and filters behind the score.
+// Changes of watched events and markets skip the pre-score filters, so their
+// watch threshold decides. Changes removed by the pre-score filters, or whose
+// market is missing from markets, are not returned. Returns a non-nil slice.
+// Lines are logged with the logger carried by ctx.
+func (m *Monitor) ScoreChanges(
+	ctx context.Context,
+	changes []models.Change,
+	markets map[string]*models.Market,
+	minScore float64,
+	vRef float64,
+	minAbsChange float64,
+	minBaseProb float64,
+) []models.Change {
+	log := logger.FromContext(ctx)
 	if vRef <= 0 {
 		vRef = 25000.0
 	}
+	watches, err := m.storage.ListWatches()
+	if err != nil {
+		log.Warn("ScoreChanges: failed to load watchlist, filtering watched markets too: %v", err)
+	}
 
 	var candidates []models.Change
-
 	for _, change := range changes {
+		breakdown := &models.ScoreBreakdown{MinScore: minScore, MinAbsChange: minAbsChange, MinBaseProb: minBaseProb}
+		watched := slices.ContainsFunc(watches, func(w models.Watch) bool { return w.Matches(&change) })
+
 		// Pre-score filter 1: minimum absolute probability change.
 		// KL divergence can be inflated for small absolute moves (especially at
 		// tail probabilities where log-ratios are large). Discard changes that
@@ -307,8 +401,15 @@ func (m *Monitor) ScoreAndRank(
 		// are always noteworthy regardless of move size.
 		entersConfirmation := (change.NewProbability > 0.95 && change.OldProbability <= 0.95) ||
 			(change.NewProbability < 0.05 && change.OldProbability >= 0.05)
-		if minAbsChange > 0 && change.Magnitude < minAbsChange && !entersConfirmation {
-			continue
+		if minAbsChange > 0 && change.Magnitude < minAbsChange {
+			switch {
+			case entersConfirmation:
+				breakdown.ConfirmationBypass = true
+			case watched:
+				breakdown.WatchBypass = true
+			default:
+				continue
+			}
 		}
 
 		// Pre-score filter 2: minimum base probability.
@@ -316,33 +417,96 @@ func (m *Monitor) ScoreAndRank(
 		// ratios blow up for tiny absolute moves. Also, stable tail markets have
 		// near-zero historical σ, so SNR clamps to 5.0 and amplifies the inflated KL.
 		if minBaseProb > 0 && change.OldProbability < minBaseProb {
-			continue
+			if !watched {
+				continue
+			}
+			breakdown.WatchBypass = true
 		}
 
-		market, ok := markets[change.EventID]
-		if !ok {
-			logger.Warn("ScoreAndRank: market %s not found in map, skipping", change.EventID)
+		if _, ok := markets[change.EventID]; !ok {
+			log.With(logger.MarketIDKey, change.EventID).Warn("ScoreChanges: market %s not found in map, skipping", change.EventID)
 			continue
 		}
+		change.Breakdown = breakdown
+		candidates = append(candidates, change)
+	}
 
-		allSnaps, err := m.storage.GetSnapshots(change.EventID)
-		snr := 1.0
-		if err == nil {
-			snr = HistoricalSNR(allSnaps, change.NewProbability-change.OldProbability)
+	// Load the history of every candidate series in one query; the window
+	// slice used for trajectory consistency is cut from it in memory.
+	marketIDs := make([]string, 0, len(candidates))
+	seen := make(map[string]bool, len(candidates))
+	for _, change := range candidates {
+		if !seen[change.EventID] {
+			seen[change.EventID] = true
+			marketIDs = append(marketIDs, change.EventID)
 		}
+	}
+	history, err := m.storage.GetSnapshotsForMarkets(marketIDs)
+	if err != nil {
+		log.Warn("ScoreChanges: failed to load snapshot history: %v", err)
+	}
+
+	now := time.Now()
+	scored := make([]models.Change, 0, len(candidates))
+	for _, change := range candidates {
+		market := markets[change.EventID]
 
-		winSnaps, err := m.storage.GetSnapshotsInWindow(change.EventID, change.TimeWindow)
-		tc := 1.0
+		snr, tc := 1.0, 1.0
 		if err == nil {
-			tc = TrajectoryConsistency(winSnaps)
+			allSnaps := polledSnapshots(history[storage.SeriesKey{MarketID: change.EventID, Outcome: change.Outcome}])
+			snr = HistoricalSNR(allSnaps, change.NewProbability-change.OldProbability)
+			tc = TrajectoryConsistency(snapshotsSince(allSnaps, now.Add(-change.TimeWindow)))
 		}
 
 		kl := KLDivergence(change.OldProbability, change.NewProbability)
 		vw := LogVolumeWeight(market.Volume24hr, vRef)
-		score := CompositeScore(kl, vw, snr, tc)
+		sw := SpreadWeight(change.Magnitude, market.Spread)
+		score := CompositeScore(kl, vw, snr, tc) * sw
+
+		b := change.Breakdown
+		b.KL, b.VolumeWeight, b.SNR, b.TrajectoryConsistency, b.SpreadWeight = kl, vw, snr, tc, sw
+		b.HistoryUnavailable = err != nil
 
 		change.SignalScore = score
-		if score >= minScore {
+		change.PassedThreshold = score >= minScore
+		scored = append(scored, change)
+	}
+
+	return scored
+}
+
+// polledSnapshots returns snaps without the ticks recorded by the CLOB stream.
+// σ and trajectory consistency assume one point per poll interval; the
+// finer-grained stream ticks would shrink σ and add oscillation to the
+// trajectory, so both are computed from the polled and backfilled series only.
+func polledSnapshots(snaps []models.Snapshot) []models.Snapshot {
+	var polled []models.Snapshot
+	for _, snap := range snaps {
+		if snap.Source != stream.Source {
+			polled = append(polled, snap)
+		}
+	}
+	return polled
+}
+
+// snapshotsSince returns the suffix of snaps (sorted by timestamp ascending)
+// taken at or after cutoff.
+func snapshotsSince(snaps []models.Snapshot, cutoff time.Time) []models.Snapshot {
+	i := sort.Search(len(snaps), func(i int) bool {
+		return !snaps[i].Timestamp.Before(cutoff)
+	})
+	return snaps[i:]
+}
+
+// RankChanges groups the changes that passed the threshold by original event ID
+// and returns the top k groups sorted by BestScore descending, ties broken by
+// EventID lexicographic descending. Groups holding a change promoted by a watch
+// (see ApplyRouting) are kept beyond the top k, after it in the same order.
+// Returns an empty (non-nil) slice when no change passed.
+func RankChanges(scored []models.Change, k int) []models.Event {
+	var candidates []models.Change
+	for _, change := range scored {
+		if change.PassedThreshold {
 			candidates = append(candidates, change)
 		}
 	}
@@ -357,13 +521,80 @@ func (m *Monitor) ScoreAndRank(
 		return groups[i].ID > groups[j].ID
 	})
 
-	if k <= 0 || len(groups) == 0 {
-		return []models.Event{}
+	ranked := []models.Event{}
+	for i, group := range groups {
+		if i < k || slices.ContainsFunc(group.Markets, promotedByWatch) {
+			ranked = append(ranked, group)
+		}
+	}
+	return ranked
+}
+
+// promotedByWatch reports whether a watch made the change pass the threshold.
+func promotedByWatch(change models.Change) bool {
+	return change.Breakdown != nil && change.Breakdown.Routing == models.RoutingWatch
+}
+
+// ApplyRouting applies the stored watchlist and mutes to scored changes in
+// place, before ranking: a change of a watched event or market passes when its
+// score reaches the watch threshold, even below the global bar, and a change
+// matching an active mute never passes. Mutes win over watches. The deciding
+// rule is recorded in the change's Breakdown. Expired mutes are deleted.
+// Returns how many changes were promoted and silenced; when the rules cannot
+// be loaded, scored is left unchanged. Lines are logged with the logger
+// carried by ctx.
+func (m *Monitor) ApplyRouting(ctx context.Context, scored []models.Change, now time.Time) (watched, muted int) {
+	log := logger.FromContext(ctx)
+	if _, err := m.storage.ExpireMutes(now); err != nil {
+		log.Warn("Failed to expire mutes: %v", err)
+	}
+	watches, err := m.storage.ListWatches()
+	if err != nil {
+		log.Warn("ApplyRouting: failed to load watchlist: %v", err)
+		return 0, 0
+	}
+	mutes, err := m.storage.ListMutes(now)
+	if err != nil {
+		log.Warn("ApplyRouting: failed to load mutes: %v", err)
+		return 0, 0
+	}
+	if len(watches) == 0 && len(mutes) == 0 {
+		return 0, 0
+	}
+
+	for i := range scored {
+		change := &scored[i]
+		if slices.ContainsFunc(mutes, func(mu models.Mute) bool { return mu.Matches(change) }) {
+			if change.PassedThreshold {
+				change.PassedThreshold = false
+				routed(change).Routing = models.RoutingMute
+				muted++
+			}
+			continue
+		}
+		if change.PassedThreshold {
+			continue
+		}
+		for _, w := range watches {
+			if w.Matches(change) && change.SignalScore >= w.Threshold {
+				change.PassedThreshold = true
+				b := routed(change)
+				b.Routing, b.WatchThreshold = models.RoutingWatch, w.Threshold
+				watched++
+				break
+			}
+		}
 	}
-	if k > len(groups) {
-		k = len(groups)
+	return watched, muted
+}
+
+// routed returns the change's breakdown for recording a routing decision,
+// creating it for changes that were not scored by ScoreChanges.
+func routed(change *models.Change) *models.ScoreBreakdown {
+	if change.Breakdown == nil {
+		change.Breakdown = &models.ScoreBreakdown{}
 	}
-	return groups[:k]
+	return change.Breakdown
 }
 
 // isDeterministicZone returns true when a probability is in the high-conviction
@@ -375,58 +606,106 @@ func isDeterministicZone(p float64) bool {
 // FilterRecentlySent removes markets from groups that were recently notified with
 // the same direction and are not entering the deterministic zone for the first time.
 // Groups that become empty after filtering are dropped. Returns a non-nil slice.
+// Cooldown records older than the longest cooldown seen so far are expired, in
+// memory and in storage.
 func (m *Monitor) FilterRecentlySent(groups []models.Event, cooldown time.Duration) []models.Event {
+	fresh, _ := m.SplitRecentlySent(groups, cooldown)
+	return fresh
+}
+
+// SplitRecentlySent is FilterRecentlySent that also returns the suppressed
+// markets, regrouped by event: moves continuing in the direction of an alert
+// sent within the cooldown. Notifiers can report them as follow-ups to that
+// alert. Both slices are non-nil.
+func (m *Monitor) SplitRecentlySent(groups []models.Event, cooldown time.Duration) (fresh, continuing []models.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	now := time.Now()
-	var result []models.Event
+	m.expireCooldowns(now, cooldown)
+	fresh, continuing = []models.Event{}, []models.Event{}
 
 	for _, group := range groups {
-		var filtered []models.Change
+		var filtered, suppressed []models.Change
 		for _, change := range group.Markets {
-			compositeID := change.EventID
-			rec, exists := m.notifiedMarkets[compositeID]
+			rec, exists := m.notifiedMarkets[change.Key()]
 			if exists && now.Sub(rec.SentAt) < cooldown {
 				// Recently sent — suppress unless direction changed or entering det zone
 				sameDirection := rec.Direction == change.Direction
 				enteringDetZone := isDeterministicZone(change.NewProbability) && !isDeterministicZone(rec.NewProb)
 				if sameDirection && !enteringDetZone {
+					suppressed = append(suppressed, change)
 					continue
 				}
 			}
 			filtered = append(filtered, change)
 		}
 
-		if len(filtered) == 0 {
-			continue
+		if len(filtered) > 0 {
+			fresh = append(fresh, withMarkets(group, filtered))
 		}
-
-		newGroup := group
-		newGroup.Markets = filtered
-		newGroup.BestScore = 0
-		for _, c := range filtered {
-			if c.SignalScore > newGroup.BestScore {
-				newGroup.BestScore = c.SignalScore
-			}
+		if len(suppressed) > 0 {
+			continuing = append(continuing, withMarkets(group, suppressed))
 		}
-		result = append(result, newGroup)
 	}
+	return fresh, continuing
+}
 
-	if result == nil {
-		return []models.Event{}
+// withMarkets returns a copy of group holding only markets, with BestScore recomputed.
+func withMarkets(group models.Event, markets []models.Change) models.Event {
+	group.Markets = markets
+	group.BestScore = 0
+	for _, c := range markets {
+		if c.SignalScore > group.BestScore {
+			group.BestScore = c.SignalScore
+		}
 	}
-	return result
+	return group
 }
 
 // RecordNotified records all markets in the given groups as notified at the current time.
-// Call this after a successful Telegram send to enable cooldown deduplication.
+// sendAlerts calls it for the groups that at least one notifier delivered, and the
+// email digest calls it through EmailConfig.Notified for the alerts it queued once
+// the digest is sent; both start the cooldown used for deduplication.
+// Records are persisted so they survive restarts. It is safe for concurrent use.
 func (m *Monitor) RecordNotified(groups []models.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	now := time.Now()
+	var records []storage.CooldownRecord
 	for _, group := range groups {
 		for _, change := range group.Markets {
-			m.notifiedMarkets[change.EventID] = notifiedRecord{
+			m.notifiedMarkets[change.Key()] = notifiedRecord{
 				Direction: change.Direction,
 				NewProb:   change.NewProbability,
 				SentAt:    now,
 			}
+			records = append(records, storage.CooldownRecord{
+				Key:       change.Key(),
+				Direction: change.Direction,
+				NewProb:   change.NewProbability,
+				SentAt:    now,
+			})
 		}
 	}
+	if err := m.storage.SaveCooldowns(records); err != nil {
+		logger.Warn("Failed to persist notification cooldowns: %v", err)
+	}
+}
+
+// expireCooldowns drops records that can no longer suppress anything: those
+// older than the longest cooldown FilterRecentlySent has been called with. The
+// caller must hold m.mu.
+func (m *Monitor) expireCooldowns(now time.Time, cooldown time.Duration) {
+	if cooldown > m.maxCooldown {
+		m.maxCooldown = cooldown
+	}
+	cutoff := now.Add(-m.maxCooldown)
+	for key, rec := range m.notifiedMarkets {
+		if rec.SentAt.Before(cutoff) {
+			delete(m.notifiedMarkets, key)
+		}
+	}
+	if _, err := m.storage.ExpireCooldowns(cutoff); err != nil {
+		logger.Warn("Failed to expire notification cooldowns: %v", err)
+	}
 }
@@ -1,13 +1,16 @@
 package monitor
 
 import (
+	"context"
 	"math"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rewired-gh/polyoracle/internal/models"
 	"github.com/rewired-gh/polyoracle/internal/storage"
+	"github.com/rewired-gh/polyoracle/internal/stream"
 )
 
 func mustStorage(t *testing.T, maxMarkets, maxSnaps int) *storage.Storage {
@@ -68,7 +71,7 @@ func TestDetectChanges(t *testing.T) {
 	}
 
 	markets := []models.Market{market}
-	changes, _, err := m.DetectChanges(markets, 2*time.Hour)
+	changes, err := m.DetectChanges(context.Background(), markets, 2*time.Hour)
 	if err != nil {
 		t.Fatalf("DetectChanges failed: %v", err)
 	}
@@ -132,7 +135,7 @@ func TestDetectChanges_BelowThreshold(t *testing.T) {
 	}
 
 	markets := []models.Market{market}
-	changes, _, err := m.DetectChanges(markets, 2*time.Hour)
+	changes, err := m.DetectChanges(context.Background(), markets, 2*time.Hour)
 	if err != nil {
 		t.Fatalf("DetectChanges failed: %v", err)
 	}
@@ -196,7 +199,7 @@ func TestDetectChanges_OutOfOrderSnapshots(t *testing.T) {
 	}
 
 	markets := []models.Market{market}
-	changes, _, err := m.DetectChanges(markets, 3*time.Hour)
+	changes, err := m.DetectChanges(context.Background(), markets, 3*time.Hour)
 	if err != nil {
 		t.Fatalf("DetectChanges failed: %v", err)
 	}
@@ -219,6 +222,55 @@ func TestDetectChanges_OutOfOrderSnapshots(t *testing.T) {
 	}
 }
 
+func TestDetectChanges_StreamedSpikeBetweenPolls(t *testing.T) {
+	s := mustStorage(t, 100, 50)
+	m := New(s)
+
+	now := time.Now()
+	market := models.Market{ID: "event-1:market-1", EventID: "event-1", MarketID: "market-1", Title: "Spike?", Category: "politics", YesProbability: 0.50, NoProbability: 0.50, Active: true}
+	if err := s.AddMarket(&market); err != nil {
+		t.Fatalf("Failed to add market: %v", err)
+	}
+
+	// Both polls read 0.50; the stream saw a spike to 0.62 that reversed in between.
+	ticks := []struct {
+		offset time.Duration
+		prob   float64
+		source string
+	}{
+		{-30 * time.Minute, 0.50, "polymarket-gamma-api"},
+		{-20 * time.Minute, 0.50, stream.Source},
+		{-15 * time.Minute, 0.62, stream.Source},
+		{-10 * time.Minute, 0.51, stream.Source},
+		{0, 0.50, "polymarket-gamma-api"},
+	}
+	for _, tick := range ticks {
+		snap := models.Snapshot{
+			ID:             uuid.New().String(),
+			EventID:        market.ID,
+			YesProbability: tick.prob,
+			NoProbability:  1 - tick.prob,
+			Timestamp:      now.Add(tick.offset),
+			Source:         tick.source,
+		}
+		if err := s.AddSnapshot(&snap); err != nil {
+			t.Fatalf("Failed to add snapshot: %v", err)
+		}
+	}
+
+	changes, err := m.DetectChanges(context.Background(), []models.Market{market}, time.Hour)
+	if err != nil {
+		t.Fatalf("DetectChanges failed: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Expected the reversed spike to be detected, got %d changes", len(changes))
+	}
+	c := changes[0]
+	if math.Abs(c.Magnitude-0.12) > 1e-9 || c.Direction != "increase" || c.OldProbability != 0.50 || c.NewProbability != 0.62 {
+		t.Errorf("Expected the 0.50→0.62 spike, got %+v", c)
+	}
+}
+
 // ─── T011: TestKLDivergence ───────────────────────────────────────────────────
 
 func TestKLDivergence(t *testing.T) {
@@ -339,6 +391,54 @@ func TestLogVolumeWeight(t *testing.T) {
 	}
 }
 
+func TestSpreadWeight(t *testing.T) {
+	tests := []struct {
+		name              string
+		magnitude, spread float64
+		wantMin, wantMax  float64
+	}{
+		{name: "no book data → 1.0", magnitude: 0.10, spread: 0, wantMin: 1.0, wantMax: 1.0},
+		{name: "tight 1c book", magnitude: 0.10, spread: 0.01, wantMin: 0.90, wantMax: 0.92},
+		{name: "wide 20c book", magnitude: 0.10, spread: 0.20, wantMin: 0.33, wantMax: 0.34},
+		{name: "zero move floors at 0.1", magnitude: 0, spread: 0.05, wantMin: 0.1, wantMax: 0.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SpreadWeight(tt.magnitude, tt.spread)
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("SpreadWeight(%v, %v) = %v, want [%v, %v]", tt.magnitude, tt.spread, got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+// TestScoreAndRank_WideSpreadScoresLower verifies that the same move ranks lower
+// in a market with a wide bid/ask spread than in a tight book.
+func TestScoreAndRank_WideSpreadScoresLower(t *testing.T) {
+	store := mustStorage(t, 100, 50)
+	mon := New(store)
+
+	markets := map[string]*models.Market{
+		"tight": {ID: "tight", EventID: "tight", Volume24hr: 100_000, Spread: 0.01, Title: "Tight", Category: "test"},
+		"wide":  {ID: "wide", EventID: "wide", Volume24hr: 100_000, Spread: 0.20, Title: "Wide", Category: "test"},
+	}
+	changes := []models.Change{
+		{ID: "c1", EventID: "tight", OldProbability: 0.41, NewProbability: 0.51, Magnitude: 0.10, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
+		{ID: "c2", EventID: "wide", OldProbability: 0.41, NewProbability: 0.51, Magnitude: 0.10, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
+	}
+
+	result := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 10, 25000.0, 0.0, 0.0)
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(result))
+	}
+	if result[0].ID != "tight" {
+		t.Errorf("Expected tight-spread market ranked first, got %s", result[0].ID)
+	}
+	if result[1].BestScore >= result[0].BestScore {
+		t.Errorf("Expected wide-spread score %.6f < tight-spread score %.6f", result[1].BestScore, result[0].BestScore)
+	}
+}
+
 // ─── T013: TestHistoricalSNR ──────────────────────────────────────────────────
 
 func makeSnaps(probs []float64) []models.Snapshot {
@@ -588,8 +688,8 @@ func TestScoring(t *testing.T) {
 			buildChange("evt-c", 0.60, 0.75, time.Hour),
 		}
 
-		result1 := mon.ScoreAndRank(changes, markets, 0.0, 10, 25000.0, 0.0, 0.0)
-		result2 := mon.ScoreAndRank(changes, markets, 0.0, 10, 25000.0, 0.0, 0.0)
+		result1 := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 10, 25000.0, 0.0, 0.0)
+		result2 := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 10, 25000.0, 0.0, 0.0)
 
 		if len(result1) != len(result2) {
 			t.Fatalf("Determinism: different lengths %d vs %d", len(result1), len(result2))
@@ -621,7 +721,7 @@ func TestScoreAndRank_TopKLimit(t *testing.T) {
 		{ID: "c3", EventID: "e3", OldProbability: 0.50, NewProbability: 0.60, Magnitude: 0.10, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
 	}
 
-	top := mon.ScoreAndRank(changes, markets, 0.0, 2, 25000.0, 0.0, 0.0)
+	top := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 2, 25000.0, 0.0, 0.0)
 	if len(top) != 2 {
 		t.Errorf("Expected 2 results (k=2), got %d", len(top))
 	}
@@ -631,7 +731,7 @@ func TestScoreAndRank_NeverNil(t *testing.T) {
 	store := mustStorage(t, 100, 50)
 	mon := New(store)
 
-	result := mon.ScoreAndRank(nil, map[string]*models.Market{}, 0.0, 5, 25000.0, 0.0, 0.0)
+	result := mon.ScoreAndRank(context.Background(), nil, map[string]*models.Market{}, 0.0, 5, 25000.0, 0.0, 0.0)
 	if result == nil {
 		t.Error("ScoreAndRank should never return nil, got nil")
 	}
@@ -649,12 +749,42 @@ func TestScoreAndRank_MinScoreFilters(t *testing.T) {
 	}
 
 	// With very high minScore, nothing should pass
-	result := mon.ScoreAndRank(changes, markets, 999.0, 5, 25000.0, 0.0, 0.0)
+	result := mon.ScoreAndRank(context.Background(), changes, markets, 999.0, 5, 25000.0, 0.0, 0.0)
 	if len(result) != 0 {
 		t.Errorf("Expected 0 results with minScore=999, got %d", len(result))
 	}
 }
 
+func TestScoreChanges_KeepsChangesBelowThreshold(t *testing.T) {
+	store := mustStorage(t, 100, 50)
+	mon := New(store)
+
+	markets := map[string]*models.Market{
+		"e1": {ID: "e1", EventID: "e1", Volume24hr: 100_000, Title: "Test", Category: "test"},
+	}
+	changes := []models.Change{
+		{ID: "small", EventID: "e1", OldProbability: 0.50, NewProbability: 0.51, Magnitude: 0.01, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
+		{ID: "large", EventID: "e1", OldProbability: 0.30, NewProbability: 0.70, Magnitude: 0.40, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
+		{ID: "filtered", EventID: "e1", OldProbability: 0.50, NewProbability: 0.505, Magnitude: 0.005, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
+	}
+
+	scored := mon.ScoreChanges(context.Background(), changes, markets, 0.05, 25000.0, 0.01, 0.0)
+	if len(scored) != 2 {
+		t.Fatalf("Expected pre-filtered change to be dropped and 2 scored, got %d", len(scored))
+	}
+	if scored[0].PassedThreshold || scored[0].SignalScore <= 0 {
+		t.Errorf("Expected small change scored but below threshold, got %+v", scored[0])
+	}
+	if !scored[1].PassedThreshold {
+		t.Errorf("Expected large change to pass threshold, got score %f", scored[1].SignalScore)
+	}
+
+	groups := RankChanges(scored, 5)
+	if len(groups) != 1 || len(groups[0].Markets) != 1 || groups[0].Markets[0].ID != "large" {
+		t.Errorf("Expected only the passing change to be ranked, got %+v", groups)
+	}
+}
+
 func TestScoreAndRank_TopKZero(t *testing.T) {
 	store := mustStorage(t, 100, 50)
 	mon := New(store)
@@ -666,7 +796,7 @@ func TestScoreAndRank_TopKZero(t *testing.T) {
 		{ID: "c1", EventID: "e1", OldProbability: 0.50, NewProbability: 0.70, Magnitude: 0.20, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
 	}
 
-	result := mon.ScoreAndRank(changes, markets, 0.0, 0, 25000.0, 0.0, 0.0)
+	result := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 0, 25000.0, 0.0, 0.0)
 	if len(result) != 0 {
 		t.Errorf("Expected 0 results when k=0, got %d", len(result))
 	}
@@ -697,7 +827,7 @@ func TestScoreAndRank_PreScoreFilters(t *testing.T) {
 	const minAbsChange = 0.03 // 3pp
 	const minBaseProb = 0.05  // 5%
 
-	result := mon.ScoreAndRank(changes, markets, 0.0, 10, 25000.0, minAbsChange, minBaseProb)
+	result := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 10, 25000.0, minAbsChange, minBaseProb)
 
 	passedIDs := make(map[string]bool)
 	for _, g := range result {
@@ -746,7 +876,7 @@ func TestScoreAndRank_ConfirmationEntry_BypassesMinAbsChange(t *testing.T) {
 		{ID: "c5", EventID: "normal-small", OldProbability: 0.40, NewProbability: 0.43, Magnitude: 0.03, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
 	}
 
-	result := mon.ScoreAndRank(changes, markets, 0.0, 10, 25000.0, minAbsChange, minBaseProb)
+	result := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 10, 25000.0, minAbsChange, minBaseProb)
 
 	passedIDs := make(map[string]bool)
 	for _, g := range result {
@@ -1026,7 +1156,7 @@ func TestScenario_NoisySignalImportantEventFiltered(t *testing.T) {
 		cleanMarketID:  cleanMkt,
 	}
 
-	results := mon.ScoreAndRank(changes, marketsMap, minScore, 5, vRef, 0.0, 0.0)
+	results := mon.ScoreAndRank(context.Background(), changes, marketsMap, minScore, 5, vRef, 0.0, 0.0)
 
 	cleanPassed := false
 	for _, r := range results {
@@ -1140,7 +1270,7 @@ func TestScenario_SignificantSignalUnimportantEventFiltered(t *testing.T) {
 		highVolID: highVolMkt,
 	}
 
-	results := mon.ScoreAndRank(changes, marketsMap, minScore, 5, vRef, 0.0, 0.0)
+	results := mon.ScoreAndRank(context.Background(), changes, marketsMap, minScore, 5, vRef, 0.0, 0.0)
 
 	highVolPassed := false
 	for _, r := range results {
@@ -1203,7 +1333,7 @@ func TestScoreAndRank_GroupsByOriginalEventID(t *testing.T) {
 		{ID: "c3", EventID: "eth:flip", OriginalEventID: "eth", OldProbability: 0.40, NewProbability: 0.60, Magnitude: 0.20, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
 	}
 
-	groups := mon.ScoreAndRank(changes, markets, 0.0, 10, 25000.0, 0.0, 0.0)
+	groups := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 10, 25000.0, 0.0, 0.0)
 
 	if len(groups) != 2 {
 		t.Errorf("Expected 2 groups (btc, eth), got %d", len(groups))
@@ -1244,7 +1374,7 @@ func TestScoreAndRank_TopKAtGroupLevel(t *testing.T) {
 		{ID: "c4", EventID: "btc", OriginalEventID: "btc", OldProbability: 0.50, NewProbability: 0.55, Magnitude: 0.05, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
 	}
 
-	groups := mon.ScoreAndRank(changes, markets, 0.0, 2, 25000.0, 0.0, 0.0)
+	groups := mon.ScoreAndRank(context.Background(), changes, markets, 0.0, 2, 25000.0, 0.0, 0.0)
 	if len(groups) != 2 {
 		t.Errorf("Expected 2 groups (k=2), got %d", len(groups))
 	}
@@ -1375,3 +1505,346 @@ func TestFilterRecentlySent_PassesAfterCooldown(t *testing.T) {
 		t.Errorf("Expected 1 group after cooldown expired, got %d", len(filtered))
 	}
 }
+
+func TestDetectChanges_PerOutcome(t *testing.T) {
+	s := mustStorage(t, 100, 50)
+	m := New(s)
+
+	now := time.Now()
+	market := models.Market{
+		ID:             "event-1:market-1",
+		EventID:        "event-1",
+		MarketID:       "market-1",
+		Title:          "Who will win?",
+		Category:       "politics",
+		YesProbability: 0.60,
+		NoProbability:  0.40,
+		Outcomes: []models.Outcome{
+			{Index: 0, Name: "Trump", Price: 0.60},
+			{Index: 1, Name: "Harris", Price: 0.30},
+			{Index: 2, Name: "Other", Price: 0.10},
+		},
+		Active:      true,
+		LastUpdated: now,
+		CreatedAt:   now.Add(-time.Hour),
+	}
+	if err := s.AddMarket(&market); err != nil {
+		t.Fatalf("Failed to add market: %v", err)
+	}
+
+	series := map[string][2]float64{"Trump": {0.45, 0.60}, "Harris": {0.45, 0.30}, "Other": {0.10, 0.10}}
+	for outcome, probs := range series {
+		for i, p := range probs {
+			snap := models.Snapshot{
+				ID:             uuid.New().String(),
+				EventID:        market.ID,
+				Outcome:        outcome,
+				YesProbability: p,
+				NoProbability:  1 - p,
+				Timestamp:      now.Add(time.Duration(i-1) * time.Hour),
+				Source:         "test",
+			}
+			if err := s.AddSnapshot(&snap); err != nil {
+				t.Fatalf("Failed to add snapshot: %v", err)
+			}
+		}
+	}
+
+	changes, err := m.DetectChanges(context.Background(), []models.Market{market}, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("DetectChanges failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected changes for Trump and Harris only, got %d", len(changes))
+	}
+	byOutcome := make(map[string]models.Change)
+	for _, c := range changes {
+		byOutcome[c.Outcome] = c
+	}
+	if c := byOutcome["Trump"]; c.Direction != "increase" || c.NewProbability != 0.60 {
+		t.Errorf("Unexpected Trump change: %+v", c)
+	}
+	if c := byOutcome["Harris"]; c.Direction != "decrease" || c.OldProbability != 0.45 {
+		t.Errorf("Unexpected Harris change: %+v", c)
+	}
+
+	markets := map[string]*models.Market{market.ID: &market}
+	groups := m.ScoreAndRank(context.Background(), changes, markets, 0, 5, 25000, 0, 0)
+	if len(groups) != 1 || len(groups[0].Markets) != 2 {
+		t.Fatalf("Expected both outcomes scored in one group, got %+v", groups)
+	}
+
+	// Cooldown is tracked per outcome
+	m.RecordNotified([]models.Event{{ID: "event-1", Markets: []models.Change{byOutcome["Trump"]}}})
+	filtered := m.FilterRecentlySent(groups, time.Hour)
+	if len(filtered) != 1 || len(filtered[0].Markets) != 1 || filtered[0].Markets[0].Outcome != "Harris" {
+		t.Errorf("Expected only the Harris change to pass cooldown, got %+v", filtered)
+	}
+}
+
+// TestFilterRecentlySent_SurvivesRestart verifies that cooldown state is loaded
+// from storage by a new Monitor, so a restart does not re-alert the same move.
+func TestFilterRecentlySent_SurvivesRestart(t *testing.T) {
+	store := mustStorage(t, 100, 50)
+
+	change := models.Change{
+		ID:             uuid.New().String(),
+		EventID:        "evt-1",
+		Outcome:        "Trump",
+		OldProbability: 0.50,
+		NewProbability: 0.60,
+		Magnitude:      0.10,
+		Direction:      "increase",
+		TimeWindow:     time.Hour,
+		DetectedAt:     time.Now(),
+	}
+	group := models.Event{ID: "evt-1", Markets: []models.Change{change}}
+	New(store).RecordNotified([]models.Event{group})
+
+	restarted := New(store)
+	if filtered := restarted.FilterRecentlySent([]models.Event{group}, time.Hour); len(filtered) != 0 {
+		t.Errorf("Expected duplicate to be suppressed after restart, got %d groups", len(filtered))
+	}
+}
+
+// TestFilterRecentlySent_ExpiresOldRecords verifies that records older than the
+// longest cooldown are removed from storage.
+func TestFilterRecentlySent_ExpiresOldRecords(t *testing.T) {
+	store := mustStorage(t, 100, 50)
+	old := time.Now().Add(-3 * time.Hour)
+	if err := store.SaveCooldowns([]storage.CooldownRecord{
+		{Key: "stale", Direction: "increase", NewProb: 0.6, SentAt: old},
+		{Key: "fresh", Direction: "increase", NewProb: 0.6, SentAt: time.Now()},
+	}); err != nil {
+		t.Fatalf("SaveCooldowns: %v", err)
+	}
+
+	mon := New(store)
+	mon.FilterRecentlySent(nil, 2*time.Hour)
+	mon.FilterRecentlySent(nil, time.Hour) // a shorter cooldown must not expire "fresh" early
+
+	records, err := store.LoadCooldowns()
+	if err != nil {
+		t.Fatalf("LoadCooldowns: %v", err)
+	}
+	if len(records) != 1 || records[0].Key != "fresh" {
+		t.Errorf("Expected only the fresh record to remain, got %+v", records)
+	}
+	if _, ok := mon.notifiedMarkets["stale"]; ok {
+		t.Error("Expected stale record to be expired in memory")
+	}
+}
+
+func TestApplyRouting(t *testing.T) {
+	store := mustStorage(t, 100, 50)
+	mon := New(store)
+	now := time.Now()
+
+	if err := store.SaveWatch(&models.Watch{Kind: models.TargetMarket, Target: "m-watched", Threshold: 0.01}); err != nil {
+		t.Fatalf("SaveWatch: %v", err)
+	}
+	if err := store.SaveWatch(&models.Watch{Kind: models.TargetEvent, Target: "e-both"}); err != nil {
+		t.Fatalf("SaveWatch: %v", err)
+	}
+	for _, m := range []*models.Mute{
+		{Kind: models.TargetCategory, Target: "sports", Until: now.Add(time.Hour)},
+		{Kind: models.TargetEvent, Target: "e-both", Until: now.Add(time.Hour)},
+		{Kind: models.TargetCategory, Target: "crypto", Until: now.Add(-time.Minute)}, // expired
+	} {
+		if err := store.SaveMute(m); err != nil {
+			t.Fatalf("SaveMute: %v", err)
+		}
+	}
+
+	scored := []models.Change{
+		{ID: "watched-low", MarketID: "m-watched", SignalScore: 0.02},
+		{ID: "watched-too-low", MarketID: "m-watched", SignalScore: 0.005},
+		{ID: "muted", Category: "sports", SignalScore: 0.5, PassedThreshold: true},
+		{ID: "watched-and-muted", OriginalEventID: "e-both", SignalScore: 0.5},
+		{ID: "expired-mute", Category: "crypto", SignalScore: 0.5, PassedThreshold: true},
+		{ID: "unrelated", SignalScore: 0.001},
+	}
+	watched, muted := mon.ApplyRouting(context.Background(), scored, now)
+	if watched != 1 || muted != 1 {
+		t.Errorf("ApplyRouting = %d watched, %d muted; want 1, 1", watched, muted)
+	}
+	if b := scored[0].Breakdown; b == nil || b.Routing != models.RoutingWatch || b.WatchThreshold != 0.01 {
+		t.Errorf("watch promotion not recorded: %+v", b)
+	}
+	if b := scored[2].Breakdown; b == nil || b.Routing != models.RoutingMute {
+		t.Errorf("mute not recorded: %+v", b)
+	}
+	want := map[string]bool{
+		"watched-low": true, "watched-too-low": false, "muted": false,
+		"watched-and-muted": false, "expired-mute": true, "unrelated": false,
+	}
+	for _, c := range scored {
+		if c.PassedThreshold != want[c.ID] {
+			t.Errorf("%s: PassedThreshold = %v, want %v", c.ID, c.PassedThreshold, want[c.ID])
+		}
+	}
+	if mutes, _ := store.ListMutes(now.Add(-time.Hour)); len(mutes) != 2 {
+		t.Errorf("expired mute should have been deleted, got %+v", mutes)
+	}
+}
+
+func TestScoreChanges_RecordsBreakdown(t *testing.T) {
+	store := mustStorage(t, 100, 50)
+	mon := New(store)
+
+	markets := map[string]*models.Market{
+		"mid":   {ID: "mid", EventID: "mid", Volume24hr: 100_000, Spread: 0.02, Title: "Mid", Category: "geopolitics"},
+		"enter": {ID: "enter", EventID: "enter", Volume24hr: 100_000, Title: "Enter", Category: "geopolitics"},
+	}
+	changes := []models.Change{
+		{ID: "c1", EventID: "mid", OldProbability: 0.40, NewProbability: 0.55, Magnitude: 0.15, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
+		{ID: "c2", EventID: "enter", OldProbability: 0.94, NewProbability: 0.96, Magnitude: 0.02, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()},
+	}
+	scored := mon.ScoreChanges(context.Background(), changes, markets, 0.01, 25000, 0.05, 0.05)
+	if len(scored) != 2 {
+		t.Fatalf("expected 2 scored changes, got %d", len(scored))
+	}
+
+	b := scored[0].Breakdown
+	if b == nil {
+		t.Fatal("expected a score breakdown")
+	}
+	product := b.KL * b.VolumeWeight * b.SNR * b.TrajectoryConsistency * b.SpreadWeight
+	if math.Abs(product-scored[0].SignalScore) > 1e-12 {
+		t.Errorf("factors multiply to %v, score is %v", product, scored[0].SignalScore)
+	}
+	if b.KL != KLDivergence(0.40, 0.55) || b.VolumeWeight != LogVolumeWeight(100_000, 25000) || b.SpreadWeight != SpreadWeight(0.15, 0.02) {
+		t.Errorf("unexpected factors: %+v", b)
+	}
+	if b.MinScore != 0.01 || b.MinAbsChange != 0.05 || b.MinBaseProb != 0.05 || b.ConfirmationBypass {
+		t.Errorf("unexpected thresholds: %+v", b)
+	}
+	if !scored[1].Breakdown.ConfirmationBypass {
+		t.Error("94%→96% should record the confirmation-zone bypass of min_abs_change")
+	}
+}
+
+func TestScoreChanges_IgnoresStreamedTicksInHistory(t *testing.T) {
+	store := mustStorage(t, 100, 200)
+	mon := New(store)
+
+	now := time.Now()
+	const id = "e1:m1"
+	market := &models.Market{ID: id, EventID: "e1", MarketID: "m1", Title: "Test?", Category: "politics", YesProbability: 0.55, NoProbability: 0.45, Volume24hr: 100_000, Active: true}
+	if err := store.AddMarket(market); err != nil {
+		t.Fatalf("Failed to add market: %v", err)
+	}
+	markets := map[string]*models.Market{id: market}
+	add := func(offset time.Duration, prob float64, source string) {
+		t.Helper()
+		snap := models.Snapshot{ID: uuid.New().String(), EventID: id, YesProbability: prob, NoProbability: 1 - prob,
+			Timestamp: now.Add(offset), Source: source}
+		if err := store.AddSnapshot(&snap); err != nil {
+			t.Fatalf("Failed to add snapshot: %v", err)
+		}
+	}
+	polls := []float64{0.40, 0.41, 0.40, 0.42, 0.41, 0.44, 0.48, 0.52, 0.55}
+	for i, p := range polls {
+		add(time.Duration(i-len(polls)+1)*15*time.Minute, p, "polymarket-gamma-api")
+	}
+	change := models.Change{ID: "c1", EventID: id, MarketID: "m1", OldProbability: 0.44, NewProbability: 0.55,
+		Magnitude: 0.11, Direction: "increase", TimeWindow: time.Hour, DetectedAt: now}
+
+	score := func() float64 {
+		t.Helper()
+		scored := mon.ScoreChanges(context.Background(), []models.Change{change}, markets, 0, 25000, 0, 0)
+		if len(scored) != 1 {
+			t.Fatalf("expected 1 scored change, got %d", len(scored))
+		}
+		return scored[0].SignalScore
+	}
+	before := score()
+
+	// Minute ticks that jitter around each poll would shrink σ and lower TC
+	// if they were mixed into the poll-interval series.
+	for i := range polls[:len(polls)-1] {
+		base := time.Duration(i-len(polls)+1) * 15 * time.Minute
+		for k := 1; k < 15; k++ {
+			add(base+time.Duration(k)*time.Minute, polls[i]+0.005*float64(k%2), stream.Source)
+		}
+	}
+	if after := score(); after != before {
+		t.Errorf("streamed ticks changed the score: %v → %v", before, after)
+	}
+}
+
+func TestScoreChanges_WatchedSkipPreFilters(t *testing.T) {
+	store := mustStorage(t, 100, 50)
+	mon := New(store)
+	if err := store.SaveWatch(&models.Watch{Kind: models.TargetMarket, Target: "m-watched"}); err != nil {
+		t.Fatalf("SaveWatch: %v", err)
+	}
+
+	markets := map[string]*models.Market{
+		"e1:m-watched": {ID: "e1:m-watched", EventID: "e1", MarketID: "m-watched", Volume24hr: 100_000},
+		"e1:m-other":   {ID: "e1:m-other", EventID: "e1", MarketID: "m-other", Volume24hr: 100_000},
+	}
+	small := func(id, marketID string, oldProb float64) models.Change {
+		return models.Change{ID: id, EventID: "e1:" + marketID, MarketID: marketID, OldProbability: oldProb, NewProbability: oldProb + 0.01,
+			Magnitude: 0.01, Direction: "increase", TimeWindow: time.Hour, DetectedAt: time.Now()}
+	}
+	changes := []models.Change{
+		small("watched-small", "m-watched", 0.40),
+		small("watched-tail", "m-watched", 0.02),
+		small("other-small", "m-other", 0.40),
+	}
+	scored := mon.ScoreChanges(context.Background(), changes, markets, 0.01, 25000, 0.05, 0.05)
+	if len(scored) != 2 || scored[0].ID != "watched-small" || scored[1].ID != "watched-tail" {
+		t.Fatalf("expected only the watched changes to skip the pre-filters, got %+v", scored)
+	}
+	for _, c := range scored {
+		if !c.Breakdown.WatchBypass {
+			t.Errorf("%s: watch bypass not recorded", c.ID)
+		}
+	}
+}
+
+func TestRankChanges_KeepsWatchedBeyondTopK(t *testing.T) {
+	promoted := &models.ScoreBreakdown{Routing: models.RoutingWatch}
+	scored := []models.Change{
+		{ID: "a", EventID: "ea:m", OriginalEventID: "ea", SignalScore: 0.9, PassedThreshold: true},
+		{ID: "b", EventID: "eb:m", OriginalEventID: "eb", SignalScore: 0.8, PassedThreshold: true},
+		{ID: "w", EventID: "ew:m", OriginalEventID: "ew", SignalScore: 0.001, PassedThreshold: true, Breakdown: promoted},
+		{ID: "c", EventID: "ec:m", OriginalEventID: "ec", SignalScore: 0.7, PassedThreshold: true},
+	}
+	var ids []string
+	for _, g := range RankChanges(scored, 1) {
+		ids = append(ids, g.ID)
+	}
+	if !slices.Equal(ids, []string{"ea", "ew"}) {
+		t.Errorf("RankChanges(k=1) = %v, want the top group plus the watched one [ea ew]", ids)
+	}
+}
+
+func TestSplitRecentlySent(t *testing.T) {
+	store := mustStorage(t, 100, 50)
+	mon := New(store)
+
+	change := func(id, direction string, newProb float64, score float64) models.Change {
+		oldProb := 0.5
+		return models.Change{ID: uuid.NewString(), EventID: id, OriginalEventID: "evt", Direction: direction,
+			OldProbability: oldProb, NewProbability: newProb, Magnitude: math.Abs(newProb - oldProb),
+			TimeWindow: time.Hour, DetectedAt: time.Now(), SignalScore: score}
+	}
+	mon.RecordNotified([]models.Event{{ID: "evt", Markets: []models.Change{
+		change("m-cont", "increase", 0.6, 1), change("m-rev", "increase", 0.6, 1),
+	}}})
+
+	group := models.Event{ID: "evt", Markets: []models.Change{
+		change("m-cont", "increase", 0.65, 0.3),
+		change("m-rev", "decrease", 0.45, 0.2),
+		change("m-new", "increase", 0.6, 0.1),
+	}}
+	fresh, continuing := mon.SplitRecentlySent([]models.Event{group}, time.Hour)
+	if len(fresh) != 1 || len(fresh[0].Markets) != 2 || fresh[0].BestScore != 0.2 {
+		t.Errorf("expected the reversal and the new market to be fresh, got %+v", fresh)
+	}
+	if len(continuing) != 1 || len(continuing[0].Markets) != 1 || continuing[0].Markets[0].EventID != "m-cont" || continuing[0].BestScore != 0.3 {
+		t.Errorf("expected the continuing move to be split off, got %+v", continuing)
+	}
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// Discord embed colours for alerts, errors and recoveries.
+const (
+	discordColorAlert    = 0xE67E22
+	discordColorError    = 0xE74C3C
+	discordColorRecovery = 0x2ECC71
+)
+
+// discordDescriptionLimit is the maximum length of an embed description, in characters.
+const discordDescriptionLimit = 4096
+
+// Discord sends notifications to a Discord channel webhook as embeds.
+type Discord struct {
+	webhookURL  string
+	destination string
+	http        httpSink
+}
+
+// NewDiscord creates a Discord notifier for the given webhook URL.
+func NewDiscord(webhookURL string, cfg HTTPConfig) (*Discord, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid Discord webhook URL")
+	}
+	// wait=true makes Discord return the created message, including its ID.
+	q := u.Query()
+	q.Set("wait", "true")
+	u.RawQuery = q.Encode()
+	return &Discord{
+		webhookURL:  u.String(),
+		destination: discordDestination(u),
+		http:        newHTTPSink(cfg),
+	}, nil
+}
+
+// discordDestination names the webhook by its ID (/api/webhooks/{id}/{token}),
+// leaving out the secret token.
+func discordDestination(u *url.URL) string {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "webhooks" {
+			return "discord:" + parts[i+1]
+		}
+	}
+	return "discord:" + u.Host
+}
+
+// Destination identifies the webhook in the alert history.
+func (d *Discord) Destination() string {
+	return d.destination
+}
+
+// Send posts the event groups as an embed and returns the Discord message ID.
+// Alerts longer than an embed description allows are split between event
+// groups into several messages, and their IDs are joined with commas.
+func (d *Discord) Send(groups []models.Event) (string, error) {
+	header, blocks := formatEventBlocks(groups, discordMarkup)
+	parts := splitBlocks(header, blocks, discordDescriptionLimit)
+	ids := make([]string, 0, len(parts))
+	for n, part := range parts {
+		id, err := d.post("🚨 Notable Odds Movements", part, discordColorAlert)
+		if err != nil {
+			if len(parts) > 1 {
+				err = fmt.Errorf("part %d of %d: %w", n+1, len(parts), err)
+			}
+			return strings.Join(ids, ","), fmt.Errorf("failed to send Discord message: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return strings.Join(ids, ","), nil
+}
+
+// SendError posts a monitoring error notification.
+func (d *Discord) SendError(cycleErr error) error {
+	if _, err := d.post("⚠️ Monitoring error", "`"+strings.ReplaceAll(cycleErr.Error(), "`", "'")+"`", discordColorError); err != nil {
+		return fmt.Errorf("failed to send Discord error message: %w", err)
+	}
+	return nil
+}
+
+// SendRecovery posts a recovery notification.
+func (d *Discord) SendRecovery(failureCount int) error {
+	text := fmt.Sprintf("Recovered after %d consecutive failure(s)", failureCount)
+	if _, err := d.post("✅ Monitoring recovered", text, discordColorRecovery); err != nil {
+		return fmt.Errorf("failed to send Discord recovery message: %w", err)
+	}
+	return nil
+}
+
+type discordEmbed struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Color       int    `json:"color"`
+}
+
+type discordPayload struct {
+	Embeds          []discordEmbed `json:"embeds"`
+	AllowedMentions struct {
+		Parse []string `json:"parse"`
+	} `json:"allowed_mentions"` // empty: never ping @everyone or roles from market text
+}
+
+func (d *Discord) post(title, description string, color int) (string, error) {
+	description = truncateRunes(description, discordDescriptionLimit)
+	payload := discordPayload{Embeds: []discordEmbed{{Title: title, Description: description, Color: color}}}
+	payload.AllowedMentions.Parse = []string{}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode payload: %w", err)
+	}
+	resp, err := d.http.post(d.webhookURL, body, nil)
+	if err != nil {
+		return "", err
+	}
+	var msg struct {
+		ID string `json:"id"`
+	}
+	if len(resp) > 0 {
+		_ = json.Unmarshal(resp, &msg)
+	}
+	return msg.ID, nil
+}
+
+var discordMarkup = markup{
+	escape: escapeDiscord,
+	bold:   func(s string) string { return "**" + s + "**" },
+	link: func(text, url string) string {
+		return "[" + escapeDiscord(text) + "](" + url + ")"
+	},
+}
+
+// escapeDiscord escapes Discord markdown control characters.
+func escapeDiscord(text string) string {
+	var b strings.Builder
+	b.Grow(len(text) + len(text)/4)
+	for _, char := range text {
+		switch char {
+		case '\\', '*', '_', '~', '`', '|', '>', '[', ']', '(', ')', '#':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(char)
+	}
+	return b.String()
+}
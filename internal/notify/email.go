@@ -0,0 +1,415 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// Queued is the message ID returned by Send when the alert was accepted into a
+// digest instead of being sent. Queued alerts are not delivered yet: the digest
+// records its own deliveries when it goes out.
+const Queued = "queued"
+
+// defaultMaxPending is the number of queued alerts kept for the next digest
+// when EmailConfig.MaxPending is not set.
+const defaultMaxPending = 100
+
+// DeliveryRecorder stores a successful delivery of the given changes; it
+// matches storage.Storage.RecordDelivery.
+type DeliveryRecorder func(changeIDs []string, destination, messageID string, sentAt time.Time) error
+
+// EmailConfig configures the SMTP notifier.
+type EmailConfig struct {
+	Host           string
+	Port           int
+	Username       string // empty = no AUTH
+	Password       string
+	From           string
+	To             []string
+	StartTLS       bool          // require STARTTLS before AUTH and DATA
+	DigestInterval time.Duration // 0 = one email per alert; otherwise alerts are batched
+	MaxPending     int           // queued alerts kept for the digest; the oldest are dropped beyond it (default 100)
+	Timeout        time.Duration // dial and session timeout (default 30s)
+
+	// Notified is called with the alerts of each digest that is sent, e.g. to
+	// start their notification cooldown. It may be nil.
+	Notified func(groups []models.Event)
+}
+
+// Email sends alerts over SMTP as multipart HTML and plain-text messages,
+// either one per alert or batched into a periodic digest.
+type Email struct {
+	cfg       EmailConfig
+	sender    string   // envelope sender (bare address of From)
+	rcpts     []string // envelope recipients (bare addresses of To)
+	tlsConfig *tls.Config
+	record    DeliveryRecorder
+
+	mu      sync.Mutex
+	pending [][]models.Event // queued alerts, one entry per Send
+}
+
+// NewEmail creates an SMTP notifier. record is called for each digest that is
+// sent and may be nil.
+func NewEmail(cfg EmailConfig, record DeliveryRecorder) (*Email, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP host must not be empty")
+	}
+	if cfg.From == "" || len(cfg.To) == 0 {
+		return nil, fmt.Errorf("email sender and at least one recipient are required")
+	}
+	sender, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	rcpts := make([]string, len(cfg.To))
+	for i, to := range cfg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient address %q: %w", to, err)
+		}
+		rcpts[i] = addr.Address
+	}
+	if cfg.Port <= 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = defaultMaxPending
+	}
+	return &Email{
+		cfg:       cfg,
+		sender:    sender.Address,
+		rcpts:     rcpts,
+		tlsConfig: &tls.Config{ServerName: cfg.Host},
+		record:    record,
+	}, nil
+}
+
+// Destination identifies the recipients in the alert history.
+func (e *Email) Destination() string {
+	return "email:" + strings.Join(e.rcpts, ",")
+}
+
+// Send emails the event groups and returns the Message-ID. In digest mode the
+// groups are queued for the next digest and Queued is returned.
+func (e *Email) Send(groups []models.Event) (string, error) {
+	if e.cfg.DigestInterval > 0 {
+		e.mu.Lock()
+		e.enqueue(groups)
+		e.mu.Unlock()
+		return Queued, nil
+	}
+	id, err := e.send("Notable Odds Movements", renderAlerts([][]models.Event{groups}, plainMarkup), renderHTML([][]models.Event{groups}))
+	if err != nil {
+		return "", fmt.Errorf("failed to send email: %w", err)
+	}
+	return id, nil
+}
+
+// SendError emails a monitoring error notification immediately.
+func (e *Email) SendError(cycleErr error) error {
+	text := "⚠️ Monitoring error\n\n" + cycleErr.Error() + "\n"
+	if _, err := e.send("Monitoring error", text, htmlPage(html.EscapeString(text))); err != nil {
+		return fmt.Errorf("failed to send error email: %w", err)
+	}
+	return nil
+}
+
+// SendRecovery emails a recovery notification immediately.
+func (e *Email) SendRecovery(failureCount int) error {
+	text := fmt.Sprintf("✅ Monitoring recovered after %d consecutive failure(s)\n", failureCount)
+	if _, err := e.send("Monitoring recovered", text, htmlPage(html.EscapeString(text))); err != nil {
+		return fmt.Errorf("failed to send recovery email: %w", err)
+	}
+	return nil
+}
+
+// Run sends the queued digest every DigestInterval until ctx is cancelled,
+// then sends whatever is still queued. It returns immediately when digests are
+// disabled.
+func (e *Email) Run(ctx context.Context) {
+	if e.cfg.DigestInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(e.cfg.DigestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := e.Flush(); err != nil {
+				logger.Warn("Failed to send final email digest: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := e.Flush(); err != nil {
+				logger.Warn("Failed to send email digest: %v", err)
+			}
+		}
+	}
+}
+
+// Flush sends all queued alerts as one digest email and records a delivery for
+// every change in it. On failure the alerts stay queued for the next attempt.
+func (e *Email) Flush() error {
+	e.mu.Lock()
+	batches := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+	if len(batches) == 0 {
+		return nil
+	}
+
+	alerts := 0
+	for _, b := range batches {
+		alerts += len(b)
+	}
+	subject := fmt.Sprintf("Odds movement digest (%d events)", alerts)
+	id, err := e.send(subject, renderAlerts(batches, plainMarkup), renderHTML(batches))
+	if err != nil {
+		e.mu.Lock()
+		queued := e.pending
+		e.pending = batches
+		e.enqueue(queued...)
+		e.mu.Unlock()
+		return fmt.Errorf("failed to send digest: %w", err)
+	}
+
+	if e.cfg.Notified != nil {
+		var groups []models.Event
+		for _, b := range batches {
+			groups = append(groups, b...)
+		}
+		e.cfg.Notified(groups)
+	}
+
+	if e.record != nil {
+		var ids []string
+		for _, b := range batches {
+			for _, g := range b {
+				for _, c := range g.Markets {
+					ids = append(ids, c.ID)
+				}
+			}
+		}
+		if err := e.record(ids, e.Destination(), id, time.Now()); err != nil {
+			return fmt.Errorf("failed to record digest delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// enqueue appends alerts to the digest queue, dropping the oldest ones beyond
+// MaxPending. A market re-reported before the digest goes out is kept only in
+// its latest alert. The caller must hold e.mu.
+func (e *Email) enqueue(batches ...[]models.Event) {
+	for _, batch := range batches {
+		keys := make(map[string]bool)
+		for _, g := range batch {
+			for _, c := range g.Markets {
+				keys[c.Key()] = true
+			}
+		}
+		kept := e.pending[:0]
+		for _, queued := range e.pending {
+			if queued = withoutKeys(queued, keys); len(queued) > 0 {
+				kept = append(kept, queued)
+			}
+		}
+		e.pending = append(kept, batch)
+	}
+	if drop := len(e.pending) - e.cfg.MaxPending; drop > 0 {
+		logger.Warn("Email digest queue is full, dropping the %d oldest alert(s)", drop)
+		e.pending = append([][]models.Event(nil), e.pending[drop:]...)
+	}
+}
+
+// withoutKeys returns groups without the changes whose Key is in keys,
+// dropping groups left empty. groups is not modified.
+func withoutKeys(groups []models.Event, keys map[string]bool) []models.Event {
+	var out []models.Event
+	for _, g := range groups {
+		var markets []models.Change
+		for _, c := range g.Markets {
+			if !keys[c.Key()] {
+				markets = append(markets, c)
+			}
+		}
+		if len(markets) == 0 {
+			continue
+		}
+		if len(markets) < len(g.Markets) {
+			g.Markets = markets
+			g.BestScore = markets[0].SignalScore
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+// renderAlerts renders one section per queued alert, each in the Telegram
+// alert layout.
+func renderAlerts(batches [][]models.Event, mk markup) string {
+	var b strings.Builder
+	b.WriteString("🚨 " + mk.bold("Notable Odds Movements") + "\n\n")
+	for _, groups := range batches {
+		b.WriteString(formatEvents(groups, mk))
+	}
+	return b.String()
+}
+
+func renderHTML(batches [][]models.Event) string {
+	return htmlPage(renderAlerts(batches, htmlMarkup))
+}
+
+func htmlPage(body string) string {
+	return `<!DOCTYPE html><html><body>` +
+		`<div style="font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;white-space:pre-wrap">` +
+		body + `</div></body></html>`
+}
+
+var plainMarkup = markup{
+	escape: func(s string) string { return s },
+	bold:   func(s string) string { return s },
+	link: func(text, url string) string {
+		return text + " (" + url + ")"
+	},
+}
+
+var htmlMarkup = markup{
+	escape: html.EscapeString,
+	bold:   func(s string) string { return "<strong>" + html.EscapeString(s) + "</strong>" },
+	link: func(text, url string) string {
+		return `<a href="` + html.EscapeString(url) + `">` + html.EscapeString(text) + `</a>`
+	},
+}
+
+// send delivers one multipart/alternative message and returns its Message-ID.
+func (e *Email) send(subject, text, htmlBody string) (string, error) {
+	msg, id, err := e.buildMessage(subject, text, htmlBody)
+	if err != nil {
+		return "", err
+	}
+
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	conn, err := net.DialTimeout("tcp", addr, e.cfg.Timeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(e.cfg.Timeout))
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return "", fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if e.cfg.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return "", fmt.Errorf("server does not support STARTTLS")
+		}
+		if err := c.StartTLS(e.tlsConfig); err != nil {
+			return "", fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return "", fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if err := c.Mail(e.sender); err != nil {
+		return "", fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, to := range e.rcpts {
+		if err := c.Rcpt(to); err != nil {
+			return "", fmt.Errorf("failed to add recipient %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return "", fmt.Errorf("failed to start message data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return "", fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to send message: %w", err)
+	}
+	_ = c.Quit()
+	return id, nil
+}
+
+func (e *Email) buildMessage(subject, text, htmlBody string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	id, err := messageID(e.sender)
+	if err != nil {
+		return nil, "", err
+	}
+	fmt.Fprintf(&buf, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.cfg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", id)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create MIME part: %w", err)
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(strings.ReplaceAll(part.body, "\n", "\r\n"))); err != nil {
+			return nil, "", fmt.Errorf("failed to encode MIME part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, "", fmt.Errorf("failed to encode MIME part: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finish MIME message: %w", err)
+	}
+	return buf.Bytes(), id, nil
+}
+
+// messageID returns a unique RFC 5322 Message-ID in the sender's domain.
+func messageID(from string) (string, error) {
+	domain := "polyoracle.local"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate message ID: %w", err)
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
@@ -0,0 +1,417 @@
+package notify
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// smtpStandIn is a minimal local SMTP server: EHLO, optional STARTTLS, AUTH
+// PLAIN, MAIL, RCPT and DATA. It records every message it accepts.
+type smtpStandIn struct {
+	ln         net.Listener
+	tlsConfig  *tls.Config // nil = STARTTLS not offered
+	user, pass string
+
+	mu       sync.Mutex
+	messages []receivedMail
+}
+
+type receivedMail struct {
+	from   string
+	to     []string
+	data   string
+	tls    bool
+	authed bool
+}
+
+func newSMTPStandIn(t *testing.T, tlsConfig *tls.Config, user, pass string) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{ln: ln, tlsConfig: tlsConfig, user: user, pass: pass}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) received() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.messages...)
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	var cur receivedMail
+
+	reply("220 stand-in ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-stand-in")
+			if s.tlsConfig != nil && !cur.tls {
+				reply("250-STARTTLS")
+			}
+			if s.user != "" {
+				reply("250-AUTH PLAIN")
+			}
+			reply("250 8BITMIME")
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, cur.tls = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			fields := strings.Fields(line)
+			creds, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			if string(creds) == "\x00"+s.user+"\x00"+s.pass {
+				cur.authed = true
+				reply("235 authenticated")
+			} else {
+				reply("535 bad credentials")
+			}
+		case "MAIL":
+			cur.from = angleAddr(line)
+			reply("250 ok")
+		case "RCPT":
+			cur.to = append(cur.to, angleAddr(line))
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			cur.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, cur)
+			s.mu.Unlock()
+			cur = receivedMail{tls: cur.tls, authed: cur.authed}
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// angleAddr extracts the address between the first < and > of an SMTP command.
+func angleAddr(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+// testTLS returns a server TLS config and a matching client config trusting it.
+func testTLS(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	return &tls.Config{Certificates: srv.TLS.Certificates},
+		&tls.Config{ServerName: "127.0.0.1", RootCAs: pool}
+}
+
+// parseParts returns the decoded text/plain and text/html bodies of a message.
+func parseParts(t *testing.T, data string) (headers mail.Header, plain, html string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", msg.Header.Get("Content-Type"), err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		body, _ := io.ReadAll(p)
+		switch {
+		case strings.HasPrefix(p.Header.Get("Content-Type"), "text/plain"):
+			plain = string(body)
+		case strings.HasPrefix(p.Header.Get("Content-Type"), "text/html"):
+			html = string(body)
+		}
+	}
+	return msg.Header, plain, html
+}
+
+func TestEmail_SendOverStartTLSWithAuth(t *testing.T) {
+	serverTLS, clientTLS := testTLS(t)
+	srv := newSMTPStandIn(t, serverTLS, "alerts", "hunter2")
+
+	e, err := NewEmail(EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     srv.port(),
+		Username: "alerts",
+		Password: "hunter2",
+		From:     "Polyoracle <alerts@example.com>",
+		To:       []string{"desk@example.com"},
+		StartTLS: true,
+		Timeout:  5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewEmail: %v", err)
+	}
+	e.tlsConfig = clientTLS
+
+	id, err := e.Send(testGroups())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	msgs := srv.received()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	m := msgs[0]
+	if !m.tls || !m.authed {
+		t.Errorf("expected STARTTLS and AUTH before DATA (tls=%v authed=%v)", m.tls, m.authed)
+	}
+	if m.from != "alerts@example.com" || len(m.to) != 1 || m.to[0] != "desk@example.com" {
+		t.Errorf("unexpected envelope: from=%q to=%v", m.from, m.to)
+	}
+
+	headers, plain, html := parseParts(t, m.data)
+	if headers.Get("Message-Id") != id {
+		t.Errorf("Message-ID header %q does not match returned ID %q", headers.Get("Message-Id"), id)
+	}
+	for _, want := range []string{
+		"Will <X> happen? (https://polymarket.com/event/x)",
+		"🏷 Trump",
+		"📈 12.0% (41.0% → 53.0%) ⏱ 45m",
+	} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("plain text missing %q:\n%s", want, plain)
+		}
+	}
+	if !strings.Contains(html, `<a href="https://polymarket.com/event/x">Will &lt;X&gt; happen?</a>`) {
+		t.Errorf("HTML missing escaped event link:\n%s", html)
+	}
+}
+
+func TestEmail_RequiresStartTLSWhenConfigured(t *testing.T) {
+	srv := newSMTPStandIn(t, nil, "", "")
+	e, err := NewEmail(EmailConfig{
+		Host: "127.0.0.1", Port: srv.port(), From: "alerts@example.com",
+		To: []string{"desk@example.com"}, StartTLS: true, Timeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewEmail: %v", err)
+	}
+	if _, err := e.Send(testGroups()); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected STARTTLS error, got %v", err)
+	}
+	if n := len(srv.received()); n != 0 {
+		t.Errorf("message sent without TLS: %d", n)
+	}
+}
+
+func TestEmail_DigestBatchesAlerts(t *testing.T) {
+	srv := newSMTPStandIn(t, nil, "", "")
+	var recordedIDs []string
+	var recordedMsgID string
+	e, err := NewEmail(EmailConfig{
+		Host: "127.0.0.1", Port: srv.port(), From: "alerts@example.com",
+		To: []string{"desk@example.com"}, DigestInterval: time.Hour, Timeout: 5 * time.Second,
+	}, func(changeIDs []string, destination, messageID string, sentAt time.Time) error {
+		recordedIDs, recordedMsgID = changeIDs, messageID
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewEmail: %v", err)
+	}
+
+	second := testGroups()
+	second[0].Title = "Second event"
+	second[0].Markets[0].ID = "chg-2"
+	second[0].Markets[0].EventID = "evt-2:mkt-1"
+	for _, groups := range [][]models.Event{testGroups(), second} {
+		id, err := e.Send(groups)
+		if err != nil || id != Queued {
+			t.Fatalf("Send in digest mode = %q, %v; want Queued", id, err)
+		}
+	}
+	if n := len(srv.received()); n != 0 {
+		t.Fatalf("digest sent before Flush: %d messages", n)
+	}
+
+	if err := e.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	msgs := srv.received()
+	if len(msgs) != 1 {
+		t.Fatalf("expected one digest email, got %d", len(msgs))
+	}
+	headers, plain, _ := parseParts(t, msgs[0].data)
+	if !strings.Contains(plain, "Will <X> happen?") || !strings.Contains(plain, "Second event") {
+		t.Errorf("digest missing an alert:\n%s", plain)
+	}
+	if strings.Join(recordedIDs, ",") != "chg-1,chg-2" || recordedMsgID != headers.Get("Message-Id") {
+		t.Errorf("recorded delivery ids=%v msg=%q", recordedIDs, recordedMsgID)
+	}
+
+	// Nothing queued: no email.
+	if err := e.Flush(); err != nil {
+		t.Fatalf("empty Flush: %v", err)
+	}
+	if n := len(srv.received()); n != 1 {
+		t.Errorf("empty Flush sent a message: %d total", n)
+	}
+}
+
+func TestEmail_DigestQueueDropsOldest(t *testing.T) {
+	srv := newSMTPStandIn(t, nil, "", "")
+	var notified []models.Event
+	e, err := NewEmail(EmailConfig{
+		Host: "127.0.0.1", Port: srv.port(), From: "alerts@example.com",
+		To: []string{"desk@example.com"}, DigestInterval: time.Hour, MaxPending: 2, Timeout: 5 * time.Second,
+		Notified: func(groups []models.Event) { notified = groups },
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewEmail: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		groups := testGroups()
+		groups[0].Title = fmt.Sprintf("Event %d", i)
+		groups[0].Markets[0].EventID = fmt.Sprintf("evt-%d:mkt-1", i)
+		if _, err := e.Send(groups); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if notified != nil {
+		t.Fatal("queued alerts reported as notified before the digest was sent")
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	_, plain, _ := parseParts(t, srv.received()[0].data)
+	if strings.Contains(plain, "Event 1") || !strings.Contains(plain, "Event 2") || !strings.Contains(plain, "Event 3") {
+		t.Errorf("digest should keep the two newest alerts:\n%s", plain)
+	}
+	if len(notified) != 2 || notified[0].Title != "Event 2" || notified[1].Title != "Event 3" {
+		t.Errorf("Notified called with %+v, want events 2 and 3", notified)
+	}
+}
+
+func TestEmail_DigestKeepsLatestAlertPerMarket(t *testing.T) {
+	srv := newSMTPStandIn(t, nil, "", "")
+	var recordedIDs []string
+	var notified []models.Event
+	e, err := NewEmail(EmailConfig{
+		Host: "127.0.0.1", Port: srv.port(), From: "alerts@example.com",
+		To: []string{"desk@example.com"}, DigestInterval: time.Hour, Timeout: 5 * time.Second,
+		Notified: func(groups []models.Event) { notified = groups },
+	}, func(changeIDs []string, destination, messageID string, sentAt time.Time) error {
+		recordedIDs = changeIDs
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NewEmail: %v", err)
+	}
+
+	// The same move is reported again on the next cycle, further along.
+	later := testGroups()
+	later[0].Markets[0].ID = "chg-1b"
+	later[0].Markets[0].NewProbability = 0.58
+	for _, groups := range [][]models.Event{testGroups(), later} {
+		if _, err := e.Send(groups); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	_, plain, _ := parseParts(t, srv.received()[0].data)
+	if n := strings.Count(plain, "Will X happen by June?"); n != 1 {
+		t.Errorf("market listed %d times in the digest, want once:\n%s", n, plain)
+	}
+	if !strings.Contains(plain, "58") || strings.Contains(plain, "53") {
+		t.Errorf("digest should carry the latest report only:\n%s", plain)
+	}
+	if strings.Join(recordedIDs, ",") != "chg-1b" {
+		t.Errorf("recorded delivery ids=%v, want [chg-1b]", recordedIDs)
+	}
+	if len(notified) != 1 || notified[0].Markets[0].ID != "chg-1b" {
+		t.Errorf("Notified called with %+v, want the latest alert only", notified)
+	}
+}
+
+func TestNewEmail_ValidatesAddresses(t *testing.T) {
+	if _, err := NewEmail(EmailConfig{Host: "smtp.example.com", From: "not an address", To: []string{"a@example.com"}}, nil); err == nil {
+		t.Error("expected error for invalid sender")
+	}
+	if _, err := NewEmail(EmailConfig{Host: "smtp.example.com", From: "a@example.com"}, nil); err == nil {
+		t.Error("expected error for missing recipients")
+	}
+	e, err := NewEmail(EmailConfig{Host: "smtp.example.com", From: "a@example.com", To: []string{"Desk <desk@example.com>"}}, nil)
+	if err != nil {
+		t.Fatalf("NewEmail: %v", err)
+	}
+	if e.Destination() != "email:desk@example.com" || e.cfg.Port != 587 {
+		t.Errorf("Destination=%q port=%s", e.Destination(), strconv.Itoa(e.cfg.Port))
+	}
+}
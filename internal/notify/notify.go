@@ -0,0 +1,214 @@
+// Package notify defines the Notifier interface implemented by every alert sink
+// and provides the webhook-based sinks: Slack incoming webhooks, Discord
+// webhooks and a signed generic JSON webhook.
+//
+// Sinks are independent: the monitoring loop sends each alert to every
+// configured Notifier and records a delivery for each one that succeeds.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// Notifier delivers alerts and service-health messages to one destination.
+type Notifier interface {
+	// Destination identifies the sink in the alert history, e.g. "telegram:-100123".
+	Destination() string
+	// Send delivers the event groups and returns the sink's message ID, or ""
+	// when the sink does not report one.
+	Send(groups []models.Event) (string, error)
+	// SendError reports a failed monitoring cycle.
+	SendError(cycleErr error) error
+	// SendRecovery reports that monitoring recovered after failureCount failed cycles.
+	SendRecovery(failureCount int) error
+}
+
+// FollowUpper is implemented by notifiers that can report a move continuing
+// an alert they sent recently, which the monitor's cooldown keeps out of new
+// alerts, as a follow-up to that alert. Such notifiers record the deliveries
+// of the follow-ups they post.
+type FollowUpper interface {
+	FollowUp(groups []models.Event) error
+}
+
+// HTTPConfig controls delivery for the webhook-based sinks.
+type HTTPConfig struct {
+	Timeout        time.Duration   // per-request timeout (default 10s)
+	MaxRetries     int             // attempts per message (default 3)
+	RetryDelayBase time.Duration   // linear backoff base between attempts (default 1s)
+	Context        context.Context // cancelling it aborts requests and pending retries, e.g. on shutdown (default: never)
+}
+
+// httpSink posts payloads with retry. Network errors, 429 and 5xx responses
+// are retried; other non-2xx responses fail immediately.
+type httpSink struct {
+	ctx            context.Context
+	client         *http.Client
+	maxRetries     int
+	retryDelayBase time.Duration
+}
+
+func newHTTPSink(cfg HTTPConfig) httpSink {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryDelayBase <= 0 {
+		cfg.RetryDelayBase = time.Second
+	}
+	if cfg.Context == nil {
+		cfg.Context = context.Background()
+	}
+	return httpSink{
+		ctx:            cfg.Context,
+		client:         &http.Client{Timeout: cfg.Timeout},
+		maxRetries:     cfg.MaxRetries,
+		retryDelayBase: cfg.RetryDelayBase,
+	}
+}
+
+// post sends body to url and returns the response body of the first 2xx reply.
+// sign, when non-nil, is called on every attempt to add request headers.
+func (h httpSink) post(url string, body []byte, sign func(http.Header)) ([]byte, error) {
+	var lastErr error
+	for i := 0; i < h.maxRetries; i++ {
+		if i > 0 {
+			select {
+			case <-h.ctx.Done():
+				return nil, fmt.Errorf("retry cancelled: %w (last error: %v)", h.ctx.Err(), lastErr)
+			case <-time.After(h.retryDelayBase * time.Duration(i)):
+			}
+		}
+		req, err := http.NewRequestWithContext(h.ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if sign != nil {
+			sign(req.Header)
+		}
+		resp, err := h.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response: %w", err)
+			}
+			return respBody, nil
+		}
+		lastErr = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return nil, lastErr
+		}
+	}
+	return nil, fmt.Errorf("failed after %d attempts: %w", h.maxRetries, lastErr)
+}
+
+// markup describes how a sink renders rich text.
+type markup struct {
+	escape func(string) string
+	bold   func(string) string
+	link   func(text, url string) string
+}
+
+// formatEvents renders event groups with the same layout as the Telegram
+// alert: a detection time line, then one numbered entry per event with its
+// markets as sub-bullets. The heading is left to the caller.
+func formatEvents(groups []models.Event, mk markup) string {
+	header, blocks := formatEventBlocks(groups, mk)
+	return header + strings.Join(blocks, "")
+}
+
+// formatEventBlocks renders the detection time line and, separately, the entry
+// of each event group, so sinks with a length limit can split between groups.
+func formatEventBlocks(groups []models.Event, mk markup) (header string, blocks []string) {
+	if len(groups) > 0 && len(groups[0].Markets) > 0 {
+		header = fmt.Sprintf("📅 Detected: %s\n\n", mk.escape(groups[0].Markets[0].DetectedAt.Format("2006-01-02 15:04:05")))
+	}
+
+	for i, group := range groups {
+		var b strings.Builder
+		title := mk.escape(group.Title)
+		if group.URL != "" {
+			title = mk.link(group.Title, group.URL)
+		}
+		fmt.Fprintf(&b, "%d. %s\n", i+1, title)
+
+		for _, change := range group.Markets {
+			directionEmoji := "📈"
+			if change.Direction == "decrease" {
+				directionEmoji = "📉"
+			}
+			if change.MarketQuestion != "" && change.MarketQuestion != group.Title {
+				fmt.Fprintf(&b, "   🎯 %s\n", mk.escape(change.MarketQuestion))
+			}
+			if change.Outcome != "" {
+				fmt.Fprintf(&b, "   🏷 %s\n", mk.escape(change.Outcome))
+			}
+			fmt.Fprintf(&b, "   %s %s (%.1f%% → %.1f%%) ⏱ %s\n",
+				directionEmoji,
+				mk.bold(fmt.Sprintf("%.1f%%", change.Magnitude*100)),
+				change.OldProbability*100, change.NewProbability*100,
+				formatDuration(change.TimeWindow))
+		}
+		b.WriteString("\n")
+		blocks = append(blocks, b.String())
+	}
+	return header, blocks
+}
+
+// splitBlocks joins blocks into texts of at most limit characters, starting
+// the first with header and breaking only between blocks. A block too long
+// for a text of its own is truncated.
+func splitBlocks(header string, blocks []string, limit int) []string {
+	var texts []string
+	cur, curLen, curBlocks := header, utf8.RuneCountInString(header), 0
+	for _, block := range blocks {
+		n := utf8.RuneCountInString(block)
+		if curLen+n > limit && curBlocks > 0 {
+			texts = append(texts, cur)
+			cur, curLen, curBlocks = "", 0, 0
+		}
+		if curLen+n > limit {
+			block, n = truncateRunes(block, limit-curLen), limit-curLen
+		}
+		cur += block
+		curLen += n
+		curBlocks++
+	}
+	return append(texts, cur)
+}
+
+// truncateRunes shortens s to at most limit characters, ending in "…".
+func truncateRunes(s string, limit int) string {
+	if limit < 1 {
+		return ""
+	}
+	if r := []rune(s); len(r) > limit {
+		return string(r[:limit-1]) + "…"
+	}
+	return s
+}
+
+// formatDuration formats a duration in a human-readable way
+func formatDuration(d time.Duration) string {
+	if hours := int(d.Hours()); hours >= 1 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
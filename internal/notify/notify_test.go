@@ -0,0 +1,282 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+var fastRetry = HTTPConfig{Timeout: time.Second, MaxRetries: 3, RetryDelayBase: time.Millisecond}
+
+func testGroups() []models.Event {
+	return []models.Event{{
+		ID:        "evt-1",
+		Title:     "Will <X> happen?",
+		URL:       "https://polymarket.com/event/x",
+		BestScore: 0.5,
+		Markets: []models.Change{{
+			ID:             "chg-1",
+			EventID:        "evt-1:mkt-1",
+			MarketQuestion: "Will X happen by June?",
+			Outcome:        "Trump",
+			Magnitude:      0.12,
+			Direction:      "increase",
+			OldProbability: 0.41,
+			NewProbability: 0.53,
+			TimeWindow:     45 * time.Minute,
+			DetectedAt:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		}},
+	}}
+}
+
+func TestSlack_Send(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	s, err := NewSlack(srv.URL+"/services/T123/B456/secret", fastRetry)
+	if err != nil {
+		t.Fatalf("NewSlack: %v", err)
+	}
+	if s.Destination() != "slack:T123/B456" {
+		t.Errorf("Destination() = %q, want token-free slack:T123/B456", s.Destination())
+	}
+	if _, err := s.Send(testGroups()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	text := got["text"]
+	for _, want := range []string{
+		"<https://polymarket.com/event/x|Will &lt;X&gt; happen?>",
+		"🏷 Trump",
+		"📈 *12.0%* (41.0% → 53.0%) ⏱ 45m",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("message missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestDiscord_SendReturnsMessageID(t *testing.T) {
+	var payload discordPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("wait") != "true" {
+			t.Errorf("expected wait=true, got %q", r.URL.RawQuery)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"id":"1122334455"}`))
+	}))
+	defer srv.Close()
+
+	d, err := NewDiscord(srv.URL+"/api/webhooks/987/secret", fastRetry)
+	if err != nil {
+		t.Fatalf("NewDiscord: %v", err)
+	}
+	if d.Destination() != "discord:987" {
+		t.Errorf("Destination() = %q, want discord:987", d.Destination())
+	}
+	id, err := d.Send(testGroups())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if id != "1122334455" {
+		t.Errorf("message ID = %q, want 1122334455", id)
+	}
+	if len(payload.Embeds) != 1 || !strings.Contains(payload.Embeds[0].Description, "**12.0%**") {
+		t.Errorf("unexpected embed: %+v", payload.Embeds)
+	}
+	if payload.AllowedMentions.Parse == nil {
+		t.Error("allowed_mentions.parse must be an explicit empty list")
+	}
+}
+
+func TestDiscord_SplitsLongAlertsBetweenGroups(t *testing.T) {
+	var descriptions []string
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload discordPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		descriptions = append(descriptions, payload.Embeds[0].Description)
+		_, _ = fmt.Fprintf(w, `{"id":"%d"}`, calls.Add(1))
+	}))
+	defer srv.Close()
+
+	var groups []models.Event
+	for i := 0; i < 40; i++ {
+		g := testGroups()[0]
+		g.ID = fmt.Sprintf("evt-%d", i)
+		g.Title = fmt.Sprintf("Event %d %s", i, strings.Repeat("é", 100))
+		groups = append(groups, g)
+	}
+
+	d, err := NewDiscord(srv.URL+"/api/webhooks/987/secret", fastRetry)
+	if err != nil {
+		t.Fatalf("NewDiscord: %v", err)
+	}
+	id, err := d.Send(groups)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(descriptions) < 2 {
+		t.Fatalf("want the alert split into several messages, got %d", len(descriptions))
+	}
+	var wantIDs []string
+	for i := range descriptions {
+		wantIDs = append(wantIDs, fmt.Sprint(i+1))
+	}
+	if want := strings.Join(wantIDs, ","); id != want {
+		t.Errorf("message IDs = %q, want %q", id, want)
+	}
+	all := strings.Join(descriptions, "")
+	for i, desc := range descriptions {
+		if n := utf8.RuneCountInString(desc); n > discordDescriptionLimit {
+			t.Errorf("message %d has %d characters, limit %d", i, n, discordDescriptionLimit)
+		}
+		if strings.Contains(desc, "…") {
+			t.Errorf("message %d was truncated instead of split between groups", i)
+		}
+	}
+	for _, g := range groups {
+		if strings.Count(all, g.Title+"]") != 1 {
+			t.Errorf("event %s should appear exactly once across messages", g.ID)
+		}
+	}
+}
+
+func TestSplitBlocks_TruncatesOversizedBlock(t *testing.T) {
+	parts := splitBlocks("head\n", []string{"short\n", strings.Repeat("x", 50), "tail"}, 20)
+	want := []string{"head\nshort\n", strings.Repeat("x", 19) + "…", "tail"}
+	if len(parts) != len(want) {
+		t.Fatalf("parts = %q, want %q", parts, want)
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("part %d = %q, want %q", i, parts[i], want[i])
+		}
+	}
+}
+
+func TestWebhook_SignsPayload(t *testing.T) {
+	secret := "s3cret"
+	var payload WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts := r.Header.Get(TimestampHeader)
+		if want := Sign([]byte(secret), ts, body); r.Header.Get(SignatureHeader) != want {
+			t.Errorf("signature = %q, want %q", r.Header.Get(SignatureHeader), want)
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	wh, err := NewWebhook(srv.URL, secret, fastRetry)
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	if _, err := wh.Send(testGroups()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if payload.Type != "alert" || len(payload.Events) != 1 || payload.Events[0].Markets[0].ID != "chg-1" {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+
+	if err := wh.SendError(errors.New("boom")); err != nil {
+		t.Fatalf("SendError: %v", err)
+	}
+	if payload.Type != "error" || payload.Error != "boom" {
+		t.Errorf("unexpected error payload: %+v", payload)
+	}
+	if err := wh.SendRecovery(3); err != nil {
+		t.Fatalf("SendRecovery: %v", err)
+	}
+	if payload.Type != "recovery" || payload.FailureCount != 3 {
+		t.Errorf("unexpected recovery payload: %+v", payload)
+	}
+}
+
+func TestNewWebhook_RequiresSecret(t *testing.T) {
+	if _, err := NewWebhook("https://example.com/hook", "", fastRetry); err == nil {
+		t.Error("expected error for empty secret")
+	}
+}
+
+func TestHTTPSink_RetriesServerErrorsOnly(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/bad":
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	h := newHTTPSink(fastRetry)
+	if _, err := h.post(srv.URL+"/flaky", []byte("{}"), nil); err != nil {
+		t.Fatalf("expected success on third attempt: %v", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("flaky endpoint called %d times, want 3", n)
+	}
+
+	calls.Store(0)
+	if _, err := h.post(srv.URL+"/bad", []byte("{}"), nil); err == nil {
+		t.Fatal("expected error for 400")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("400 response retried: %d calls, want 1", n)
+	}
+}
+
+func TestHTTPSink_CancelStopsRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := newHTTPSink(HTTPConfig{Timeout: time.Second, MaxRetries: 5, RetryDelayBase: time.Hour, Context: ctx})
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := h.post(srv.URL, []byte("{}"), nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("post returned after %v, should stop waiting on cancel", elapsed)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("endpoint called %d times after cancel, want 1", n)
+	}
+}
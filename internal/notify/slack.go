@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// Slack sends notifications to a Slack incoming webhook.
+type Slack struct {
+	webhookURL  string
+	destination string
+	http        httpSink
+}
+
+// NewSlack creates a Slack notifier for the given incoming-webhook URL.
+func NewSlack(webhookURL string, cfg HTTPConfig) (*Slack, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid Slack webhook URL")
+	}
+	return &Slack{
+		webhookURL:  webhookURL,
+		destination: slackDestination(u),
+		http:        newHTTPSink(cfg),
+	}, nil
+}
+
+// slackDestination names the webhook by its workspace and integration IDs
+// (/services/T…/B…/token), leaving out the secret token.
+func slackDestination(u *url.URL) string {
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) >= 3 && parts[0] == "services" {
+		return "slack:" + parts[1] + "/" + parts[2]
+	}
+	return "slack:" + u.Host
+}
+
+// Destination identifies the webhook in the alert history.
+func (s *Slack) Destination() string {
+	return s.destination
+}
+
+// Send posts the event groups as an mrkdwn message. Incoming webhooks do not
+// return a message ID, so the returned ID is always empty.
+func (s *Slack) Send(groups []models.Event) (string, error) {
+	text := "🚨 *Notable Odds Movements*\n\n" + formatEvents(groups, slackMarkup)
+	if err := s.post(text); err != nil {
+		return "", fmt.Errorf("failed to send Slack message: %w", err)
+	}
+	return "", nil
+}
+
+// SendError posts a monitoring error notification.
+func (s *Slack) SendError(cycleErr error) error {
+	if err := s.post(fmt.Sprintf("⚠️ *Monitoring error*\n`%s`", escapeSlack(cycleErr.Error()))); err != nil {
+		return fmt.Errorf("failed to send Slack error message: %w", err)
+	}
+	return nil
+}
+
+// SendRecovery posts a recovery notification.
+func (s *Slack) SendRecovery(failureCount int) error {
+	if err := s.post(fmt.Sprintf("✅ *Monitoring recovered* after %d consecutive failure(s)", failureCount)); err != nil {
+		return fmt.Errorf("failed to send Slack recovery message: %w", err)
+	}
+	return nil
+}
+
+func (s *Slack) post(text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	_, err = s.http.post(s.webhookURL, body, nil)
+	return err
+}
+
+var slackMarkup = markup{
+	escape: escapeSlack,
+	bold:   func(s string) string { return "*" + s + "*" },
+	link: func(text, url string) string {
+		return "<" + url + "|" + escapeSlack(text) + ">"
+	},
+}
+
+// escapeSlack escapes the three characters Slack treats as control sequences.
+func escapeSlack(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// Signature headers set on every generic webhook request. The signature is
+// hex(HMAC-SHA256(secret, timestamp + "." + body)), prefixed with "sha256=".
+const (
+	SignatureHeader = "X-Polyoracle-Signature"
+	TimestampHeader = "X-Polyoracle-Timestamp"
+)
+
+// Webhook posts notifications as JSON to an arbitrary HTTP endpoint, signed
+// with a shared secret so the receiver can verify their origin.
+type Webhook struct {
+	url    string
+	host   string
+	secret []byte
+	http   httpSink
+	now    func() time.Time
+}
+
+// NewWebhook creates a generic JSON webhook notifier.
+func NewWebhook(endpoint, secret string, cfg HTTPConfig) (*Webhook, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL: %q", endpoint)
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("webhook secret must not be empty")
+	}
+	return &Webhook{
+		url:    endpoint,
+		host:   u.Host,
+		secret: []byte(secret),
+		http:   newHTTPSink(cfg),
+		now:    time.Now,
+	}, nil
+}
+
+// WebhookPayload is the JSON body posted by Webhook. Type is "alert", "error"
+// or "recovery"; only the fields for that type are set.
+type WebhookPayload struct {
+	Type         string         `json:"type"`
+	SentAt       time.Time      `json:"sent_at"`
+	Events       []WebhookEvent `json:"events,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	FailureCount int            `json:"failure_count,omitempty"`
+}
+
+// WebhookEvent is one event group in an alert payload.
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	URL       string          `json:"url"`
+	BestScore float64         `json:"best_score"`
+	Markets   []models.Change `json:"markets"`
+}
+
+// Destination identifies the endpoint host in the alert history.
+func (w *Webhook) Destination() string {
+	return "webhook:" + w.host
+}
+
+// Send posts an alert payload. The receiver's response body is not inspected,
+// so the returned message ID is always empty.
+func (w *Webhook) Send(groups []models.Event) (string, error) {
+	events := make([]WebhookEvent, len(groups))
+	for i, g := range groups {
+		events[i] = WebhookEvent{ID: g.ID, Title: g.Title, URL: g.URL, BestScore: g.BestScore, Markets: g.Markets}
+	}
+	if err := w.post(WebhookPayload{Type: "alert", Events: events}); err != nil {
+		return "", fmt.Errorf("failed to send webhook alert: %w", err)
+	}
+	return "", nil
+}
+
+// SendError posts an error payload.
+func (w *Webhook) SendError(cycleErr error) error {
+	if err := w.post(WebhookPayload{Type: "error", Error: cycleErr.Error()}); err != nil {
+		return fmt.Errorf("failed to send webhook error: %w", err)
+	}
+	return nil
+}
+
+// SendRecovery posts a recovery payload.
+func (w *Webhook) SendRecovery(failureCount int) error {
+	if err := w.post(WebhookPayload{Type: "recovery", FailureCount: failureCount}); err != nil {
+		return fmt.Errorf("failed to send webhook recovery: %w", err)
+	}
+	return nil
+}
+
+func (w *Webhook) post(payload WebhookPayload) error {
+	payload.SentAt = w.now().UTC()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	_, err = w.http.post(w.url, body, func(h http.Header) {
+		ts := strconv.FormatInt(w.now().Unix(), 10)
+		h.Set(TimestampHeader, ts)
+		h.Set(SignatureHeader, Sign(w.secret, ts, body))
+	})
+	return err
+}
+
+// Sign returns the signature header value for body sent at timestamp (Unix
+// seconds). Receivers recompute it with their copy of the secret and compare
+// with hmac.Equal, rejecting stale timestamps to prevent replays.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
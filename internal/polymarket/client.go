@@ -7,11 +7,14 @@
 package polymarket
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +29,8 @@ type Client struct {
 	timeout        time.Duration
 	maxRetries     int
 	retryDelayBase time.Duration
+	onResponse     func(api string, status int)
+	onRetry        func(api string)
 }
 
 // PolymarketEvent represents an event from Polymarket Gamma API
@@ -67,6 +72,8 @@ type PolymarketMarket struct {
 	Volume        string  `json:"volume"`        // Total volume (string in API)
 	Volume1wk     float64 `json:"volume1wk"`     // 1-week volume (number in API)
 	Volume1mo     float64 `json:"volume1mo"`     // 1-month volume (number in API)
+	Closed        bool    `json:"closed"`
+	ClosedTime    string  `json:"closedTime"` // e.g. "2024-11-06 14:43:21+00"; empty while open
 }
 
 // ClientConfig holds optional configuration for the Polymarket client
@@ -76,6 +83,11 @@ type ClientConfig struct {
 	MaxIdleConns        int
 	MaxIdleConnsPerHost int
 	IdleConnTimeout     time.Duration
+	// OnResponse, when set, is called after every HTTP attempt with the API
+	// ("gamma" or "clob") and the status code, 0 when no response arrived.
+	OnResponse func(api string, status int)
+	// OnRetry, when set, is called each time a request is retried.
+	OnRetry func(api string)
 }
 
 // NewClient creates a new Polymarket client
@@ -85,6 +97,8 @@ func NewClient(gammaAPIURL, clobAPIURL string, timeout time.Duration, cfg ...Cli
 	var maxIdleConns = 100
 	var maxIdleConnsPerHost = 10
 	var idleConnTimeout = 90 * time.Second
+	var onResponse = func(string, int) {}
+	var onRetry = func(string) {}
 
 	if len(cfg) > 0 {
 		if cfg[0].MaxRetries > 0 {
@@ -102,6 +116,12 @@ func NewClient(gammaAPIURL, clobAPIURL string, timeout time.Duration, cfg ...Cli
 		if cfg[0].IdleConnTimeout > 0 {
 			idleConnTimeout = cfg[0].IdleConnTimeout
 		}
+		if cfg[0].OnResponse != nil {
+			onResponse = cfg[0].OnResponse
+		}
+		if cfg[0].OnRetry != nil {
+			onRetry = cfg[0].OnRetry
+		}
 	}
 
 	return &Client{
@@ -119,6 +139,8 @@ func NewClient(gammaAPIURL, clobAPIURL string, timeout time.Duration, cfg ...Cli
 		timeout:        timeout,
 		maxRetries:     maxRetries,
 		retryDelayBase: retryDelayBase,
+		onResponse:     onResponse,
+		onRetry:        onRetry,
 	}
 }
 
@@ -156,7 +178,7 @@ func (c *Client) FetchEvents(ctx context.Context, categories []string, vol24hrMi
 
 		u.RawQuery = q.Encode()
 
-		resp, err := c.doRequest(ctx, u.String())
+		resp, err := c.doRequest(ctx, http.MethodGet, u.String(), nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch events from %s: %w", u.String(), err)
 		}
@@ -240,6 +262,21 @@ func (c *Client) FetchEvents(ctx context.Context, categories []string, vol24hrMi
 				if err != nil {
 					continue // Skip invalid markets
 				}
+				yesTokenID := parseYesTokenID(market)
+				outcomes := parseOutcomes(market)
+
+				// Named-outcome markets (e.g. "Trump"/"Harris", "Over"/"Under") have no
+				// Yes/No prices: the first outcome stands in for Yes so market-level
+				// fields stay meaningful, and every outcome is tracked via Outcomes.
+				probe := models.Market{Outcomes: outcomes}
+				if !probe.IsYesNo() {
+					yesProb = outcomes[0].Price
+					noProb = 1 - yesProb
+					yesTokenID = outcomes[0].TokenID
+					if !anyPriced(outcomes) {
+						continue
+					}
+				}
 
 				// Skip markets with no valid probability data
 				if yesProb == 0 && noProb == 0 {
@@ -271,6 +308,7 @@ func (c *Client) FetchEvents(ctx context.Context, categories []string, vol24hrMi
 					EventID:        pe.ID,
 					MarketID:       market.ID,
 					MarketQuestion: market.Question,
+					ClobTokenID:    yesTokenID,
 					Title:          pe.Title,
 					EventURL:       "https://polymarket.com/event/" + pe.Slug,
 					Description:    pe.Description,
@@ -278,6 +316,7 @@ func (c *Client) FetchEvents(ctx context.Context, categories []string, vol24hrMi
 					Subcategory:    pe.Subcategory,
 					YesProbability: yesProb,
 					NoProbability:  noProb,
+					Outcomes:       outcomes,
 					Volume24hr:     marketVolume24hr,
 					Volume1wk:      marketVolume1wk,
 					Volume1mo:      marketVolume1mo,
@@ -347,17 +386,87 @@ func parseMarketProbabilities(market PolymarketMarket) (float64, float64, error)
 	return yesProb, noProb, nil
 }
 
+// parseYesTokenID returns the CLOB token ID of the "Yes" outcome, or "" when the
+// market has no token IDs or no Yes outcome. Token IDs are index-aligned with outcomes.
+func parseYesTokenID(market PolymarketMarket) string {
+	var outcomes, tokenIDs []string
+	if err := json.Unmarshal([]byte(market.Outcomes), &outcomes); err != nil {
+		return ""
+	}
+	if err := json.Unmarshal([]byte(market.ClobTokenIds), &tokenIDs); err != nil {
+		return ""
+	}
+	for i, outcome := range outcomes {
+		if outcome == "Yes" && i < len(tokenIDs) {
+			return tokenIDs[i]
+		}
+	}
+	return ""
+}
+
+// parseOutcomes returns every outcome of a market with its price and CLOB token
+// ID. Outcomes without a parseable price are dropped; token IDs are optional.
+// Returns nil when the outcome arrays cannot be decoded.
+func parseOutcomes(market PolymarketMarket) []models.Outcome {
+	var names, prices, tokenIDs []string
+	if err := json.Unmarshal([]byte(market.Outcomes), &names); err != nil {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(market.OutcomePrices), &prices); err != nil {
+		return nil
+	}
+	_ = json.Unmarshal([]byte(market.ClobTokenIds), &tokenIDs)
+
+	outcomes := make([]models.Outcome, 0, len(names))
+	for i, name := range names {
+		if i >= len(prices) || name == "" {
+			break
+		}
+		price, err := strconv.ParseFloat(prices[i], 64)
+		if err != nil || price < 0 || price > 1 {
+			continue
+		}
+		o := models.Outcome{Index: i, Name: name, Price: price}
+		if i < len(tokenIDs) {
+			o.TokenID = tokenIDs[i]
+		}
+		outcomes = append(outcomes, o)
+	}
+	if len(outcomes) == 0 {
+		return nil
+	}
+	return outcomes
+}
+
+// anyPriced reports whether at least one outcome has a non-zero price.
+func anyPriced(outcomes []models.Outcome) bool {
+	for _, o := range outcomes {
+		if o.Price > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // containsJSON checks if a content-type header indicates JSON
 func containsJSON(contentType string) bool {
 	return contentType == "application/json" ||
 		strings.HasPrefix(contentType, "application/json;")
 }
 
-// doRequest performs HTTP request with retry logic
-func (c *Client) doRequest(ctx context.Context, urlStr string) (*http.Response, error) {
+// doRequest performs HTTP request with retry logic.
+// body is sent as JSON when non-nil and is replayed on every attempt.
+func (c *Client) doRequest(ctx context.Context, method, urlStr string, body []byte) (*http.Response, error) {
 	var lastErr error
+	api := "clob"
+	if strings.HasPrefix(urlStr, c.gammaAPIURL) {
+		api = "gamma"
+	}
 
 	for i := 0; i < c.maxRetries; i++ {
+		if i > 0 {
+			c.onRetry(api)
+		}
 		// Check if context is cancelled before making request
 		select {
 		case <-ctx.Done():
@@ -365,15 +474,23 @@ func (c *Client) doRequest(ctx context.Context, urlStr string) (*http.Response,
 		default:
 		}
 
-		req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, urlStr, reqBody)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
 		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			c.onResponse(api, 0)
 			lastErr = err
 			// Exponential backoff with context check
 			select {
@@ -384,6 +501,8 @@ func (c *Client) doRequest(ctx context.Context, urlStr string) (*http.Response,
 			}
 		}
 
+		c.onResponse(api, resp.StatusCode)
+
 		// Handle various HTTP status codes
 		if resp.StatusCode >= 500 {
 			_ = resp.Body.Close()
@@ -3,10 +3,14 @@ package polymarket
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
 )
 
 func TestFetchEvents_RealAPIFormat(t *testing.T) {
@@ -553,3 +557,247 @@ func TestContainsJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderBookStats(t *testing.T) {
+	// Levels are deliberately unordered: the best bid/ask must be searched for.
+	book := OrderBook{
+		Bids: []OrderLevel{{Price: "0.40", Size: "100"}, {Price: "0.48", Size: "50"}, {Price: "0.45", Size: "10"}},
+		Asks: []OrderLevel{{Price: "0.60", Size: "100"}, {Price: "0.52", Size: "25"}},
+	}
+	stats, ok := book.Stats()
+	if !ok {
+		t.Fatal("Expected stats for a two-sided book")
+	}
+	if stats.BestBid != 0.48 || stats.BestAsk != 0.52 {
+		t.Errorf("Expected bid/ask 0.48/0.52, got %f/%f", stats.BestBid, stats.BestAsk)
+	}
+	if diff := stats.Spread - 0.04; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected spread 0.04, got %f", stats.Spread)
+	}
+	if diff := stats.Midpoint - 0.50; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected midpoint 0.50, got %f", stats.Midpoint)
+	}
+	if diff := stats.BidDepth - 24.0; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected bid depth 24.0, got %f", stats.BidDepth)
+	}
+
+	if _, ok := (OrderBook{Bids: book.Bids}).Stats(); ok {
+		t.Error("Expected ok=false for a one-sided book")
+	}
+	crossed := OrderBook{Bids: []OrderLevel{{Price: "0.55", Size: "1"}}, Asks: []OrderLevel{{Price: "0.50", Size: "1"}}}
+	if _, ok := crossed.Stats(); ok {
+		t.Error("Expected ok=false for a crossed book")
+	}
+}
+
+func TestEnrichWithOrderBooks(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/books" {
+			t.Errorf("Expected POST /books, got %s %s", r.Method, r.URL.Path)
+		}
+		var params []map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if len(params) != 2 {
+			t.Errorf("Expected 2 token IDs in batch, got %d", len(params))
+		}
+
+		books := []OrderBook{
+			{
+				AssetID: "token-tight",
+				Bids:    []OrderLevel{{Price: "0.49", Size: "1000"}},
+				Asks:    []OrderLevel{{Price: "0.51", Size: "1000"}},
+			},
+			{
+				AssetID: "token-wide",
+				Bids:    []OrderLevel{{Price: "0.40", Size: "10"}},
+				Asks:    []OrderLevel{{Price: "0.60", Size: "10"}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(books); err != nil {
+			t.Errorf("Failed to encode books: %v", err)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := NewClient("https://gamma-api.polymarket.com", mockServer.URL, 30*time.Second)
+	markets := []models.Market{
+		{ID: "e:tight", ClobTokenID: "token-tight"},
+		{ID: "e:wide", ClobTokenID: "token-wide"},
+		{ID: "e:none"},
+	}
+
+	enriched, err := client.EnrichWithOrderBooks(context.Background(), markets)
+	if err != nil {
+		t.Fatalf("EnrichWithOrderBooks failed: %v", err)
+	}
+	if enriched != 2 {
+		t.Errorf("Expected 2 enriched markets, got %d", enriched)
+	}
+	if markets[0].Spread < 0.0199 || markets[0].Spread > 0.0201 {
+		t.Errorf("Expected tight spread 0.02, got %f", markets[0].Spread)
+	}
+	if markets[1].Spread < 0.1999 || markets[1].Spread > 0.2001 {
+		t.Errorf("Expected wide spread 0.20, got %f", markets[1].Spread)
+	}
+	if markets[2].Spread != 0 || markets[2].BestBid != 0 {
+		t.Errorf("Expected market without token to be untouched, got %+v", markets[2])
+	}
+}
+
+func TestParseYesTokenID(t *testing.T) {
+	market := PolymarketMarket{
+		Outcomes:     "[\"No\", \"Yes\"]",
+		ClobTokenIds: "[\"token-no\", \"token-yes\"]",
+	}
+	if got := parseYesTokenID(market); got != "token-yes" {
+		t.Errorf("Expected token-yes, got %q", got)
+	}
+	if got := parseYesTokenID(PolymarketMarket{Outcomes: "[\"Yes\", \"No\"]"}); got != "" {
+		t.Errorf("Expected empty token ID when clobTokenIds is missing, got %q", got)
+	}
+}
+
+func TestFetchPriceHistory(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/prices-history" || q.Get("market") != "token-1" {
+			t.Errorf("Unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		if q.Get("startTs") != "1700000000" || q.Get("endTs") != "1700003600" || q.Get("fidelity") != "15" {
+			t.Errorf("Unexpected range parameters: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"history":[{"t":1700000000,"p":0.41},{"t":1700000900,"p":0.43}]}`))
+	}))
+	defer mockServer.Close()
+
+	client := NewClient("https://gamma-api.polymarket.com", mockServer.URL, 30*time.Second)
+	points, err := client.FetchPriceHistory(context.Background(), "token-1",
+		time.Unix(1700000000, 0), time.Unix(1700003600, 0), 15*time.Minute)
+	if err != nil {
+		t.Fatalf("FetchPriceHistory failed: %v", err)
+	}
+	if len(points) != 2 || points[1].P != 0.43 {
+		t.Errorf("Unexpected points: %+v", points)
+	}
+}
+
+func TestDoRequest_ReportsResponsesAndRetries(t *testing.T) {
+	calls := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"history":[]}`))
+	}))
+	defer mockServer.Close()
+
+	var responses []string
+	retries := 0
+	client := NewClient("https://gamma-api.polymarket.com", mockServer.URL, 30*time.Second, ClientConfig{
+		RetryDelayBase: time.Millisecond,
+		OnResponse:     func(api string, status int) { responses = append(responses, fmt.Sprintf("%s:%d", api, status)) },
+		OnRetry:        func(api string) { retries++ },
+	})
+	if _, err := client.FetchPriceHistory(context.Background(), "token-1", time.Unix(0, 0), time.Unix(60, 0), time.Minute); err != nil {
+		t.Fatalf("FetchPriceHistory failed: %v", err)
+	}
+	if strings.Join(responses, ",") != "clob:502,clob:200" || retries != 1 {
+		t.Errorf("got responses %v and %d retries; want clob:502,clob:200 and 1", responses, retries)
+	}
+}
+
+func TestFetchEvents_NamedOutcomeMarket(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events := []PolymarketEvent{
+			{
+				ID:     "event-1",
+				Title:  "Presidential Election Winner",
+				Active: true,
+				Markets: []PolymarketMarket{
+					{
+						ID:            "market-1",
+						Question:      "Who will win?",
+						Outcomes:      "[\"Trump\", \"Harris\"]",
+						OutcomePrices: "[\"0.58\", \"0.42\"]",
+						ClobTokenIds:  "[\"token-trump\", \"token-harris\"]",
+					},
+				},
+				Tags: []PolymarketTag{{ID: "1", Label: "Politics", Slug: "politics"}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(events); err != nil {
+			t.Errorf("Failed to encode events: %v", err)
+		}
+	}))
+	defer mockServer.Close()
+
+	client := NewClient(mockServer.URL, "https://clob.polymarket.com", 30*time.Second)
+	events, err := client.FetchEvents(context.Background(), []string{"politics"}, 0, 0, 0, true, 10)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected named-outcome market to be kept, got %d markets", len(events))
+	}
+
+	m := events[0]
+	if m.IsYesNo() {
+		t.Error("Expected market to be classified as named-outcome")
+	}
+	if len(m.Outcomes) != 2 || m.Outcomes[1].Name != "Harris" || m.Outcomes[1].Price != 0.42 || m.Outcomes[1].TokenID != "token-harris" {
+		t.Errorf("Unexpected outcomes: %+v", m.Outcomes)
+	}
+	if m.YesProbability != 0.58 || m.ClobTokenID != "token-trump" {
+		t.Errorf("Expected first outcome to stand in for Yes, got yes=%.2f token=%q", m.YesProbability, m.ClobTokenID)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("Expected valid market, got %v", err)
+	}
+}
+
+func TestParseOutcomes(t *testing.T) {
+	market := PolymarketMarket{
+		Outcomes:      "[\"Over\", \"Under\", \"Push\"]",
+		OutcomePrices: "[\"0.55\", \"bad\", \"0.05\"]",
+	}
+	got := parseOutcomes(market)
+	if len(got) != 2 {
+		t.Fatalf("Expected unparseable price to be dropped, got %+v", got)
+	}
+	if got[1].Name != "Push" || got[1].Index != 2 || got[1].TokenID != "" {
+		t.Errorf("Expected index-aligned outcome without token, got %+v", got[1])
+	}
+	if parseOutcomes(PolymarketMarket{Outcomes: "not json", OutcomePrices: "[]"}) != nil {
+		t.Error("Expected nil for undecodable outcomes")
+	}
+}
+
+func TestWinningOutcome(t *testing.T) {
+	tests := []struct {
+		name   string
+		market PolymarketMarket
+		want   string
+		wantOK bool
+	}{
+		{"open market", PolymarketMarket{Outcomes: `["Yes","No"]`, OutcomePrices: `["1","0"]`}, "", false},
+		{"resolved No", PolymarketMarket{Closed: true, Outcomes: `["Yes","No"]`, OutcomePrices: `["0","1"]`}, "No", true},
+		{"named outcome", PolymarketMarket{Closed: true, Outcomes: `["Trump","Harris"]`, OutcomePrices: `["0.9995","0.0005"]`}, "Trump", true},
+		{"disputed", PolymarketMarket{Closed: true, Outcomes: `["Yes","No"]`, OutcomePrices: `["0.5","0.5"]`}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.market.WinningOutcome()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("WinningOutcome() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
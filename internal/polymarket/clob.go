@@ -0,0 +1,207 @@
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// bookBatchSize is the number of token IDs requested per POST /books call.
+const bookBatchSize = 100
+
+// OrderBook represents a CLOB order book summary for a single outcome token
+type OrderBook struct {
+	Market  string       `json:"market"`   // Condition ID
+	AssetID string       `json:"asset_id"` // CLOB token ID
+	Bids    []OrderLevel `json:"bids"`
+	Asks    []OrderLevel `json:"asks"`
+}
+
+// OrderLevel represents one price level of an order book (strings in API)
+type OrderLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// BookStats holds top-of-book statistics derived from an OrderBook.
+// Depth is the USD notional (price × size) resting at the best level.
+type BookStats struct {
+	BestBid  float64
+	BestAsk  float64
+	Spread   float64
+	Midpoint float64
+	BidDepth float64
+	AskDepth float64
+}
+
+// Stats computes best bid/ask, spread, midpoint and top-of-book depth.
+// The API does not guarantee level ordering, so the best levels are searched.
+// Returns ok=false when either side of the book is empty or the book is crossed.
+func (b OrderBook) Stats() (BookStats, bool) {
+	bidPrice, bidSize, okBid := bestLevel(b.Bids, func(p, best float64) bool { return p > best })
+	askPrice, askSize, okAsk := bestLevel(b.Asks, func(p, best float64) bool { return p < best })
+	if !okBid || !okAsk || askPrice < bidPrice {
+		return BookStats{}, false
+	}
+	return BookStats{
+		BestBid:  bidPrice,
+		BestAsk:  askPrice,
+		Spread:   askPrice - bidPrice,
+		Midpoint: (bidPrice + askPrice) / 2,
+		BidDepth: bidPrice * bidSize,
+		AskDepth: askPrice * askSize,
+	}, true
+}
+
+// bestLevel returns the price and size of the level preferred by better.
+// Unparseable levels are skipped.
+func bestLevel(levels []OrderLevel, better func(p, best float64) bool) (float64, float64, bool) {
+	var bestPrice, bestSize float64
+	found := false
+	for _, lvl := range levels {
+		price, err := strconv.ParseFloat(lvl.Price, 64)
+		if err != nil {
+			continue
+		}
+		size, err := strconv.ParseFloat(lvl.Size, 64)
+		if err != nil {
+			continue
+		}
+		if !found || better(price, bestPrice) {
+			bestPrice, bestSize, found = price, size, true
+		}
+	}
+	return bestPrice, bestSize, found
+}
+
+// FetchOrderBooks retrieves CLOB order books for the given token IDs using the
+// batch POST /books endpoint. Returns books keyed by token ID; tokens without a
+// book are absent from the map.
+func (c *Client) FetchOrderBooks(ctx context.Context, tokenIDs []string) (map[string]OrderBook, error) {
+	books := make(map[string]OrderBook, len(tokenIDs))
+
+	for start := 0; start < len(tokenIDs); start += bookBatchSize {
+		end := start + bookBatchSize
+		if end > len(tokenIDs) {
+			end = len(tokenIDs)
+		}
+
+		params := make([]map[string]string, 0, end-start)
+		for _, id := range tokenIDs[start:end] {
+			params = append(params, map[string]string{"token_id": id})
+		}
+		body, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode book request: %w", err)
+		}
+
+		urlStr := c.clobAPIURL + "/books"
+		resp, err := c.doRequest(ctx, http.MethodPost, urlStr, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch order books from %s: %w", urlStr, err)
+		}
+
+		var page []OrderBook
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode order books JSON: %w", err)
+		}
+		_ = resp.Body.Close()
+
+		for _, book := range page {
+			books[book.AssetID] = book
+		}
+	}
+
+	return books, nil
+}
+
+// EnrichWithOrderBooks fetches the order book of each market's CLOB token (the
+// Yes token, or the first outcome's token for named-outcome markets) and fills in best bid/ask, spread, midpoint and top-of-book depth. Markets without a CLOB
+// token or without a two-sided book are left untouched.
+// Returns the number of markets enriched.
+func (c *Client) EnrichWithOrderBooks(ctx context.Context, markets []models.Market) (int, error) {
+	var tokenIDs []string
+	for i := range markets {
+		if markets[i].ClobTokenID != "" {
+			tokenIDs = append(tokenIDs, markets[i].ClobTokenID)
+		}
+	}
+	if len(tokenIDs) == 0 {
+		return 0, nil
+	}
+
+	books, err := c.FetchOrderBooks(ctx, tokenIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	enriched := 0
+	for i := range markets {
+		m := &markets[i]
+		book, ok := books[m.ClobTokenID]
+		if !ok {
+			continue
+		}
+		stats, ok := book.Stats()
+		if !ok {
+			continue
+		}
+		m.BestBid = stats.BestBid
+		m.BestAsk = stats.BestAsk
+		m.Spread = stats.Spread
+		m.Midpoint = stats.Midpoint
+		m.BidDepth = stats.BidDepth
+		m.AskDepth = stats.AskDepth
+		enriched++
+	}
+	return enriched, nil
+}
+
+// PricePoint is one sample from the CLOB prices-history endpoint
+type PricePoint struct {
+	T int64   `json:"t"` // Unix seconds
+	P float64 `json:"p"` // Price (probability) of the token
+}
+
+// FetchPriceHistory retrieves historical prices for a CLOB token between start
+// and end, sampled every fidelity (rounded down to whole minutes, minimum 1m).
+// Points are returned in the order the API provides (ascending by time).
+func (c *Client) FetchPriceHistory(ctx context.Context, tokenID string, start, end time.Time, fidelity time.Duration) ([]PricePoint, error) {
+	u, err := url.Parse(c.clobAPIURL + "/prices-history")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	minutes := int(fidelity / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	q := u.Query()
+	q.Set("market", tokenID)
+	q.Set("startTs", strconv.FormatInt(start.Unix(), 10))
+	q.Set("endTs", strconv.FormatInt(end.Unix(), 10))
+	q.Set("fidelity", strconv.Itoa(minutes))
+	u.RawQuery = q.Encode()
+
+	resp, err := c.doRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch price history from %s: %w", u.String(), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var payload struct {
+		History []PricePoint `json:"history"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode price history JSON: %w", err)
+	}
+	return payload.History, nil
+}
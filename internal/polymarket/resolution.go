@@ -0,0 +1,76 @@
+package polymarket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// resolvedPriceThreshold is the minimum final price for an outcome to count as
+// the winner. Resolved markets settle at exactly 1/0, but the Gamma API can
+// briefly report prices like 0.9995 while payouts are being finalised.
+const resolvedPriceThreshold = 0.99
+
+// closedTimeLayouts are the formats the Gamma API has used for closedTime.
+var closedTimeLayouts = []string{
+	"2006-01-02 15:04:05-07",
+	"2006-01-02 15:04:05Z07:00",
+	time.RFC3339,
+}
+
+// FetchMarket retrieves a single market from the Gamma API by its Polymarket
+// market ID, regardless of whether it is still active.
+func (c *Client) FetchMarket(ctx context.Context, marketID string) (*PolymarketMarket, error) {
+	urlStr := c.gammaAPIURL + "/markets/" + url.PathEscape(marketID)
+	resp, err := c.doRequest(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch market from %s: %w", urlStr, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var market PolymarketMarket
+	if err := json.NewDecoder(resp.Body).Decode(&market); err != nil {
+		return nil, fmt.Errorf("failed to decode market JSON: %w", err)
+	}
+	return &market, nil
+}
+
+// WinningOutcome returns the name of the outcome a closed market resolved to.
+// Returns ok=false while the market is open or when no outcome has settled at
+// (or near) 1.0, e.g. during a resolution dispute.
+func (m PolymarketMarket) WinningOutcome() (string, bool) {
+	if !m.Closed {
+		return "", false
+	}
+	var names, prices []string
+	if err := json.Unmarshal([]byte(m.Outcomes), &names); err != nil {
+		return "", false
+	}
+	if err := json.Unmarshal([]byte(m.OutcomePrices), &prices); err != nil {
+		return "", false
+	}
+	for i, name := range names {
+		if i >= len(prices) {
+			break
+		}
+		price, err := strconv.ParseFloat(prices[i], 64)
+		if err == nil && price >= resolvedPriceThreshold {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ClosedAt parses ClosedTime. Returns ok=false when it is empty or unparseable.
+func (m PolymarketMarket) ClosedAt() (time.Time, bool) {
+	for _, layout := range closedTimeLayouts {
+		if t, err := time.Parse(layout, m.ClosedTime); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,112 @@
+// Package resolution detects when tracked markets close and records how they
+// resolved, so alerts can be judged against the eventual outcome.
+//
+// FetchEvents only returns active, open markets, so a market that closes simply
+// stops appearing. Each cycle the Tracker looks up markets that were not seen
+// in the latest fetch; closed markets with a settled winner get a Resolution,
+// while markets that merely dropped out of the top-K (or are still disputed)
+// are rechecked no more often than RecheckInterval.
+package resolution
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/polymarket"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+// Config controls how often disappeared markets are looked up.
+type Config struct {
+	RecheckInterval time.Duration // minimum time between lookups of the same market
+	MaxPerCycle     int           // maximum markets looked up per call to Run
+}
+
+// Result summarises one Run.
+type Result struct {
+	Checked  int // markets looked up
+	Resolved int // resolutions recorded
+	Failed   int // lookups that failed and will be retried
+}
+
+// Tracker records resolutions for markets that left the active feed.
+type Tracker struct {
+	client *polymarket.Client
+	store  *storage.Storage
+	cfg    Config
+}
+
+// New creates a Tracker.
+func New(client *polymarket.Client, store *storage.Storage, cfg Config) *Tracker {
+	return &Tracker{client: client, store: store, cfg: cfg}
+}
+
+// Run checks markets not seen since seenBefore (typically the cycle time).
+// Per-market failures are logged and counted, not returned. Returns an error
+// only when the candidate set cannot be read or ctx is cancelled.
+func (t *Tracker) Run(ctx context.Context, seenBefore time.Time) (Result, error) {
+	var result Result
+
+	now := time.Now()
+	pending, err := t.store.MarketsPendingResolution(seenBefore, now.Add(-t.cfg.RecheckInterval), t.cfg.MaxPerCycle)
+	if err != nil {
+		return result, err
+	}
+
+	for _, market := range pending {
+		if ctx.Err() != nil {
+			return result, fmt.Errorf("resolution check cancelled: %w", ctx.Err())
+		}
+		result.Checked++
+
+		resolved, err := t.check(ctx, market, now)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, fmt.Errorf("resolution check cancelled: %w", ctx.Err())
+			}
+			logger.FromContext(ctx).With(logger.MarketIDKey, market.ID).Warn("Resolution check failed for market %s: %v", market.ID, err)
+			result.Failed++
+			continue
+		}
+		if resolved {
+			result.Resolved++
+		}
+	}
+	return result, nil
+}
+
+// check looks up one market and records its resolution if it has settled.
+func (t *Tracker) check(ctx context.Context, market *models.Market, now time.Time) (bool, error) {
+	pm, err := t.client.FetchMarket(ctx, market.MarketID)
+	if err != nil {
+		return false, err
+	}
+	if err := t.store.MarkResolutionChecked(market.ID, now); err != nil {
+		return false, err
+	}
+
+	winner, ok := pm.WinningOutcome()
+	if !ok {
+		return false, nil
+	}
+	resolvedAt, ok := pm.ClosedAt()
+	if !ok {
+		resolvedAt = now
+	}
+
+	res := &models.Resolution{
+		MarketID:       market.ID,
+		EventID:        market.EventID,
+		WinningOutcome: winner,
+		ResolvedAt:     resolvedAt,
+		RecordedAt:     now,
+	}
+	if err := t.store.AddResolution(res); err != nil {
+		return false, err
+	}
+	logger.FromContext(ctx).With(logger.MarketIDKey, market.ID).Info("Market %s resolved: %s", market.ID, winner)
+	return true, nil
+}
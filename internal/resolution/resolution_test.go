@@ -0,0 +1,152 @@
+package resolution
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/polymarket"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+func mustStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	s, err := storage.New(100, 100, ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func addMarket(t *testing.T, s *storage.Storage, marketID string, lastSeen time.Time) {
+	t.Helper()
+	m := &models.Market{
+		ID:             "event:" + marketID,
+		EventID:        "event",
+		MarketID:       marketID,
+		Title:          "Test",
+		Category:       "test",
+		YesProbability: 0.5,
+		NoProbability:  0.5,
+		Active:         true,
+		LastUpdated:    lastSeen,
+		CreatedAt:      lastSeen.Add(-time.Hour),
+	}
+	if err := s.AddMarket(m); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+}
+
+// gammaServer serves GET /markets/{id} from markets and counts requests.
+func gammaServer(t *testing.T, markets map[string]polymarket.PolymarketMarket, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		id := strings.TrimPrefix(r.URL.Path, "/markets/")
+		m, ok := markets[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(m)
+	}))
+}
+
+func TestTracker_RecordsResolutionsAndLedger(t *testing.T) {
+	store := mustStorage(t)
+	cycle := time.Now().Add(-time.Minute)
+	addMarket(t, store, "won", cycle.Add(-time.Hour))
+	addMarket(t, store, "open", cycle.Add(-time.Hour))
+	addMarket(t, store, "seen", cycle.Add(time.Second)) // returned this cycle: not checked
+
+	var requests atomic.Int32
+	server := gammaServer(t, map[string]polymarket.PolymarketMarket{
+		"won":  {ID: "won", Closed: true, ClosedTime: "2026-01-02 15:04:05+00", Outcomes: `["Yes","No"]`, OutcomePrices: `["1","0"]`},
+		"open": {ID: "open", Outcomes: `["Yes","No"]`, OutcomePrices: `["0.4","0.6"]`},
+	}, &requests)
+	defer server.Close()
+
+	// An alert that called the move correctly, and one that did not
+	for _, c := range []models.Change{
+		{ID: "up", EventID: "event:won", Direction: "increase", Magnitude: 0.1, OldProbability: 0.5, NewProbability: 0.6, TimeWindow: time.Hour, DetectedAt: cycle.Add(-2 * time.Hour), Notified: true},
+		{ID: "down", EventID: "event:won", Direction: "decrease", Magnitude: 0.1, OldProbability: 0.6, NewProbability: 0.5, TimeWindow: time.Hour, DetectedAt: cycle.Add(-3 * time.Hour), Notified: true},
+		{ID: "unsent", EventID: "event:won", Direction: "increase", Magnitude: 0.1, OldProbability: 0.5, NewProbability: 0.6, TimeWindow: time.Hour, DetectedAt: cycle.Add(-time.Hour)},
+	} {
+		if err := store.AddChange(&c); err != nil {
+			t.Fatalf("AddChange: %v", err)
+		}
+	}
+
+	client := polymarket.NewClient(server.URL, "", 5*time.Second)
+	tracker := New(client, store, Config{RecheckInterval: time.Hour, MaxPerCycle: 10})
+
+	result, err := tracker.Run(context.Background(), cycle)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Checked != 2 || result.Resolved != 1 || result.Failed != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	res, err := store.GetResolution("event:won")
+	if err != nil {
+		t.Fatalf("GetResolution: %v", err)
+	}
+	if res.WinningOutcome != "Yes" || res.ResolvedAt.Year() != 2026 {
+		t.Errorf("Unexpected resolution: %+v", res)
+	}
+	m, _ := store.GetMarket("event:won")
+	if !m.Closed {
+		t.Error("Expected resolved market to be marked closed")
+	}
+
+	ledger, err := store.GetLedger(10)
+	if err != nil {
+		t.Fatalf("GetLedger: %v", err)
+	}
+	if len(ledger) != 2 {
+		t.Fatalf("Expected 2 notified changes in ledger, got %d", len(ledger))
+	}
+	if ledger[0].Change.ID != "up" || !ledger[0].Correct() {
+		t.Errorf("Expected newest alert 'up' to be correct, got %+v", ledger[0])
+	}
+	if ledger[1].Change.ID != "down" || ledger[1].Correct() {
+		t.Errorf("Expected alert 'down' to be incorrect, got %+v", ledger[1])
+	}
+
+	// The open market is not rechecked within RecheckInterval
+	requests.Store(0)
+	if _, err := tracker.Run(context.Background(), cycle); err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("Expected no lookups within recheck interval, got %d", n)
+	}
+}
+
+func TestTracker_CountsFailures(t *testing.T) {
+	store := mustStorage(t)
+	cycle := time.Now()
+	addMarket(t, store, "missing", cycle.Add(-time.Hour))
+
+	var requests atomic.Int32
+	server := gammaServer(t, nil, &requests)
+	defer server.Close()
+
+	client := polymarket.NewClient(server.URL, "", 5*time.Second, polymarket.ClientConfig{MaxRetries: 1})
+	result, err := New(client, store, Config{RecheckInterval: time.Hour, MaxPerCycle: 10}).Run(context.Background(), cycle)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Failed != 1 || result.Resolved != 0 {
+		t.Errorf("Expected 1 failed lookup, got %+v", result)
+	}
+}
@@ -0,0 +1,80 @@
+// Package status tracks the state of the monitoring loop — when cycles ran, how
+// long they took and whether they failed — for operator-facing surfaces such as
+// bot commands and health checks.
+package status
+
+import (
+	"sync"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of the monitoring loop state.
+type Snapshot struct {
+	StartedAt           time.Time // service start
+	Cycles              int       // completed cycles, successful or not
+	LastCycleStart      time.Time // zero until the first cycle finishes
+	LastCycleDuration   time.Duration
+	LastSuccess         time.Time // end of the last successful cycle
+	LastError           string    // error of the last cycle; "" if it succeeded
+	ConsecutiveFailures int
+	NextRun             time.Time // zero when unknown
+	LastFetchMarkets    int       // markets returned by the latest fetch
+	EmptyFetches        int       // consecutive fetches that returned no markets
+}
+
+// Tracker records cycle results. It is safe for concurrent use.
+type Tracker struct {
+	mu sync.RWMutex
+	s  Snapshot
+}
+
+// New creates a Tracker for a service started at startedAt.
+func New(startedAt time.Time) *Tracker {
+	return &Tracker{s: Snapshot{StartedAt: startedAt}}
+}
+
+// CycleFinished records a cycle that ran from start to end with result err and
+// returns the number of consecutive failures before this cycle.
+func (t *Tracker) CycleFinished(start, end time.Time, err error) (previousFailures int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	previousFailures = t.s.ConsecutiveFailures
+	t.s.Cycles++
+	t.s.LastCycleStart = start
+	t.s.LastCycleDuration = end.Sub(start)
+	if err != nil {
+		t.s.LastError = err.Error()
+		t.s.ConsecutiveFailures++
+	} else {
+		t.s.LastError = ""
+		t.s.LastSuccess = end
+		t.s.ConsecutiveFailures = 0
+	}
+	return previousFailures
+}
+
+// FetchFinished records how many markets a fetch returned.
+func (t *Tracker) FetchFinished(markets int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.s.LastFetchMarkets = markets
+	if markets == 0 {
+		t.s.EmptyFetches++
+	} else {
+		t.s.EmptyFetches = 0
+	}
+}
+
+// SetNextRun records when the next cycle is scheduled.
+func (t *Tracker) SetNextRun(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.s.NextRun = at
+}
+
+// Snapshot returns a copy of the current state.
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.s
+}
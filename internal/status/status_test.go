@@ -0,0 +1,48 @@
+package status
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTracker_CycleFinished(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr := New(start)
+
+	if prev := tr.CycleFinished(start, start.Add(2*time.Second), errors.New("boom")); prev != 0 {
+		t.Errorf("first failure: previous = %d, want 0", prev)
+	}
+	if prev := tr.CycleFinished(start, start.Add(time.Second), errors.New("boom")); prev != 1 {
+		t.Errorf("second failure: previous = %d, want 1", prev)
+	}
+	s := tr.Snapshot()
+	if s.ConsecutiveFailures != 2 || s.LastError != "boom" || !s.LastSuccess.IsZero() {
+		t.Errorf("unexpected state after failures: %+v", s)
+	}
+
+	end := start.Add(3 * time.Second)
+	if prev := tr.CycleFinished(start, end, nil); prev != 2 {
+		t.Errorf("recovery: previous = %d, want 2", prev)
+	}
+	s = tr.Snapshot()
+	if s.ConsecutiveFailures != 0 || s.LastError != "" || !s.LastSuccess.Equal(end) || s.Cycles != 3 {
+		t.Errorf("unexpected state after recovery: %+v", s)
+	}
+	if s.LastCycleDuration != 3*time.Second {
+		t.Errorf("LastCycleDuration = %v, want 3s", s.LastCycleDuration)
+	}
+}
+
+func TestTracker_FetchFinished(t *testing.T) {
+	tr := New(time.Now())
+	tr.FetchFinished(0)
+	tr.FetchFinished(0)
+	if s := tr.Snapshot(); s.EmptyFetches != 2 || s.LastFetchMarkets != 0 {
+		t.Errorf("after two empty fetches: %+v", s)
+	}
+	tr.FetchFinished(40)
+	if s := tr.Snapshot(); s.EmptyFetches != 0 || s.LastFetchMarkets != 40 {
+		t.Errorf("a non-empty fetch should reset the count: %+v", s)
+	}
+}
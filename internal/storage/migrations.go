@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is one numbered schema change. Migrations run in order, each in its
+// own transaction together with the schema_version row that records it.
+//
+// Statements must be idempotent (IF NOT EXISTS, addColumn): databases created
+// before versioning have no schema_version rows but may already contain some of
+// the tables and columns below.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// migrations is the ordered schema history. Append new migrations; never edit
+// or renumber released ones.
+var migrations = []migration{
+	{1, "initial schema", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS markets (
+				id              TEXT PRIMARY KEY,
+				event_id        TEXT NOT NULL,
+				market_id       TEXT NOT NULL,
+				market_question TEXT,
+				title           TEXT NOT NULL,
+				event_url       TEXT,
+				description     TEXT,
+				category        TEXT NOT NULL,
+				subcategory     TEXT,
+				yes_prob        REAL NOT NULL,
+				no_prob         REAL NOT NULL,
+				volume_24hr     REAL,
+				volume_1wk      REAL,
+				volume_1mo      REAL,
+				liquidity       REAL,
+				active          INTEGER,
+				closed          INTEGER,
+				last_updated    INTEGER NOT NULL,
+				created_at      INTEGER NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS snapshots (
+				id        TEXT PRIMARY KEY,
+				market_id TEXT NOT NULL REFERENCES markets(id) ON DELETE CASCADE,
+				yes_prob  REAL NOT NULL,
+				no_prob   REAL NOT NULL,
+				timestamp INTEGER NOT NULL,
+				source    TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_snapshots_market_ts ON snapshots(market_id, timestamp)`,
+			`CREATE TABLE IF NOT EXISTS changes (
+				id                   TEXT PRIMARY KEY,
+				market_id            TEXT NOT NULL,
+				original_event_id    TEXT,
+				event_title          TEXT,
+				event_url            TEXT,
+				polymarket_market_id TEXT,
+				market_question      TEXT,
+				magnitude            REAL NOT NULL,
+				direction            TEXT NOT NULL,
+				old_prob             REAL NOT NULL,
+				new_prob             REAL NOT NULL,
+				time_window          INTEGER NOT NULL,
+				detected_at          INTEGER NOT NULL,
+				notified             INTEGER DEFAULT 0,
+				signal_score         REAL DEFAULT 0
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_changes_detected_at ON changes(detected_at)`,
+		)
+	}},
+	{2, "CLOB order book and backfill columns", func(tx *sql.Tx) error {
+		return addColumns(tx,
+			column{"markets", "clob_token_id", "TEXT DEFAULT ''"},
+			column{"markets", "best_bid", "REAL DEFAULT 0"},
+			column{"markets", "best_ask", "REAL DEFAULT 0"},
+			column{"markets", "spread", "REAL DEFAULT 0"},
+			column{"markets", "midpoint", "REAL DEFAULT 0"},
+			column{"markets", "bid_depth", "REAL DEFAULT 0"},
+			column{"markets", "ask_depth", "REAL DEFAULT 0"},
+			column{"markets", "backfilled_at", "INTEGER DEFAULT 0"},
+			column{"snapshots", "best_bid", "REAL DEFAULT 0"},
+			column{"snapshots", "best_ask", "REAL DEFAULT 0"},
+			column{"snapshots", "spread", "REAL DEFAULT 0"},
+			column{"snapshots", "midpoint", "REAL DEFAULT 0"},
+			column{"snapshots", "bid_depth", "REAL DEFAULT 0"},
+			column{"snapshots", "ask_depth", "REAL DEFAULT 0"},
+		)
+	}},
+	{3, "named outcomes", func(tx *sql.Tx) error {
+		if err := addColumns(tx,
+			column{"snapshots", "outcome", "TEXT DEFAULT ''"},
+			column{"changes", "outcome", "TEXT DEFAULT ''"},
+		); err != nil {
+			return err
+		}
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS outcomes (
+				market_id TEXT NOT NULL REFERENCES markets(id) ON DELETE CASCADE,
+				idx       INTEGER NOT NULL,
+				name      TEXT NOT NULL,
+				token_id  TEXT DEFAULT '',
+				price     REAL NOT NULL,
+				PRIMARY KEY (market_id, idx)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_snapshots_market_outcome_ts ON snapshots(market_id, outcome, timestamp)`,
+		)
+	}},
+	{4, "market resolutions", func(tx *sql.Tx) error {
+		if err := addColumns(tx, column{"markets", "resolution_checked_at", "INTEGER DEFAULT 0"}); err != nil {
+			return err
+		}
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS resolutions (
+				market_id       TEXT PRIMARY KEY,
+				event_id        TEXT NOT NULL,
+				winning_outcome TEXT NOT NULL,
+				resolved_at     INTEGER NOT NULL,
+				recorded_at     INTEGER NOT NULL
+			)`,
+		)
+	}},
+	{5, "alert history", func(tx *sql.Tx) error {
+		if err := addColumns(tx, column{"changes", "passed_threshold", "INTEGER DEFAULT 0"}); err != nil {
+			return err
+		}
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS deliveries (
+				change_id   TEXT NOT NULL REFERENCES changes(id) ON DELETE CASCADE,
+				destination TEXT NOT NULL,
+				message_id  TEXT,
+				sent_at     INTEGER NOT NULL,
+				PRIMARY KEY (change_id, destination)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_changes_market_detected_at ON changes(market_id, detected_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_changes_event_detected_at ON changes(original_event_id, detected_at)`,
+		)
+	}},
+	{6, "notification cooldowns", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS notified_markets (
+				key       TEXT PRIMARY KEY,
+				direction TEXT NOT NULL,
+				new_prob  REAL NOT NULL,
+				sent_at   INTEGER NOT NULL
+			)`,
+		)
+	}},
+	{7, "telegram subscriptions", func(tx *sql.Tx) error {
+		if err := addColumns(tx, column{"changes", "category", "TEXT DEFAULT ''"}); err != nil {
+			return err
+		}
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS subscriptions (
+				chat_id    INTEGER PRIMARY KEY,
+				categories TEXT NOT NULL DEFAULT '[]',
+				event_ids  TEXT NOT NULL DEFAULT '[]',
+				min_score  REAL NOT NULL DEFAULT 0,
+				top_k      INTEGER NOT NULL DEFAULT 0,
+				created_at INTEGER NOT NULL,
+				updated_at INTEGER NOT NULL
+			)`,
+		)
+	}},
+	{8, "watchlist and mutes", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS watches (
+				kind       TEXT NOT NULL,
+				target     TEXT NOT NULL,
+				threshold  REAL NOT NULL DEFAULT 0,
+				created_at INTEGER NOT NULL,
+				PRIMARY KEY (kind, target)
+			)`,
+			`CREATE TABLE IF NOT EXISTS mutes (
+				kind       TEXT NOT NULL,
+				target     TEXT NOT NULL,
+				until      INTEGER NOT NULL,
+				created_at INTEGER NOT NULL,
+				PRIMARY KEY (kind, target)
+			)`,
+		)
+	}},
+	{9, "score breakdown", func(tx *sql.Tx) error {
+		return addColumns(tx, column{"changes", "score_breakdown", "TEXT NOT NULL DEFAULT ''"})
+	}},
+	{10, "telegram alert threads", func(tx *sql.Tx) error {
+		return execAll(tx,
+			`CREATE TABLE IF NOT EXISTS alert_threads (
+				chat_id    INTEGER NOT NULL,
+				market_key TEXT NOT NULL,
+				message_id INTEGER NOT NULL,
+				direction  TEXT NOT NULL,
+				start_prob REAL NOT NULL,
+				last_prob  REAL NOT NULL,
+				started_at INTEGER NOT NULL,
+				updated_at INTEGER NOT NULL,
+				PRIMARY KEY (chat_id, market_key)
+			)`,
+		)
+	}},
+}
+
+// LatestSchemaVersion is the schema version this build migrates databases to.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// migrate brings the database up to LatestSchemaVersion. It refuses to touch a
+// database whose schema is newer than this build understands.
+func (s *Storage) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if latest := LatestSchemaVersion(); current > latest {
+		return fmt.Errorf("database schema version %d is newer than supported version %d; upgrade polyoracle", current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := s.applyMigration(m); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Storage) applyMigration(m migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?,?,?)`,
+		m.version, m.name, time.Now().UnixNano()); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	return tx.Commit()
+}
+
+// SchemaVersion returns the highest migration applied to the database (0 for
+// a database created before versioned migrations).
+func (s *Storage) SchemaVersion() (int, error) {
+	var version int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+func execAll(tx *sql.Tx, stmts ...string) error {
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// column describes a column added to an existing table.
+type column struct {
+	table, name, decl string
+}
+
+// addColumns adds each column to its table unless it already exists.
+func addColumns(tx *sql.Tx, cols ...column) error {
+	for _, col := range cols {
+		exists, err := hasColumn(tx, col.table, col.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, col.table, col.name, col.decl)); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", col.table, col.name, err)
+		}
+	}
+	return nil
+}
+
+func hasColumn(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
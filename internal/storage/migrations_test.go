@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// baselineSchema is the schema written by releases before versioned migrations.
+var baselineSchema = []string{
+	`CREATE TABLE markets (
+		id TEXT PRIMARY KEY, event_id TEXT NOT NULL, market_id TEXT NOT NULL,
+		market_question TEXT, title TEXT NOT NULL, event_url TEXT, description TEXT,
+		category TEXT NOT NULL, subcategory TEXT, yes_prob REAL NOT NULL, no_prob REAL NOT NULL,
+		volume_24hr REAL, volume_1wk REAL, volume_1mo REAL, liquidity REAL,
+		active INTEGER, closed INTEGER, last_updated INTEGER NOT NULL, created_at INTEGER NOT NULL)`,
+	`CREATE TABLE snapshots (
+		id TEXT PRIMARY KEY, market_id TEXT NOT NULL REFERENCES markets(id) ON DELETE CASCADE,
+		yes_prob REAL NOT NULL, no_prob REAL NOT NULL, timestamp INTEGER NOT NULL, source TEXT NOT NULL)`,
+	`CREATE INDEX idx_snapshots_market_ts ON snapshots(market_id, timestamp)`,
+	`CREATE TABLE changes (
+		id TEXT PRIMARY KEY, market_id TEXT NOT NULL, original_event_id TEXT, event_title TEXT,
+		event_url TEXT, polymarket_market_id TEXT, market_question TEXT, magnitude REAL NOT NULL,
+		direction TEXT NOT NULL, old_prob REAL NOT NULL, new_prob REAL NOT NULL,
+		time_window INTEGER NOT NULL, detected_at INTEGER NOT NULL,
+		notified INTEGER DEFAULT 0, signal_score REAL DEFAULT 0)`,
+	`CREATE INDEX idx_changes_detected_at ON changes(detected_at)`,
+}
+
+func createBaselineDB(t *testing.T) string {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "baseline.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	for _, stmt := range baselineSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("create baseline schema: %v", err)
+		}
+	}
+	now := time.Now().Add(-time.Minute).UnixNano()
+	if _, err := db.Exec(`INSERT INTO markets VALUES ('e:m','e','m','Q?','Title','','','politics','',0.6,0.4,1,2,3,4,1,0,?,?)`, now, now); err != nil {
+		t.Fatalf("insert market: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO snapshots VALUES ('s1','e:m',0.6,0.4,?,'polymarket-gamma-api')`, now); err != nil {
+		t.Fatalf("insert snapshot: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO changes VALUES ('c1','e:m','e','Title','','m','Q?',0.1,'increase',0.5,0.6,3600000000000,?,0,0.2)`, now); err != nil {
+		t.Fatalf("insert change: %v", err)
+	}
+	return dbPath
+}
+
+func TestMigrate_UpgradesBaselineSchema(t *testing.T) {
+	dbPath := createBaselineDB(t)
+
+	s, err := New(10, 10, dbPath)
+	if err != nil {
+		t.Fatalf("New on baseline database: %v", err)
+	}
+	defer s.Close()
+
+	if v, err := s.SchemaVersion(); err != nil || v != LatestSchemaVersion() {
+		t.Fatalf("SchemaVersion() = %d, %v; want %d", v, err, LatestSchemaVersion())
+	}
+
+	// Existing rows survive and read back through the current queries
+	m, err := s.GetMarket("e:m")
+	if err != nil {
+		t.Fatalf("GetMarket: %v", err)
+	}
+	if m.YesProbability != 0.6 || m.ClobTokenID != "" || m.Spread != 0 {
+		t.Errorf("unexpected upgraded market: %+v", m)
+	}
+	snaps, err := s.GetSnapshots("e:m")
+	if err != nil || len(snaps) != 1 || snaps[0].Outcome != "" {
+		t.Errorf("expected baseline snapshot in primary series, got %+v (err %v)", snaps, err)
+	}
+	history, err := s.QueryChanges(ChangeFilter{MarketID: "e:m"})
+	if err != nil || len(history) != 1 || history[0].PassedThreshold {
+		t.Errorf("expected baseline change in history, got %+v (err %v)", history, err)
+	}
+
+	// New tables and columns are usable
+	snap := &models.Snapshot{ID: "s2", EventID: "e:m", Outcome: "Yes", YesProbability: 0.7, NoProbability: 0.3, Spread: 0.02, Timestamp: time.Now(), Source: "test"}
+	if err := s.AddSnapshot(snap); err != nil {
+		t.Errorf("AddSnapshot after upgrade: %v", err)
+	}
+	if err := s.RecordDelivery([]string{"c1"}, "telegram:1", "7", time.Now()); err != nil {
+		t.Errorf("RecordDelivery after upgrade: %v", err)
+	}
+	if err := s.SaveCooldowns([]CooldownRecord{{Key: "e:m", Direction: "increase", NewProb: 0.6, SentAt: time.Now()}}); err != nil {
+		t.Errorf("SaveCooldowns after upgrade: %v", err)
+	}
+}
+
+func TestMigrate_ReopenIsNoOp(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "data.db")
+	for i := 0; i < 2; i++ {
+		s, err := New(10, 10, dbPath)
+		if err != nil {
+			t.Fatalf("open %d: %v", i, err)
+		}
+		var rows int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&rows); err != nil {
+			t.Fatalf("count schema_version: %v", err)
+		}
+		if rows != len(migrations) {
+			t.Errorf("open %d: expected %d schema_version rows, got %d", i, len(migrations), rows)
+		}
+		_ = s.Close()
+	}
+}
+
+func TestMigrate_RefusesNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "data.db")
+	s, err := New(10, 10, dbPath)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'from the future', 0)`, LatestSchemaVersion()+1); err != nil {
+		t.Fatalf("insert future version: %v", err)
+	}
+	_ = s.Close()
+
+	if _, err := New(10, 10, dbPath); err == nil || !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("expected newer schema to be refused, got %v", err)
+	}
+}
+
+func TestMigrate_FailedMigrationRollsBack(t *testing.T) {
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	migrations = append(append([]migration{}, saved...), migration{
+		version: LatestSchemaVersion() + 1,
+		name:    "broken",
+		up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`CREATE TABLE half_done (id TEXT)`); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	})
+
+	dbPath := filepath.Join(t.TempDir(), "data.db")
+	if _, err := New(10, 10, dbPath); err == nil {
+		t.Fatal("expected migration failure")
+	}
+
+	migrations = saved
+	s, err := New(10, 10, dbPath)
+	if err != nil {
+		t.Fatalf("reopen with good migrations: %v", err)
+	}
+	defer s.Close()
+	if v, _ := s.SchemaVersion(); v != LatestSchemaVersion() {
+		t.Errorf("expected version %d after rollback, got %d", LatestSchemaVersion(), v)
+	}
+	var n int
+	_ = s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'`).Scan(&n)
+	if n != 0 {
+		t.Error("expected failed migration's table to be rolled back")
+	}
+}
@@ -1,12 +1,16 @@
-// Package storage provides SQLite-backed persistence for markets, snapshots, and changes.
+// Package storage provides SQLite-backed persistence for markets, snapshots, changes and resolutions.
 // It uses modernc.org/sqlite (pure Go, no CGO) with WAL mode for concurrent reads.
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/rewired-gh/polyoracle/internal/models"
@@ -18,6 +22,7 @@ type Storage struct {
 	db                   *sql.DB
 	maxMarkets           int
 	maxSnapshotsPerEvent int
+	pendingMaxAge        time.Duration // see KeepPendingResolution
 }
 
 // New opens (or creates) the SQLite database at dbPath.
@@ -42,332 +47,1519 @@ func New(maxMarkets, maxSnapshotsPerEvent int, dbPath string) (*Storage, error)
 		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
 	}
 	s := &Storage{db: db, maxMarkets: maxMarkets, maxSnapshotsPerEvent: maxSnapshotsPerEvent}
-	if err := s.createTables(); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
+	if err := s.migrate(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 	return s, nil
 }
 
+// Ping checks that the database answers a query.
+func (s *Storage) Ping(ctx context.Context) error {
+	var one int
+	if err := s.db.QueryRowContext(ctx, `SELECT 1`).Scan(&one); err != nil {
+		return fmt.Errorf("failed to reach database: %w", err)
+	}
+	return nil
+}
+
 // Close closes the underlying database connection.
 func (s *Storage) Close() error {
 	return s.db.Close()
 }
 
-// Save is a no-op: SQLite writes are immediate.
-func (s *Storage) Save() error { return nil }
+// Save is a no-op: SQLite writes are immediate.
+func (s *Storage) Save() error { return nil }
+
+// Load is a no-op: SQLite data is always present on open.
+func (s *Storage) Load() error { return nil }
+
+// --- Markets ---
+
+func (s *Storage) AddMarket(market *models.Market) error {
+	if err := market.Validate(); err != nil {
+		return fmt.Errorf("invalid market: %w", err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	_, err = tx.Exec(`
+		INSERT INTO markets
+			(id, event_id, market_id, market_question, title, event_url, description,
+			 category, subcategory, yes_prob, no_prob, volume_24hr, volume_1wk, volume_1mo,
+			 liquidity, active, closed, last_updated, created_at,
+			 clob_token_id, best_bid, best_ask, spread, midpoint, bid_depth, ask_depth)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		market.ID, market.EventID, market.MarketID, market.MarketQuestion, market.Title,
+		market.EventURL, market.Description, market.Category, market.Subcategory,
+		market.YesProbability, market.NoProbability,
+		market.Volume24hr, market.Volume1wk, market.Volume1mo, market.Liquidity,
+		boolToInt(market.Active), boolToInt(market.Closed),
+		market.LastUpdated.UnixNano(), market.CreatedAt.UnixNano(),
+		market.ClobTokenID, market.BestBid, market.BestAsk, market.Spread, market.Midpoint,
+		market.BidDepth, market.AskDepth,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert market: %w", err)
+	}
+	if err := replaceOutcomes(tx, market); err != nil {
+		return err
+	}
+
+	// Evict oldest market(s) if we exceed the cap (cascades to snapshots).
+	if err := s.evictMarkets(tx); err != nil {
+		return fmt.Errorf("failed to enforce market cap: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) GetMarket(id string) (*models.Market, error) {
+	row := s.db.QueryRow(`SELECT `+marketCols+` FROM markets WHERE id = ?`, id)
+	m, err := scanMarket(row.Scan)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("market not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market: %w", err)
+	}
+	if err := s.attachOutcomes([]*models.Market{m}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *Storage) GetAllMarkets() ([]*models.Market, error) {
+	rows, err := s.db.Query(`SELECT ` + marketCols + ` FROM markets`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query markets: %w", err)
+	}
+	defer rows.Close()
+	var markets []*models.Market
+	for rows.Next() {
+		m, err := scanMarket(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan market: %w", err)
+		}
+		markets = append(markets, m)
+	}
+	if markets == nil {
+		markets = []*models.Market{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := s.attachAllOutcomes(markets); err != nil {
+		return nil, err
+	}
+	return markets, nil
+}
+
+func (s *Storage) UpdateMarket(market *models.Market) error {
+	if err := market.Validate(); err != nil {
+		return fmt.Errorf("invalid market: %w", err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.Exec(`
+		UPDATE markets SET
+			event_id=?, market_id=?, market_question=?, title=?, event_url=?, description=?,
+			category=?, subcategory=?, yes_prob=?, no_prob=?, volume_24hr=?, volume_1wk=?,
+			volume_1mo=?, liquidity=?, active=?, closed=?, last_updated=?, created_at=?,
+			clob_token_id=?, best_bid=?, best_ask=?, spread=?, midpoint=?, bid_depth=?, ask_depth=?
+		WHERE id=?`,
+		market.EventID, market.MarketID, market.MarketQuestion, market.Title,
+		market.EventURL, market.Description, market.Category, market.Subcategory,
+		market.YesProbability, market.NoProbability,
+		market.Volume24hr, market.Volume1wk, market.Volume1mo, market.Liquidity,
+		boolToInt(market.Active), boolToInt(market.Closed),
+		market.LastUpdated.UnixNano(), market.CreatedAt.UnixNano(),
+		market.ClobTokenID, market.BestBid, market.BestAsk, market.Spread, market.Midpoint,
+		market.BidDepth, market.AskDepth,
+		market.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update market: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("market not found: %s", market.ID)
+	}
+	if err := replaceOutcomes(tx, market); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceOutcomes overwrites the stored outcomes of market with market.Outcomes.
+func replaceOutcomes(tx *sql.Tx, market *models.Market) error {
+	if _, err := tx.Exec(`DELETE FROM outcomes WHERE market_id = ?`, market.ID); err != nil {
+		return fmt.Errorf("failed to clear outcomes: %w", err)
+	}
+	for _, o := range market.Outcomes {
+		if _, err := tx.Exec(`
+			INSERT INTO outcomes (market_id, idx, name, token_id, price) VALUES (?,?,?,?,?)`,
+			market.ID, o.Index, o.Name, o.TokenID, o.Price); err != nil {
+			return fmt.Errorf("failed to insert outcome: %w", err)
+		}
+	}
+	return nil
+}
+
+// --- Cycle ingestion ---
+
+// IngestResult summarises one IngestCycle call.
+type IngestResult struct {
+	New       int
+	Updated   int
+	Snapshots int
+	// Rejected maps the IDs of markets that failed validation (or whose
+	// snapshots did) to the reason. Nothing was written for them.
+	Rejected map[string]error
+}
+
+// IngestCycle writes one polling cycle in a single transaction: it upserts every
+// market, inserts the snapshots with their timestamp set to cycleTime, and
+// enforces the market cap once at the end. Markets seen for the first time get
+// CreatedAt = cycleTime and existing markets keep their stored CreatedAt; both
+// are written back into markets. Invalid markets are skipped and reported in
+// Rejected; any database error rolls back the whole cycle.
+func (s *Storage) IngestCycle(cycleTime time.Time, markets []models.Market, snapshots []models.Snapshot) (IngestResult, error) {
+	result := IngestResult{Rejected: make(map[string]error)}
+
+	for i := range snapshots {
+		snapshots[i].Timestamp = cycleTime
+		if err := snapshots[i].Validate(); err != nil {
+			result.Rejected[snapshots[i].EventID] = fmt.Errorf("invalid snapshot: %w", err)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return IngestResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	created, err := createdAtFor(tx, markets)
+	if err != nil {
+		return IngestResult{}, err
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO markets
+			(id, event_id, market_id, market_question, title, event_url, description,
+			 category, subcategory, yes_prob, no_prob, volume_24hr, volume_1wk, volume_1mo,
+			 liquidity, active, closed, last_updated, created_at,
+			 clob_token_id, best_bid, best_ask, spread, midpoint, bid_depth, ask_depth)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(id) DO UPDATE SET
+			event_id=excluded.event_id, market_id=excluded.market_id,
+			market_question=excluded.market_question, title=excluded.title,
+			event_url=excluded.event_url, description=excluded.description,
+			category=excluded.category, subcategory=excluded.subcategory,
+			yes_prob=excluded.yes_prob, no_prob=excluded.no_prob,
+			volume_24hr=excluded.volume_24hr, volume_1wk=excluded.volume_1wk,
+			volume_1mo=excluded.volume_1mo, liquidity=excluded.liquidity,
+			active=excluded.active, closed=excluded.closed, last_updated=excluded.last_updated,
+			clob_token_id=excluded.clob_token_id, best_bid=excluded.best_bid,
+			best_ask=excluded.best_ask, spread=excluded.spread, midpoint=excluded.midpoint,
+			bid_depth=excluded.bid_depth, ask_depth=excluded.ask_depth`)
+	if err != nil {
+		return IngestResult{}, fmt.Errorf("failed to prepare market upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := range markets {
+		market := &markets[i]
+		if _, ok := result.Rejected[market.ID]; ok {
+			continue
+		}
+		existing, seen := created[market.ID]
+		if seen {
+			market.CreatedAt = existing
+		} else {
+			market.CreatedAt = cycleTime
+		}
+		if err := market.Validate(); err != nil {
+			result.Rejected[market.ID] = fmt.Errorf("invalid market: %w", err)
+			continue
+		}
+		if _, err := stmt.Exec(
+			market.ID, market.EventID, market.MarketID, market.MarketQuestion, market.Title,
+			market.EventURL, market.Description, market.Category, market.Subcategory,
+			market.YesProbability, market.NoProbability,
+			market.Volume24hr, market.Volume1wk, market.Volume1mo, market.Liquidity,
+			boolToInt(market.Active), boolToInt(market.Closed),
+			market.LastUpdated.UnixNano(), market.CreatedAt.UnixNano(),
+			market.ClobTokenID, market.BestBid, market.BestAsk, market.Spread, market.Midpoint,
+			market.BidDepth, market.AskDepth,
+		); err != nil {
+			return IngestResult{}, fmt.Errorf("failed to upsert market %s: %w", market.ID, err)
+		}
+		if err := replaceOutcomes(tx, market); err != nil {
+			return IngestResult{}, err
+		}
+		if seen {
+			result.Updated++
+		} else {
+			result.New++
+		}
+		created[market.ID] = market.CreatedAt
+	}
+
+	snapStmt, err := tx.Prepare(`
+		INSERT INTO snapshots
+			(id, market_id, yes_prob, no_prob, timestamp, source,
+			 best_bid, best_ask, spread, midpoint, bid_depth, ask_depth, outcome)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return IngestResult{}, fmt.Errorf("failed to prepare snapshot insert: %w", err)
+	}
+	defer snapStmt.Close()
+
+	for _, snap := range snapshots {
+		if _, ok := result.Rejected[snap.EventID]; ok {
+			continue
+		}
+		if _, ok := created[snap.EventID]; !ok {
+			result.Rejected[snap.EventID] = fmt.Errorf("market not found: %s", snap.EventID)
+			continue
+		}
+		if _, err := snapStmt.Exec(
+			snap.ID, snap.EventID, snap.YesProbability, snap.NoProbability,
+			snap.Timestamp.UnixNano(), snap.Source,
+			snap.BestBid, snap.BestAsk, snap.Spread, snap.Midpoint, snap.BidDepth, snap.AskDepth,
+			snap.Outcome,
+		); err != nil {
+			return IngestResult{}, fmt.Errorf("failed to insert snapshot %s: %w", snap.ID, err)
+		}
+		result.Snapshots++
+	}
+
+	// Evict oldest market(s) once for the whole cycle (cascades to snapshots).
+	if err := s.evictMarkets(tx); err != nil {
+		return IngestResult{}, fmt.Errorf("failed to enforce market cap: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return IngestResult{}, fmt.Errorf("failed to commit cycle: %w", err)
+	}
+	return result, nil
+}
+
+// createdAtFor returns the stored CreatedAt of those markets that already exist.
+func createdAtFor(tx *sql.Tx, markets []models.Market) (map[string]time.Time, error) {
+	ids := make([]string, len(markets))
+	for i := range markets {
+		ids[i] = markets[i].ID
+	}
+	encoded, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode market IDs: %w", err)
+	}
+	rows, err := tx.Query(`
+		SELECT id, created_at FROM markets
+		WHERE id IN (SELECT value FROM json_each(?))`, string(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query existing markets: %w", err)
+	}
+	defer rows.Close()
+	created := make(map[string]time.Time, len(markets))
+	for rows.Next() {
+		var id string
+		var createdAt int64
+		if err := rows.Scan(&id, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan market: %w", err)
+		}
+		created[id] = time.Unix(0, createdAt)
+	}
+	return created, rows.Err()
+}
+
+// attachOutcomes loads the outcomes of the given markets in a single query.
+func (s *Storage) attachOutcomes(markets []*models.Market) error {
+	if len(markets) == 0 {
+		return nil
+	}
+	marketIDs := make([]string, len(markets))
+	for i, m := range markets {
+		marketIDs[i] = m.ID
+	}
+	ids, err := json.Marshal(marketIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode market IDs: %w", err)
+	}
+	return s.loadOutcomes(markets, `
+		SELECT market_id, idx, name, token_id, price FROM outcomes
+		WHERE market_id IN (SELECT value FROM json_each(?))
+		ORDER BY market_id, idx`, string(ids))
+}
+
+// attachAllOutcomes is attachOutcomes for a markets slice holding every
+// tracked market: it reads the whole outcomes table instead of filtering it.
+func (s *Storage) attachAllOutcomes(markets []*models.Market) error {
+	if len(markets) == 0 {
+		return nil
+	}
+	return s.loadOutcomes(markets, `SELECT market_id, idx, name, token_id, price FROM outcomes ORDER BY market_id, idx`)
+}
+
+// loadOutcomes runs an outcomes query and appends each row to its market.
+// Rows of markets not in the slice are ignored.
+func (s *Storage) loadOutcomes(markets []*models.Market, query string, args ...any) error {
+	byID := make(map[string]*models.Market, len(markets))
+	for _, m := range markets {
+		byID[m.ID] = m
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query outcomes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var marketID string
+		var o models.Outcome
+		if err := rows.Scan(&marketID, &o.Index, &o.Name, &o.TokenID, &o.Price); err != nil {
+			return fmt.Errorf("failed to scan outcome: %w", err)
+		}
+		if m, ok := byID[marketID]; ok {
+			m.Outcomes = append(m.Outcomes, o)
+		}
+	}
+	return rows.Err()
+}
+
+// CountMarkets returns the number of tracked markets.
+func (s *Storage) CountMarkets() (int, error) {
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM markets`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count markets: %w", err)
+	}
+	return n, nil
+}
+
+// CountSnapshots returns the number of stored snapshots.
+func (s *Storage) CountSnapshots() (int, error) {
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM snapshots`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count snapshots: %w", err)
+	}
+	return n, nil
+}
+
+// DBSize returns the size of the database in bytes (page count × page size,
+// excluding the WAL file).
+func (s *Storage) DBSize() (int64, error) {
+	var pages, pageSize int64
+	if err := s.db.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := s.db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// SearchMarkets returns up to limit markets whose event title or market
+// question contains query (case-insensitive for ASCII), most liquid first.
+func (s *Storage) SearchMarkets(query string, limit int) ([]*models.Market, error) {
+	return s.QueryMarkets(MarketFilter{Search: query, Limit: limit})
+}
+
+// MarketFilter selects a page of tracked markets. Zero values disable a filter.
+type MarketFilter struct {
+	Category  string  // category, case-insensitive for ASCII
+	MinVolume float64 // minimum 24h volume in USD
+	Search    string  // substring of the event title or market question
+	EventID   string  // parent Polymarket event ID
+	Limit     int     // page size; <= 0 means 100
+	Offset    int     // rows to skip
+}
+
+// QueryMarkets returns a page of tracked markets matching f, with their
+// outcomes, most liquid first.
+func (s *Storage) QueryMarkets(f MarketFilter) ([]*models.Market, error) {
+	query := `SELECT ` + marketCols + ` FROM markets WHERE 1=1`
+	var args []any
+	if f.Category != "" {
+		query += ` AND category = ? COLLATE NOCASE`
+		args = append(args, f.Category)
+	}
+	if f.MinVolume > 0 {
+		query += ` AND volume_24hr >= ?`
+		args = append(args, f.MinVolume)
+	}
+	if f.Search != "" {
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(f.Search) + "%"
+		query += ` AND (title LIKE ? ESCAPE '\' OR market_question LIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern)
+	}
+	if f.EventID != "" {
+		query += ` AND event_id = ?`
+		args = append(args, f.EventID)
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query += ` ORDER BY volume_24hr DESC, id LIMIT ? OFFSET ?`
+	args = append(args, limit, f.Offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query markets: %w", err)
+	}
+	defer rows.Close()
+	markets := []*models.Market{}
+	for rows.Next() {
+		m, err := scanMarket(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan market: %w", err)
+		}
+		markets = append(markets, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := s.attachOutcomes(markets); err != nil {
+		return nil, err
+	}
+	return markets, nil
+}
+
+// MarketsPendingBackfill returns up to limit markets that have a CLOB token but
+// have not yet had their price history backfilled, oldest first.
+func (s *Storage) MarketsPendingBackfill(limit int) ([]*models.Market, error) {
+	rows, err := s.db.Query(`
+		SELECT `+marketCols+` FROM markets
+		WHERE backfilled_at = 0 AND clob_token_id != ''
+		ORDER BY created_at ASC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query markets pending backfill: %w", err)
+	}
+	defer rows.Close()
+	markets := []*models.Market{}
+	for rows.Next() {
+		m, err := scanMarket(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan market: %w", err)
+		}
+		markets = append(markets, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := s.attachOutcomes(markets); err != nil {
+		return nil, err
+	}
+	return markets, nil
+}
+
+// MarkBackfilled records that a market's price history backfill completed at t.
+func (s *Storage) MarkBackfilled(marketID string, t time.Time) error {
+	if _, err := s.db.Exec(`UPDATE markets SET backfilled_at = ? WHERE id = ?`, t.UnixNano(), marketID); err != nil {
+		return fmt.Errorf("failed to mark market backfilled: %w", err)
+	}
+	return nil
+}
+
+// MarketsPendingResolution returns up to limit open markets that were not seen
+// since seenBefore (they dropped out of the active feed) and whose resolution
+// has not been checked since checkedBefore, least recently seen first.
+func (s *Storage) MarketsPendingResolution(seenBefore, checkedBefore time.Time, limit int) ([]*models.Market, error) {
+	rows, err := s.db.Query(`
+		SELECT `+marketCols+` FROM markets
+		WHERE closed = 0 AND last_updated < ? AND resolution_checked_at < ?
+		  AND id NOT IN (SELECT market_id FROM resolutions)
+		ORDER BY last_updated ASC LIMIT ?`,
+		seenBefore.UnixNano(), checkedBefore.UnixNano(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query markets pending resolution: %w", err)
+	}
+	defer rows.Close()
+	markets := []*models.Market{}
+	for rows.Next() {
+		m, err := scanMarket(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan market: %w", err)
+		}
+		markets = append(markets, m)
+	}
+	return markets, rows.Err()
+}
+
+// MarkResolutionChecked records that a market's resolution was looked up at t.
+func (s *Storage) MarkResolutionChecked(marketID string, t time.Time) error {
+	if _, err := s.db.Exec(`UPDATE markets SET resolution_checked_at = ? WHERE id = ?`, t.UnixNano(), marketID); err != nil {
+		return fmt.Errorf("failed to mark resolution checked: %w", err)
+	}
+	return nil
+}
+
+// --- Resolutions ---
+
+// AddResolution stores a market's final resolution and marks the market closed.
+// Recording the same market again overwrites the previous resolution.
+func (s *Storage) AddResolution(res *models.Resolution) error {
+	if err := res.Validate(); err != nil {
+		return fmt.Errorf("invalid resolution: %w", err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO resolutions (market_id, event_id, winning_outcome, resolved_at, recorded_at)
+		VALUES (?,?,?,?,?)`,
+		res.MarketID, res.EventID, res.WinningOutcome, res.ResolvedAt.UnixNano(), res.RecordedAt.UnixNano(),
+	); err != nil {
+		return fmt.Errorf("failed to insert resolution: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE markets SET closed = 1, active = 0 WHERE id = ?`, res.MarketID); err != nil {
+		return fmt.Errorf("failed to mark market closed: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetResolution returns the recorded resolution of a market.
+func (s *Storage) GetResolution(marketID string) (*models.Resolution, error) {
+	var r models.Resolution
+	var resolvedNano, recordedNano int64
+	err := s.db.QueryRow(`
+		SELECT market_id, event_id, winning_outcome, resolved_at, recorded_at
+		FROM resolutions WHERE market_id = ?`, marketID).Scan(
+		&r.MarketID, &r.EventID, &r.WinningOutcome, &resolvedNano, &recordedNano)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("resolution not found: %s", marketID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resolution: %w", err)
+	}
+	r.ResolvedAt = time.Unix(0, resolvedNano)
+	r.RecordedAt = time.Unix(0, recordedNano)
+	return &r, nil
+}
+
+// GetLedger returns up to limit notified changes whose market has resolved,
+// newest alert first, each paired with its resolution.
+func (s *Storage) GetLedger(limit int) ([]models.LedgerEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT `+prefixCols("c", changeCols)+`,
+		       r.market_id, r.event_id, r.winning_outcome, r.resolved_at, r.recorded_at
+		FROM changes c JOIN resolutions r ON r.market_id = c.market_id
+		WHERE c.notified = 1
+		ORDER BY c.detected_at DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.LedgerEntry{}
+	for rows.Next() {
+		var e models.LedgerEntry
+		var resolvedNano, recordedNano int64
+		c, err := scanChange(rows.Scan,
+			&e.Resolution.MarketID, &e.Resolution.EventID, &e.Resolution.WinningOutcome,
+			&resolvedNano, &recordedNano)
+		if err != nil {
+			return nil, err
+		}
+		e.Change = c
+		e.Resolution.ResolvedAt = time.Unix(0, resolvedNano)
+		e.Resolution.RecordedAt = time.Unix(0, recordedNano)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// --- Snapshots ---
+
+func (s *Storage) AddSnapshot(snapshot *models.Snapshot) error {
+	if err := snapshot.Validate(); err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM markets WHERE id = ?`, snapshot.EventID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to verify market: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("market not found: %s", snapshot.EventID)
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO snapshots
+			(id, market_id, yes_prob, no_prob, timestamp, source,
+			 best_bid, best_ask, spread, midpoint, bid_depth, ask_depth, outcome)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		snapshot.ID, snapshot.EventID,
+		snapshot.YesProbability, snapshot.NoProbability,
+		snapshot.Timestamp.UnixNano(), snapshot.Source,
+		snapshot.BestBid, snapshot.BestAsk, snapshot.Spread, snapshot.Midpoint,
+		snapshot.BidDepth, snapshot.AskDepth, snapshot.Outcome,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert snapshot: %w", err)
+	}
+	return nil
+}
+
+// AddSnapshots inserts a batch of snapshots in a single transaction. Rows whose
+// ID already exists are skipped, so replaying the same batch is idempotent.
+// Returns the number of rows actually inserted.
+func (s *Storage) AddSnapshots(snapshots []models.Snapshot) (int, error) {
+	for i := range snapshots {
+		if err := snapshots[i].Validate(); err != nil {
+			return 0, fmt.Errorf("invalid snapshot %s: %w", snapshots[i].ID, err)
+		}
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO snapshots
+			(id, market_id, yes_prob, no_prob, timestamp, source,
+			 best_bid, best_ask, spread, midpoint, bid_depth, ask_depth, outcome)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare snapshot insert: %w", err)
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, snap := range snapshots {
+		res, err := stmt.Exec(
+			snap.ID, snap.EventID, snap.YesProbability, snap.NoProbability,
+			snap.Timestamp.UnixNano(), snap.Source,
+			snap.BestBid, snap.BestAsk, snap.Spread, snap.Midpoint, snap.BidDepth, snap.AskDepth,
+			snap.Outcome,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert snapshot %s: %w", snap.ID, err)
+		}
+		n, _ := res.RowsAffected()
+		inserted += int(n)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit snapshots: %w", err)
+	}
+	return inserted, nil
+}
+
+// GetSnapshots returns the primary (Yes) series of a market, oldest first.
+func (s *Storage) GetSnapshots(marketID string) ([]models.Snapshot, error) {
+	return s.GetOutcomeSnapshots(marketID, "")
+}
+
+// GetSnapshotsInWindow returns the primary (Yes) series of a market within window.
+func (s *Storage) GetSnapshotsInWindow(marketID string, window time.Duration) ([]models.Snapshot, error) {
+	return s.GetOutcomeSnapshotsInWindow(marketID, "", window)
+}
+
+// GetOutcomeSnapshots returns the snapshot series of one outcome of a market,
+// oldest first. outcome "" selects the Yes series of a Yes/No market.
+func (s *Storage) GetOutcomeSnapshots(marketID, outcome string) ([]models.Snapshot, error) {
+	rows, err := s.db.Query(`
+		SELECT `+snapshotCols+`
+		FROM snapshots WHERE market_id = ? AND outcome = ? ORDER BY timestamp ASC`, marketID, outcome)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots: %w", err)
+	}
+	defer rows.Close()
+	return scanSnapshots(rows)
+}
+
+// GetOutcomeSnapshotsInWindow is GetOutcomeSnapshots restricted to the last window.
+func (s *Storage) GetOutcomeSnapshotsInWindow(marketID, outcome string, window time.Duration) ([]models.Snapshot, error) {
+	cutoff := time.Now().Add(-window).UnixNano()
+	rows, err := s.db.Query(`
+		SELECT `+snapshotCols+`
+		FROM snapshots WHERE market_id = ? AND outcome = ? AND timestamp >= ? ORDER BY timestamp ASC`,
+		marketID, outcome, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots in window: %w", err)
+	}
+	defer rows.Close()
+	return scanSnapshots(rows)
+}
+
+// GetSnapshotsInRange returns every snapshot series of a market taken at or
+// after since and before until, ordered by outcome then oldest first. Zero
+// times leave that end open.
+func (s *Storage) GetSnapshotsInRange(marketID string, since, until time.Time) ([]models.Snapshot, error) {
+	query := `SELECT ` + snapshotCols + ` FROM snapshots WHERE market_id = ?`
+	args := []any{marketID}
+	if !since.IsZero() {
+		query += ` AND timestamp >= ?`
+		args = append(args, since.UnixNano())
+	}
+	if !until.IsZero() {
+		query += ` AND timestamp < ?`
+		args = append(args, until.UnixNano())
+	}
+	rows, err := s.db.Query(query+` ORDER BY outcome, timestamp ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots in range: %w", err)
+	}
+	defer rows.Close()
+	return scanSnapshots(rows)
+}
+
+// SeriesKey identifies one snapshot series: a market and, for named-outcome
+// markets, one of its outcomes ("" for the Yes series of a Yes/No market).
+type SeriesKey struct {
+	MarketID string
+	Outcome  string
+}
+
+// GetSnapshotsForMarkets returns the full snapshot history of every series of
+// the given markets in a single query, grouped by series, oldest first.
+func (s *Storage) GetSnapshotsForMarkets(marketIDs []string) (map[SeriesKey][]models.Snapshot, error) {
+	return s.snapshotsForMarkets(marketIDs, 0)
+}
+
+// GetSnapshotsInWindowForMarkets is GetSnapshotsForMarkets restricted to the
+// last window.
+func (s *Storage) GetSnapshotsInWindowForMarkets(marketIDs []string, window time.Duration) (map[SeriesKey][]models.Snapshot, error) {
+	return s.snapshotsForMarkets(marketIDs, time.Now().Add(-window).UnixNano())
+}
+
+// snapshotsForMarkets loads snapshots at or after cutoff (UnixNano) for the given
+// markets. The ID list is passed as one JSON array parameter, so the query size
+// does not depend on the number of markets.
+func (s *Storage) snapshotsForMarkets(marketIDs []string, cutoff int64) (map[SeriesKey][]models.Snapshot, error) {
+	result := make(map[SeriesKey][]models.Snapshot)
+	if len(marketIDs) == 0 {
+		return result, nil
+	}
+	ids, err := json.Marshal(marketIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode market IDs: %w", err)
+	}
+	rows, err := s.db.Query(`
+		SELECT `+snapshotCols+`
+		FROM snapshots
+		WHERE market_id IN (SELECT value FROM json_each(?)) AND timestamp >= ?
+		ORDER BY market_id, timestamp ASC`, string(ids), cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snapshots for markets: %w", err)
+	}
+	defer rows.Close()
+	snapshots, err := scanSnapshots(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, snap := range snapshots {
+		key := SeriesKey{MarketID: snap.EventID, Outcome: snap.Outcome}
+		result[key] = append(result[key], snap)
+	}
+	return result, nil
+}
+
+// --- Changes ---
+
+// AddChange appends a change to the alert history.
+func (s *Storage) AddChange(change *models.Change) error {
+	if err := change.Validate(); err != nil {
+		return fmt.Errorf("invalid change: %w", err)
+	}
+	return insertChange(s.db, change)
+}
+
+// AddChanges appends a batch of changes to the alert history in one transaction.
+func (s *Storage) AddChanges(changes []models.Change) error {
+	for i := range changes {
+		if err := changes[i].Validate(); err != nil {
+			return fmt.Errorf("invalid change %s: %w", changes[i].ID, err)
+		}
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	for i := range changes {
+		if err := insertChange(tx, &changes[i]); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+	return nil
+}
+
+// PruneChanges deletes changes detected before cutoff, with their delivery
+// records, and returns how many changes were deleted.
+func (s *Storage) PruneChanges(cutoff time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM changes WHERE detected_at < ?`, cutoff.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune changes: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func insertChange(db execer, change *models.Change) error {
+	var breakdown string
+	if change.Breakdown != nil {
+		data, err := json.Marshal(change.Breakdown)
+		if err != nil {
+			return fmt.Errorf("failed to encode score breakdown: %w", err)
+		}
+		breakdown = string(data)
+	}
+	_, err := db.Exec(`
+		INSERT INTO changes
+			(id, market_id, original_event_id, event_title, event_url, polymarket_market_id,
+			 market_question, magnitude, direction, old_prob, new_prob, time_window,
+			 detected_at, notified, signal_score, outcome, passed_threshold, category, score_breakdown)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		change.ID, change.EventID, change.OriginalEventID, change.EventTitle, change.EventURL,
+		change.MarketID, change.MarketQuestion,
+		change.Magnitude, change.Direction, change.OldProbability, change.NewProbability,
+		change.TimeWindow.Nanoseconds(), change.DetectedAt.UnixNano(),
+		boolToInt(change.Notified), change.SignalScore, change.Outcome,
+		boolToInt(change.PassedThreshold), change.Category, breakdown,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert change: %w", err)
+	}
+	return nil
+}
+
+// GetChange returns the change with the given ID and its deliveries, or nil if
+// it is not in the alert history.
+func (s *Storage) GetChange(id string) (*models.Change, error) {
+	rows, err := s.db.Query(`SELECT `+changeCols+` FROM changes WHERE id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query change: %w", err)
+	}
+	changes, err := scanChanges(rows)
+	rows.Close()
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	if err := s.attachDeliveries(changes); err != nil {
+		return nil, err
+	}
+	return &changes[0], nil
+}
+
+// TopScoredChanges returns up to limit changes detected at or after since,
+// highest signal score first, whether or not they passed the alert threshold.
+func (s *Storage) TopScoredChanges(since time.Time, limit int) ([]models.Change, error) {
+	rows, err := s.db.Query(`
+		SELECT `+changeCols+` FROM changes
+		WHERE detected_at >= ?
+		ORDER BY signal_score DESC, detected_at DESC
+		LIMIT ?`, since.UnixNano(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top changes: %w", err)
+	}
+	defer rows.Close()
+	return scanChanges(rows)
+}
+
+func (s *Storage) GetTopChanges(k int) ([]models.Change, error) {
+	rows, err := s.db.Query(`SELECT `+changeCols+` FROM changes ORDER BY magnitude DESC LIMIT ?`, k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changes: %w", err)
+	}
+	defer rows.Close()
+	return scanChanges(rows)
+}
+
+// RecordDelivery records that the given changes were sent to destination as
+// messageID, and marks them notified.
+func (s *Storage) RecordDelivery(changeIDs []string, destination, messageID string, sentAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	for _, id := range changeIDs {
+		if _, err := tx.Exec(`
+			INSERT OR REPLACE INTO deliveries (change_id, destination, message_id, sent_at)
+			VALUES (?,?,?,?)`, id, destination, messageID, sentAt.UnixNano()); err != nil {
+			return fmt.Errorf("failed to record delivery: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE changes SET notified = 1 WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to mark change notified: %w", err)
+		}
+	}
+	return tx.Commit()
+}
 
-// Load is a no-op: SQLite data is always present on open.
-func (s *Storage) Load() error { return nil }
+// ChangeFilter selects a page of the alert history. Zero values disable a filter.
+type ChangeFilter struct {
+	Since    time.Time // detected at or after
+	Until    time.Time // detected before
+	MarketID string    // composite market ID (EventID:MarketID)
+	EventID  string    // parent Polymarket event ID
+	MinScore float64   // minimum composite signal score
+	Passed   *bool     // only changes that passed (true) or missed (false) the alert bar
+	Limit    int       // page size; <= 0 means 100
+	Offset   int       // rows to skip
+}
 
-func (s *Storage) createTables() error {
-	stmts := []string{
-		`CREATE TABLE IF NOT EXISTS markets (
-			id              TEXT PRIMARY KEY,
-			event_id        TEXT NOT NULL,
-			market_id       TEXT NOT NULL,
-			market_question TEXT,
-			title           TEXT NOT NULL,
-			event_url       TEXT,
-			description     TEXT,
-			category        TEXT NOT NULL,
-			subcategory     TEXT,
-			yes_prob        REAL NOT NULL,
-			no_prob         REAL NOT NULL,
-			volume_24hr     REAL,
-			volume_1wk      REAL,
-			volume_1mo      REAL,
-			liquidity       REAL,
-			active          INTEGER,
-			closed          INTEGER,
-			last_updated    INTEGER NOT NULL,
-			created_at      INTEGER NOT NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS snapshots (
-			id        TEXT PRIMARY KEY,
-			market_id TEXT NOT NULL REFERENCES markets(id) ON DELETE CASCADE,
-			yes_prob  REAL NOT NULL,
-			no_prob   REAL NOT NULL,
-			timestamp INTEGER NOT NULL,
-			source    TEXT NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_snapshots_market_ts ON snapshots(market_id, timestamp)`,
-		`CREATE TABLE IF NOT EXISTS changes (
-			id                   TEXT PRIMARY KEY,
-			market_id            TEXT NOT NULL,
-			original_event_id    TEXT,
-			event_title          TEXT,
-			event_url            TEXT,
-			polymarket_market_id TEXT,
-			market_question      TEXT,
-			magnitude            REAL NOT NULL,
-			direction            TEXT NOT NULL,
-			old_prob             REAL NOT NULL,
-			new_prob             REAL NOT NULL,
-			time_window          INTEGER NOT NULL,
-			detected_at          INTEGER NOT NULL,
-			notified             INTEGER DEFAULT 0,
-			signal_score         REAL DEFAULT 0
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_changes_detected_at ON changes(detected_at)`,
-	}
-	for _, stmt := range stmts {
-		if _, err := s.db.Exec(stmt); err != nil {
-			return err
+// QueryChanges returns a page of the alert history matching f, newest first,
+// with each change's deliveries populated.
+func (s *Storage) QueryChanges(f ChangeFilter) ([]models.Change, error) {
+	query := `SELECT ` + changeCols + ` FROM changes WHERE 1=1`
+	var args []any
+	if !f.Since.IsZero() {
+		query += ` AND detected_at >= ?`
+		args = append(args, f.Since.UnixNano())
+	}
+	if !f.Until.IsZero() {
+		query += ` AND detected_at < ?`
+		args = append(args, f.Until.UnixNano())
+	}
+	if f.MarketID != "" {
+		query += ` AND market_id = ?`
+		args = append(args, f.MarketID)
+	}
+	if f.EventID != "" {
+		query += ` AND original_event_id = ?`
+		args = append(args, f.EventID)
+	}
+	if f.MinScore > 0 {
+		query += ` AND signal_score >= ?`
+		args = append(args, f.MinScore)
+	}
+	if f.Passed != nil {
+		query += ` AND passed_threshold = ?`
+		args = append(args, boolToInt(*f.Passed))
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	query += ` ORDER BY detected_at DESC, id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, f.Offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query change history: %w", err)
+	}
+	changes, err := scanChanges(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []models.Change{}
+	}
+	if err := s.attachDeliveries(changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// attachDeliveries loads the deliveries of the given changes.
+func (s *Storage) attachDeliveries(changes []models.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	index := make(map[string]int, len(changes))
+	placeholders := make([]string, len(changes))
+	args := make([]any, len(changes))
+	for i, c := range changes {
+		index[c.ID] = i
+		placeholders[i] = "?"
+		args[i] = c.ID
+	}
+	rows, err := s.db.Query(`
+		SELECT change_id, destination, message_id, sent_at FROM deliveries
+		WHERE change_id IN (`+strings.Join(placeholders, ",")+`) ORDER BY sent_at`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query deliveries: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d models.Delivery
+		var sentNano int64
+		if err := rows.Scan(&d.ChangeID, &d.Destination, &d.MessageID, &sentNano); err != nil {
+			return fmt.Errorf("failed to scan delivery: %w", err)
 		}
+		d.SentAt = time.Unix(0, sentNano)
+		c := &changes[index[d.ChangeID]]
+		c.Deliveries = append(c.Deliveries, d)
 	}
-	return nil
+	return rows.Err()
 }
 
-// --- Markets ---
+// --- Cooldowns ---
 
-func (s *Storage) AddMarket(market *models.Market) error {
-	if err := market.Validate(); err != nil {
-		return fmt.Errorf("invalid market: %w", err)
-	}
+// CooldownRecord is the last notification sent for one price series, used to
+// suppress repeat alerts across restarts.
+type CooldownRecord struct {
+	Key       string // models.Change.Key()
+	Direction string
+	NewProb   float64
+	SentAt    time.Time
+}
+
+// SaveCooldowns upserts the given records.
+func (s *Storage) SaveCooldowns(records []CooldownRecord) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback() //nolint:errcheck
+	for _, r := range records {
+		if _, err := tx.Exec(`
+			INSERT OR REPLACE INTO notified_markets (key, direction, new_prob, sent_at)
+			VALUES (?,?,?,?)`, r.Key, r.Direction, r.NewProb, r.SentAt.UnixNano()); err != nil {
+			return fmt.Errorf("failed to save cooldown: %w", err)
+		}
+	}
+	return tx.Commit()
+}
 
-	_, err = tx.Exec(`
-		INSERT INTO markets
-			(id, event_id, market_id, market_question, title, event_url, description,
-			 category, subcategory, yes_prob, no_prob, volume_24hr, volume_1wk, volume_1mo,
-			 liquidity, active, closed, last_updated, created_at)
-		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
-		market.ID, market.EventID, market.MarketID, market.MarketQuestion, market.Title,
-		market.EventURL, market.Description, market.Category, market.Subcategory,
-		market.YesProbability, market.NoProbability,
-		market.Volume24hr, market.Volume1wk, market.Volume1mo, market.Liquidity,
-		boolToInt(market.Active), boolToInt(market.Closed),
-		market.LastUpdated.UnixNano(), market.CreatedAt.UnixNano(),
-	)
+// LoadCooldowns returns all stored cooldown records.
+func (s *Storage) LoadCooldowns() ([]CooldownRecord, error) {
+	rows, err := s.db.Query(`SELECT key, direction, new_prob, sent_at FROM notified_markets`)
 	if err != nil {
-		return fmt.Errorf("failed to insert market: %w", err)
+		return nil, fmt.Errorf("failed to query cooldowns: %w", err)
+	}
+	defer rows.Close()
+	var records []CooldownRecord
+	for rows.Next() {
+		var r CooldownRecord
+		var sentNano int64
+		if err := rows.Scan(&r.Key, &r.Direction, &r.NewProb, &sentNano); err != nil {
+			return nil, fmt.Errorf("failed to scan cooldown: %w", err)
+		}
+		r.SentAt = time.Unix(0, sentNano)
+		records = append(records, r)
 	}
+	return records, rows.Err()
+}
 
-	// Evict oldest market(s) if we exceed the cap (cascades to snapshots).
-	if _, err = tx.Exec(`
-		DELETE FROM markets WHERE id NOT IN (
-			SELECT id FROM markets ORDER BY last_updated DESC LIMIT ?
-		)`, s.maxMarkets); err != nil {
-		return fmt.Errorf("failed to enforce market cap: %w", err)
+// ExpireCooldowns deletes records sent before cutoff and returns how many were removed.
+func (s *Storage) ExpireCooldowns(cutoff time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM notified_markets WHERE sent_at < ?`, cutoff.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire cooldowns: %w", err)
 	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
 
-	return tx.Commit()
+// --- Subscriptions ---
+
+// SaveSubscription creates or replaces the subscription of sub.ChatID. CreatedAt
+// is kept from the stored row when one exists.
+func (s *Storage) SaveSubscription(sub *models.Subscription) error {
+	if err := sub.Validate(); err != nil {
+		return fmt.Errorf("invalid subscription: %w", err)
+	}
+	categories, err := json.Marshal(nonNil(sub.Categories))
+	if err != nil {
+		return fmt.Errorf("failed to encode categories: %w", err)
+	}
+	eventIDs, err := json.Marshal(nonNil(sub.EventIDs))
+	if err != nil {
+		return fmt.Errorf("failed to encode event IDs: %w", err)
+	}
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+	sub.UpdatedAt = time.Now()
+	_, err = s.db.Exec(`
+		INSERT INTO subscriptions (chat_id, categories, event_ids, min_score, top_k, created_at, updated_at)
+		VALUES (?,?,?,?,?,?,?)
+		ON CONFLICT(chat_id) DO UPDATE SET
+			categories=excluded.categories, event_ids=excluded.event_ids,
+			min_score=excluded.min_score, top_k=excluded.top_k, updated_at=excluded.updated_at`,
+		sub.ChatID, string(categories), string(eventIDs), sub.MinScore, sub.TopK,
+		sub.CreatedAt.UnixNano(), sub.UpdatedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
 }
 
-func (s *Storage) GetMarket(id string) (*models.Market, error) {
-	row := s.db.QueryRow(`SELECT `+marketCols+` FROM markets WHERE id = ?`, id)
-	m, err := scanMarket(row.Scan)
+// GetSubscription returns the subscription of chatID, or nil if the chat is not subscribed.
+func (s *Storage) GetSubscription(chatID int64) (*models.Subscription, error) {
+	row := s.db.QueryRow(`SELECT `+subscriptionCols+` FROM subscriptions WHERE chat_id = ?`, chatID)
+	sub, err := scanSubscription(row.Scan)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("market not found: %s", id)
+		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get market: %w", err)
+		return nil, err
 	}
-	return m, nil
+	return sub, nil
 }
 
-func (s *Storage) GetAllMarkets() ([]*models.Market, error) {
-	rows, err := s.db.Query(`SELECT ` + marketCols + ` FROM markets`)
+// ListSubscriptions returns all subscriptions ordered by chat ID.
+func (s *Storage) ListSubscriptions() ([]*models.Subscription, error) {
+	rows, err := s.db.Query(`SELECT ` + subscriptionCols + ` FROM subscriptions ORDER BY chat_id`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query markets: %w", err)
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
 	}
 	defer rows.Close()
-	var markets []*models.Market
+	var subs []*models.Subscription
 	for rows.Next() {
-		m, err := scanMarket(rows.Scan)
+		sub, err := scanSubscription(rows.Scan)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan market: %w", err)
+			return nil, err
 		}
-		markets = append(markets, m)
+		subs = append(subs, sub)
 	}
-	if markets == nil {
-		markets = []*models.Market{}
+	return subs, rows.Err()
+}
+
+// DeleteSubscription removes the subscription of chatID. Deleting a missing
+// subscription is not an error.
+func (s *Storage) DeleteSubscription(chatID int64) error {
+	if _, err := s.db.Exec(`DELETE FROM subscriptions WHERE chat_id = ?`, chatID); err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
-	return markets, rows.Err()
+	return nil
 }
 
-func (s *Storage) UpdateMarket(market *models.Market) error {
-	if err := market.Validate(); err != nil {
-		return fmt.Errorf("invalid market: %w", err)
+const subscriptionCols = `chat_id, categories, event_ids, min_score, top_k, created_at, updated_at`
+
+func scanSubscription(scan func(...any) error) (*models.Subscription, error) {
+	var sub models.Subscription
+	var categories, eventIDs string
+	var createdNano, updatedNano int64
+	err := scan(&sub.ChatID, &categories, &eventIDs, &sub.MinScore, &sub.TopK, &createdNano, &updatedNano)
+	if err == sql.ErrNoRows {
+		return nil, err
 	}
-	res, err := s.db.Exec(`
-		UPDATE markets SET
-			event_id=?, market_id=?, market_question=?, title=?, event_url=?, description=?,
-			category=?, subcategory=?, yes_prob=?, no_prob=?, volume_24hr=?, volume_1wk=?,
-			volume_1mo=?, liquidity=?, active=?, closed=?, last_updated=?, created_at=?
-		WHERE id=?`,
-		market.EventID, market.MarketID, market.MarketQuestion, market.Title,
-		market.EventURL, market.Description, market.Category, market.Subcategory,
-		market.YesProbability, market.NoProbability,
-		market.Volume24hr, market.Volume1wk, market.Volume1mo, market.Liquidity,
-		boolToInt(market.Active), boolToInt(market.Closed),
-		market.LastUpdated.UnixNano(), market.CreatedAt.UnixNano(),
-		market.ID,
-	)
 	if err != nil {
-		return fmt.Errorf("failed to update market: %w", err)
+		return nil, fmt.Errorf("failed to scan subscription: %w", err)
 	}
-	n, _ := res.RowsAffected()
-	if n == 0 {
-		return fmt.Errorf("market not found: %s", market.ID)
+	if err := json.Unmarshal([]byte(categories), &sub.Categories); err != nil {
+		return nil, fmt.Errorf("failed to decode categories: %w", err)
+	}
+	if err := json.Unmarshal([]byte(eventIDs), &sub.EventIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode event IDs: %w", err)
+	}
+	sub.CreatedAt = time.Unix(0, createdNano)
+	sub.UpdatedAt = time.Unix(0, updatedNano)
+	return &sub, nil
+}
+
+// nonNil returns s, or an empty slice when s is nil, so it encodes as [] not null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// --- Watchlist and mutes ---
+
+// SaveWatch creates or replaces the watch on (w.Kind, w.Target).
+func (s *Storage) SaveWatch(w *models.Watch) error {
+	if err := w.Validate(); err != nil {
+		return fmt.Errorf("invalid watch: %w", err)
+	}
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now()
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO watches (kind, target, threshold, created_at) VALUES (?,?,?,?)
+		ON CONFLICT(kind, target) DO UPDATE SET threshold=excluded.threshold`,
+		w.Kind, w.Target, w.Threshold, w.CreatedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to save watch: %w", err)
 	}
 	return nil
 }
 
-// --- Snapshots ---
+// ListWatches returns every watch, oldest first.
+func (s *Storage) ListWatches() ([]models.Watch, error) {
+	rows, err := s.db.Query(`SELECT kind, target, threshold, created_at FROM watches ORDER BY created_at, kind, target`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watches: %w", err)
+	}
+	defer rows.Close()
+	var watches []models.Watch
+	for rows.Next() {
+		var w models.Watch
+		var createdNano int64
+		if err := rows.Scan(&w.Kind, &w.Target, &w.Threshold, &createdNano); err != nil {
+			return nil, fmt.Errorf("failed to scan watch: %w", err)
+		}
+		w.CreatedAt = time.Unix(0, createdNano)
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
 
-func (s *Storage) AddSnapshot(snapshot *models.Snapshot) error {
-	if err := snapshot.Validate(); err != nil {
-		return fmt.Errorf("invalid snapshot: %w", err)
+// DeleteWatch removes the watch on (kind, target) and reports whether it existed.
+func (s *Storage) DeleteWatch(kind, target string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM watches WHERE kind = ? AND target = ?`, kind, target)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete watch: %w", err)
 	}
-	var count int
-	if err := s.db.QueryRow(`SELECT COUNT(*) FROM markets WHERE id = ?`, snapshot.EventID).Scan(&count); err != nil {
-		return fmt.Errorf("failed to verify market: %w", err)
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SaveMute creates or replaces the mute on (m.Kind, m.Target).
+func (s *Storage) SaveMute(m *models.Mute) error {
+	if err := m.Validate(); err != nil {
+		return fmt.Errorf("invalid mute: %w", err)
 	}
-	if count == 0 {
-		return fmt.Errorf("market not found: %s", snapshot.EventID)
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
 	}
 	_, err := s.db.Exec(`
-		INSERT INTO snapshots (id, market_id, yes_prob, no_prob, timestamp, source)
-		VALUES (?,?,?,?,?,?)`,
-		snapshot.ID, snapshot.EventID,
-		snapshot.YesProbability, snapshot.NoProbability,
-		snapshot.Timestamp.UnixNano(), snapshot.Source,
-	)
+		INSERT INTO mutes (kind, target, until, created_at) VALUES (?,?,?,?)
+		ON CONFLICT(kind, target) DO UPDATE SET until=excluded.until, created_at=excluded.created_at`,
+		m.Kind, m.Target, m.Until.UnixNano(), m.CreatedAt.UnixNano())
 	if err != nil {
-		return fmt.Errorf("failed to insert snapshot: %w", err)
+		return fmt.Errorf("failed to save mute: %w", err)
 	}
 	return nil
 }
 
-func (s *Storage) GetSnapshots(marketID string) ([]models.Snapshot, error) {
-	rows, err := s.db.Query(`
-		SELECT id, market_id, yes_prob, no_prob, timestamp, source
-		FROM snapshots WHERE market_id = ? ORDER BY timestamp ASC`, marketID)
+// ListMutes returns the mutes still active at now, ending soonest first.
+func (s *Storage) ListMutes(now time.Time) ([]models.Mute, error) {
+	rows, err := s.db.Query(`SELECT kind, target, until, created_at FROM mutes WHERE until > ? ORDER BY until, kind, target`,
+		now.UnixNano())
 	if err != nil {
-		return nil, fmt.Errorf("failed to query snapshots: %w", err)
+		return nil, fmt.Errorf("failed to query mutes: %w", err)
 	}
 	defer rows.Close()
-	return scanSnapshots(rows)
+	var mutes []models.Mute
+	for rows.Next() {
+		var m models.Mute
+		var untilNano, createdNano int64
+		if err := rows.Scan(&m.Kind, &m.Target, &untilNano, &createdNano); err != nil {
+			return nil, fmt.Errorf("failed to scan mute: %w", err)
+		}
+		m.Until = time.Unix(0, untilNano)
+		m.CreatedAt = time.Unix(0, createdNano)
+		mutes = append(mutes, m)
+	}
+	return mutes, rows.Err()
 }
 
-func (s *Storage) GetSnapshotsInWindow(marketID string, window time.Duration) ([]models.Snapshot, error) {
-	cutoff := time.Now().Add(-window).UnixNano()
-	rows, err := s.db.Query(`
-		SELECT id, market_id, yes_prob, no_prob, timestamp, source
-		FROM snapshots WHERE market_id = ? AND timestamp >= ? ORDER BY timestamp ASC`,
-		marketID, cutoff)
+// DeleteMute removes the mute on (kind, target) and reports whether it existed.
+func (s *Storage) DeleteMute(kind, target string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM mutes WHERE kind = ? AND target = ?`, kind, target)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query snapshots in window: %w", err)
+		return false, fmt.Errorf("failed to delete mute: %w", err)
 	}
-	defer rows.Close()
-	return scanSnapshots(rows)
+	n, _ := res.RowsAffected()
+	return n > 0, nil
 }
 
-// --- Changes ---
+// ExpireMutes deletes mutes that ended at or before now and returns how many were removed.
+func (s *Storage) ExpireMutes(now time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM mutes WHERE until <= ?`, now.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire mutes: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
 
-func (s *Storage) AddChange(change *models.Change) error {
-	if err := change.Validate(); err != nil {
-		return fmt.Errorf("invalid change: %w", err)
+// --- Alert threads ---
+
+// SaveAlertThreads creates or replaces the given threads in one transaction.
+func (s *Storage) SaveAlertThreads(threads []models.AlertThread) error {
+	for i := range threads {
+		if err := threads[i].Validate(); err != nil {
+			return fmt.Errorf("invalid alert thread %s: %w", threads[i].Key, err)
+		}
 	}
-	_, err := s.db.Exec(`
-		INSERT INTO changes
-			(id, market_id, original_event_id, event_title, event_url, polymarket_market_id,
-			 market_question, magnitude, direction, old_prob, new_prob, time_window,
-			 detected_at, notified, signal_score)
-		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
-		change.ID, change.EventID, change.OriginalEventID, change.EventTitle, change.EventURL,
-		change.MarketID, change.MarketQuestion,
-		change.Magnitude, change.Direction, change.OldProbability, change.NewProbability,
-		change.TimeWindow.Nanoseconds(), change.DetectedAt.UnixNano(),
-		boolToInt(change.Notified), change.SignalScore,
-	)
+	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to insert change: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	for _, t := range threads {
+		if _, err := tx.Exec(`
+			INSERT OR REPLACE INTO alert_threads
+				(chat_id, market_key, message_id, direction, start_prob, last_prob, started_at, updated_at)
+			VALUES (?,?,?,?,?,?,?,?)`,
+			t.ChatID, t.Key, t.MessageID, t.Direction, t.StartProb, t.LastProb,
+			t.StartedAt.UnixNano(), t.UpdatedAt.UnixNano()); err != nil {
+			return fmt.Errorf("failed to save alert thread: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit alert threads: %w", err)
 	}
 	return nil
 }
 
-func (s *Storage) GetTopChanges(k int) ([]models.Change, error) {
+// GetAlertThreads returns the threads of chatID for the given market keys,
+// keyed by market key. Keys without a thread are absent.
+func (s *Storage) GetAlertThreads(chatID int64, keys []string) (map[string]models.AlertThread, error) {
+	threads := make(map[string]models.AlertThread)
+	if len(keys) == 0 {
+		return threads, nil
+	}
+	keysJSON, err := json.Marshal(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode market keys: %w", err)
+	}
 	rows, err := s.db.Query(`
-		SELECT id, market_id, original_event_id, event_title, event_url, polymarket_market_id,
-		       market_question, magnitude, direction, old_prob, new_prob, time_window,
-		       detected_at, notified, signal_score
-		FROM changes ORDER BY magnitude DESC LIMIT ?`, k)
+		SELECT chat_id, market_key, message_id, direction, start_prob, last_prob, started_at, updated_at
+		FROM alert_threads
+		WHERE chat_id = ? AND market_key IN (SELECT value FROM json_each(?))`, chatID, string(keysJSON))
 	if err != nil {
-		return nil, fmt.Errorf("failed to query changes: %w", err)
+		return nil, fmt.Errorf("failed to query alert threads: %w", err)
 	}
 	defer rows.Close()
-	return scanChanges(rows)
+	for rows.Next() {
+		var t models.AlertThread
+		var startedNano, updatedNano int64
+		if err := rows.Scan(&t.ChatID, &t.Key, &t.MessageID, &t.Direction, &t.StartProb, &t.LastProb,
+			&startedNano, &updatedNano); err != nil {
+			return nil, fmt.Errorf("failed to scan alert thread: %w", err)
+		}
+		t.StartedAt = time.Unix(0, startedNano)
+		t.UpdatedAt = time.Unix(0, updatedNano)
+		threads[t.Key] = t
+	}
+	return threads, rows.Err()
 }
 
-func (s *Storage) ClearChanges() error {
-	if _, err := s.db.Exec(`DELETE FROM changes`); err != nil {
-		return fmt.Errorf("failed to clear changes: %w", err)
+// ExpireAlertThreads deletes threads last updated before cutoff and returns how many were removed.
+func (s *Storage) ExpireAlertThreads(cutoff time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM alert_threads WHERE updated_at < ?`, cutoff.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire alert threads: %w", err)
 	}
-	return nil
+	n, _ := res.RowsAffected()
+	return n, nil
 }
 
 // --- Rotation ---
 
-// RotateSnapshots keeps at most maxSnapshotsPerEvent newest snapshots per market,
-// ordered by timestamp (not insertion order).
-func (s *Storage) RotateSnapshots() error {
+// RotateSnapshots keeps at most maxSnapshotsPerEvent newest snapshots per market
+// series (one series per tracked outcome), ordered by timestamp (not insertion order).
+// Snapshots from the exempt sources neither count against the cap nor are
+// deleted by it; rotate them with PruneSnapshots.
+func (s *Storage) RotateSnapshots(exempt ...string) error {
+	exemptJSON, err := json.Marshal(append([]string{}, exempt...))
+	if err != nil {
+		return fmt.Errorf("failed to encode exempt sources: %w", err)
+	}
 	rows, err := s.db.Query(`
-		SELECT market_id FROM snapshots
-		GROUP BY market_id HAVING COUNT(*) > ?`, s.maxSnapshotsPerEvent)
+		SELECT market_id, outcome FROM snapshots
+		WHERE source NOT IN (SELECT value FROM json_each(?))
+		GROUP BY market_id, outcome HAVING COUNT(*) > ?`, string(exemptJSON), s.maxSnapshotsPerEvent)
 	if err != nil {
 		return fmt.Errorf("failed to query markets for rotation: %w", err)
 	}
-	var ids []string
+	type series struct{ id, outcome string }
+	var all []series
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
+		var sr series
+		if err := rows.Scan(&sr.id, &sr.outcome); err != nil {
 			rows.Close()
 			return err
 		}
-		ids = append(ids, id)
+		all = append(all, sr)
 	}
 	rows.Close()
 	if err := rows.Err(); err != nil {
 		return err
 	}
-	for _, id := range ids {
+	for _, sr := range all {
 		_, err := s.db.Exec(`
 			DELETE FROM snapshots
-			WHERE market_id = ? AND id NOT IN (
-				SELECT id FROM snapshots WHERE market_id = ?
+			WHERE market_id = ? AND outcome = ?
+			AND source NOT IN (SELECT value FROM json_each(?))
+			AND id NOT IN (
+				SELECT id FROM snapshots
+				WHERE market_id = ? AND outcome = ? AND source NOT IN (SELECT value FROM json_each(?))
 				ORDER BY timestamp DESC LIMIT ?
-			)`, id, id, s.maxSnapshotsPerEvent)
+			)`, sr.id, sr.outcome, string(exemptJSON), sr.id, sr.outcome, string(exemptJSON), s.maxSnapshotsPerEvent)
 		if err != nil {
-			return fmt.Errorf("failed to rotate snapshots for %s: %w", id, err)
+			return fmt.Errorf("failed to rotate snapshots for %s: %w", sr.id, err)
 		}
 	}
 	return nil
 }
 
+// PruneSnapshots deletes snapshots from source taken before cutoff and
+// returns how many were deleted.
+func (s *Storage) PruneSnapshots(source string, cutoff time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM snapshots WHERE source = ? AND timestamp < ?`, source, cutoff.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune %s snapshots: %w", source, err)
+	}
+	return res.RowsAffected()
+}
+
+// KeepPendingResolution exempts open, unresolved markets from the market cap
+// for maxAge after they were last seen. Markets that left the feed have the
+// oldest last_updated, so they would otherwise be evicted first, before the
+// resolution tracker records how they resolved. Zero (the default) exempts nothing.
+func (s *Storage) KeepPendingResolution(maxAge time.Duration) {
+	s.pendingMaxAge = maxAge
+}
+
 // RotateMarkets keeps at most maxMarkets newest markets (by last_updated),
-// cascading delete removes their snapshots.
+// cascading delete removes their snapshots. Markets pending resolution are
+// kept (see KeepPendingResolution).
 func (s *Storage) RotateMarkets() error {
-	_, err := s.db.Exec(`
-		DELETE FROM markets WHERE id NOT IN (
-			SELECT id FROM markets ORDER BY last_updated DESC LIMIT ?
-		)`, s.maxMarkets)
-	if err != nil {
+	if err := s.evictMarkets(s.db); err != nil {
 		return fmt.Errorf("failed to rotate markets: %w", err)
 	}
 	return nil
 }
 
+// evictMarkets deletes the markets beyond the cap, except those pending resolution.
+func (s *Storage) evictMarkets(db execer) error {
+	pendingSince := int64(math.MaxInt64)
+	if s.pendingMaxAge > 0 {
+		pendingSince = time.Now().Add(-s.pendingMaxAge).UnixNano()
+	}
+	_, err := db.Exec(`
+		DELETE FROM markets WHERE id NOT IN (
+			SELECT id FROM markets ORDER BY last_updated DESC LIMIT ?
+		) AND NOT (
+			closed = 0 AND last_updated >= ?
+			AND id NOT IN (SELECT market_id FROM resolutions)
+		)`, s.maxMarkets, pendingSince)
+	return err
+}
+
 // --- Helpers ---
 
 const marketCols = `id, event_id, market_id, market_question, title, event_url, description,
 	category, subcategory, yes_prob, no_prob, volume_24hr, volume_1wk, volume_1mo,
-	liquidity, active, closed, last_updated, created_at`
+	liquidity, active, closed, last_updated, created_at,
+	clob_token_id, best_bid, best_ask, spread, midpoint, bid_depth, ask_depth`
+
+const changeCols = `id, market_id, original_event_id, event_title, event_url, polymarket_market_id,
+	market_question, magnitude, direction, old_prob, new_prob, time_window,
+	detected_at, notified, signal_score, outcome, passed_threshold, category, score_breakdown`
+
+const snapshotCols = `id, market_id, yes_prob, no_prob, timestamp, source,
+	best_bid, best_ask, spread, midpoint, bid_depth, ask_depth, outcome`
 
 func scanMarket(scan func(...any) error) (*models.Market, error) {
 	var m models.Market
@@ -379,6 +1571,7 @@ func scanMarket(scan func(...any) error) (*models.Market, error) {
 		&m.YesProbability, &m.NoProbability,
 		&m.Volume24hr, &m.Volume1wk, &m.Volume1mo, &m.Liquidity,
 		&active, &closed, &lastUpdatedNano, &createdAtNano,
+		&m.ClobTokenID, &m.BestBid, &m.BestAsk, &m.Spread, &m.Midpoint, &m.BidDepth, &m.AskDepth,
 	)
 	if err != nil {
 		return nil, err
@@ -395,7 +1588,11 @@ func scanSnapshots(rows *sql.Rows) ([]models.Snapshot, error) {
 	for rows.Next() {
 		var s models.Snapshot
 		var tsNano int64
-		if err := rows.Scan(&s.ID, &s.EventID, &s.YesProbability, &s.NoProbability, &tsNano, &s.Source); err != nil {
+		err := rows.Scan(
+			&s.ID, &s.EventID, &s.YesProbability, &s.NoProbability, &tsNano, &s.Source,
+			&s.BestBid, &s.BestAsk, &s.Spread, &s.Midpoint, &s.BidDepth, &s.AskDepth, &s.Outcome,
+		)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
 		}
 		s.Timestamp = time.Unix(0, tsNano)
@@ -410,26 +1607,53 @@ func scanSnapshots(rows *sql.Rows) ([]models.Snapshot, error) {
 func scanChanges(rows *sql.Rows) ([]models.Change, error) {
 	var result []models.Change
 	for rows.Next() {
-		var c models.Change
-		var detectedAtNano, timeWindowNano int64
-		var notified int
-		err := rows.Scan(
-			&c.ID, &c.EventID, &c.OriginalEventID, &c.EventTitle, &c.EventURL,
-			&c.MarketID, &c.MarketQuestion,
-			&c.Magnitude, &c.Direction, &c.OldProbability, &c.NewProbability,
-			&timeWindowNano, &detectedAtNano, &notified, &c.SignalScore,
-		)
+		c, err := scanChange(rows.Scan)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan change: %w", err)
+			return nil, err
 		}
-		c.TimeWindow = time.Duration(timeWindowNano)
-		c.DetectedAt = time.Unix(0, detectedAtNano)
-		c.Notified = notified != 0
 		result = append(result, c)
 	}
 	return result, rows.Err()
 }
 
+// scanChange scans changeCols followed by any extra destinations.
+func scanChange(scan func(...any) error, extra ...any) (models.Change, error) {
+	var c models.Change
+	var detectedAtNano, timeWindowNano int64
+	var notified, passed int
+	var breakdown string
+	dest := []any{
+		&c.ID, &c.EventID, &c.OriginalEventID, &c.EventTitle, &c.EventURL,
+		&c.MarketID, &c.MarketQuestion,
+		&c.Magnitude, &c.Direction, &c.OldProbability, &c.NewProbability,
+		&timeWindowNano, &detectedAtNano, &notified, &c.SignalScore, &c.Outcome, &passed,
+		&c.Category, &breakdown,
+	}
+	if err := scan(append(dest, extra...)...); err != nil {
+		return c, fmt.Errorf("failed to scan change: %w", err)
+	}
+	if breakdown != "" {
+		c.Breakdown = &models.ScoreBreakdown{}
+		if err := json.Unmarshal([]byte(breakdown), c.Breakdown); err != nil {
+			return c, fmt.Errorf("failed to decode score breakdown: %w", err)
+		}
+	}
+	c.TimeWindow = time.Duration(timeWindowNano)
+	c.DetectedAt = time.Unix(0, detectedAtNano)
+	c.Notified = notified != 0
+	c.PassedThreshold = passed != 0
+	return c, nil
+}
+
+// prefixCols qualifies each column in a comma-separated list with a table alias.
+func prefixCols(alias, cols string) string {
+	parts := strings.Split(cols, ",")
+	for i, p := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(p)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+const (
+	benchMarkets         = 10000
+	benchSnapshotsPerMkt = 10
+)
+
+// seedBenchStorage creates benchMarkets markets with benchSnapshotsPerMkt
+// snapshots each. Markets are inserted directly in one transaction because
+// AddMarket enforces the market cap on every insert.
+func seedBenchStorage(b *testing.B) (*Storage, []string) {
+	b.Helper()
+	s, err := New(benchMarkets, benchSnapshotsPerMkt, filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("failed to create storage: %v", err)
+	}
+	b.Cleanup(func() { _ = s.Close() })
+
+	now := time.Now()
+	tx, err := s.db.Begin()
+	if err != nil {
+		b.Fatalf("begin: %v", err)
+	}
+	ids := make([]string, benchMarkets)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("event-%d:market-%d", i, i)
+		if _, err := tx.Exec(`
+			INSERT INTO markets (id, event_id, market_id, title, category, yes_prob, no_prob, active, closed, last_updated, created_at)
+			VALUES (?, ?, ?, 'Bench', 'bench', 0.5, 0.5, 1, 0, ?, ?)`,
+			ids[i], fmt.Sprintf("event-%d", i), fmt.Sprintf("market-%d", i), now.UnixNano(), now.UnixNano()); err != nil {
+			b.Fatalf("insert market: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatalf("commit: %v", err)
+	}
+
+	snaps := make([]models.Snapshot, 0, benchMarkets*benchSnapshotsPerMkt)
+	for _, id := range ids {
+		for j := 0; j < benchSnapshotsPerMkt; j++ {
+			snaps = append(snaps, models.Snapshot{
+				ID:             fmt.Sprintf("%s:%d", id, j),
+				EventID:        id,
+				YesProbability: 0.5,
+				NoProbability:  0.5,
+				Timestamp:      now.Add(-time.Duration(j) * time.Minute),
+				Source:         "bench",
+			})
+		}
+	}
+	if _, err := s.AddSnapshots(snaps); err != nil {
+		b.Fatalf("AddSnapshots: %v", err)
+	}
+	return s, ids
+}
+
+func BenchmarkSnapshotsInWindow_PerMarket(b *testing.B) {
+	s, ids := seedBenchStorage(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, id := range ids {
+			if _, err := s.GetSnapshotsInWindow(id, time.Hour); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSnapshotsInWindow_Batched(b *testing.B) {
+	s, ids := seedBenchStorage(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.GetSnapshotsInWindowForMarkets(ids, time.Hour); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package storage
 
 import (
+	"database/sql"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -132,6 +136,69 @@ func TestStorage_AddSnapshot(t *testing.T) {
 	}
 }
 
+func TestStorage_OrderBookFieldsRoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+	m := testMarket("e:m", "e", "m", now)
+	m.ClobTokenID = "token-yes"
+	m.BestBid, m.BestAsk, m.Spread, m.Midpoint = 0.74, 0.76, 0.02, 0.75
+	m.BidDepth, m.AskDepth = 740, 380
+	if err := s.AddMarket(m); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+	snap := &models.Snapshot{
+		ID: "snap-1", EventID: "e:m", YesProbability: 0.75, NoProbability: 0.25,
+		BestBid: 0.74, BestAsk: 0.76, Spread: 0.02, Midpoint: 0.75, BidDepth: 740, AskDepth: 380,
+		Timestamp: now.Add(-time.Minute), Source: "test",
+	}
+	if err := s.AddSnapshot(snap); err != nil {
+		t.Fatalf("AddSnapshot: %v", err)
+	}
+
+	got, err := s.GetMarket("e:m")
+	if err != nil {
+		t.Fatalf("GetMarket: %v", err)
+	}
+	if got.ClobTokenID != "token-yes" || got.Spread != 0.02 || got.BidDepth != 740 {
+		t.Errorf("market book fields not persisted: %+v", got)
+	}
+	snaps, err := s.GetSnapshots("e:m")
+	if err != nil {
+		t.Fatalf("GetSnapshots: %v", err)
+	}
+	if len(snaps) != 1 || snaps[0].BestAsk != 0.76 || snaps[0].AskDepth != 380 {
+		t.Errorf("snapshot book fields not persisted: %+v", snaps)
+	}
+}
+
+func TestStorage_AddsMissingColumnsToExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// Pre-CLOB snapshots table without order-book columns.
+	if _, err := db.Exec(`CREATE TABLE snapshots (
+		id TEXT PRIMARY KEY, market_id TEXT NOT NULL, yes_prob REAL NOT NULL,
+		no_prob REAL NOT NULL, timestamp INTEGER NOT NULL, source TEXT NOT NULL)`); err != nil {
+		t.Fatalf("create legacy table: %v", err)
+	}
+	_ = db.Close()
+
+	s, err := New(10, 10, dbPath)
+	if err != nil {
+		t.Fatalf("New on legacy database: %v", err)
+	}
+	defer s.Close()
+	if err := s.AddMarket(testMarket("e:m", "e", "m", time.Now())); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+	snap := &models.Snapshot{ID: "s", EventID: "e:m", YesProbability: 0.5, NoProbability: 0.5, Spread: 0.01, Timestamp: time.Now(), Source: "test"}
+	if err := s.AddSnapshot(snap); err != nil {
+		t.Fatalf("AddSnapshot on upgraded table: %v", err)
+	}
+}
+
 func TestStorage_GetSnapshotsInWindow(t *testing.T) {
 	s := newTestStorage(t)
 	now := time.Now()
@@ -250,6 +317,49 @@ func TestStorage_RotateSnapshots_ByTimestamp_NotInsertionOrder(t *testing.T) {
 	}
 }
 
+func TestStorage_RotateSnapshots_ExemptSourcesArePrunedByAge(t *testing.T) {
+	s, err := New(100, 2, ":memory:")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+
+	now := time.Now()
+	if err := s.AddMarket(testMarket("e:m", "e", "m", now)); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+	// Two polled snapshots an hour apart, with streamed ones in between
+	add := func(id, source string, age time.Duration) {
+		t.Helper()
+		if err := s.AddSnapshot(&models.Snapshot{ID: id, EventID: "e:m", YesProbability: 0.5, NoProbability: 0.5,
+			Timestamp: now.Add(-age), Source: source}); err != nil {
+			t.Fatalf("AddSnapshot: %v", err)
+		}
+	}
+	add("p1", "poll", 2*time.Hour)
+	add("p2", "poll", time.Hour)
+	for i := 0; i < 5; i++ {
+		add(fmt.Sprintf("w%d", i), "ws", time.Duration(i*20)*time.Minute)
+	}
+
+	if err := s.RotateSnapshots("ws"); err != nil {
+		t.Fatalf("RotateSnapshots: %v", err)
+	}
+	snaps, _ := s.GetSnapshots("e:m")
+	if len(snaps) != 7 {
+		t.Fatalf("streamed snapshots must not push polled ones out of the cap: got %d snapshots, want 7", len(snaps))
+	}
+
+	n, err := s.PruneSnapshots("ws", now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatalf("PruneSnapshots: %v", err)
+	}
+	snaps, _ = s.GetSnapshots("e:m")
+	if n != 3 || len(snaps) != 4 {
+		t.Errorf("pruned %d, %d left; want 3 pruned, 4 left (2 polled, 2 streamed)", n, len(snaps))
+	}
+}
+
 func TestStorage_RotateMarkets(t *testing.T) {
 	s, err := New(5, 50, ":memory:")
 	if err != nil {
@@ -285,6 +395,46 @@ func TestStorage_RotateMarkets(t *testing.T) {
 	}
 }
 
+func TestStorage_RotateMarkets_KeepsPendingResolution(t *testing.T) {
+	s, err := New(2, 50, ":memory:")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+	s.KeepPendingResolution(7 * 24 * time.Hour)
+
+	now := time.Now()
+	for id, lastSeen := range map[string]time.Duration{
+		"pending:m":  2 * time.Hour,       // left the feed recently, unresolved
+		"stale:m":    10 * 24 * time.Hour, // left the feed too long ago
+		"resolved:m": 3 * time.Hour,
+		"live1:m":    0,
+		"live2:m":    time.Minute,
+	} {
+		eventID, marketID, _ := strings.Cut(id, ":")
+		if err := s.AddMarket(testMarket(id, eventID, marketID, now.Add(-lastSeen))); err != nil {
+			t.Fatalf("AddMarket %s: %v", id, err)
+		}
+	}
+	if err := s.AddResolution(&models.Resolution{MarketID: "resolved:m", EventID: "resolved",
+		WinningOutcome: "Yes", ResolvedAt: now, RecordedAt: now}); err != nil {
+		t.Fatalf("AddResolution: %v", err)
+	}
+
+	if err := s.RotateMarkets(); err != nil {
+		t.Fatalf("RotateMarkets: %v", err)
+	}
+	markets, _ := s.GetAllMarkets()
+	var ids []string
+	for _, m := range markets {
+		ids = append(ids, m.ID)
+	}
+	sort.Strings(ids)
+	if got := strings.Join(ids, ","); got != "live1:m,live2:m,pending:m" {
+		t.Errorf("markets after rotation = %s, want live1:m,live2:m,pending:m", got)
+	}
+}
+
 func TestStorage_RotateMarkets_CascadesSnapshots(t *testing.T) {
 	s, err := New(1, 50, ":memory:")
 	if err != nil {
@@ -357,21 +507,120 @@ func TestStorage_GetTopChanges(t *testing.T) {
 	}
 }
 
-func TestStorage_ClearChanges(t *testing.T) {
+func TestStorage_QueryChanges(t *testing.T) {
+	s := newTestStorage(t)
+	base := time.Now().Add(-time.Hour)
+	var batch []models.Change
+	for i := 0; i < 6; i++ {
+		event := "e1"
+		if i%2 == 1 {
+			event = "e2"
+		}
+		batch = append(batch, models.Change{
+			ID: fmt.Sprintf("c%d", i), EventID: event + ":m", OriginalEventID: event, EventTitle: "T",
+			Magnitude: 0.10, Direction: "increase", OldProbability: 0.60, NewProbability: 0.70,
+			TimeWindow: time.Hour, DetectedAt: base.Add(time.Duration(i) * time.Minute),
+			SignalScore: 0.2, PassedThreshold: i%3 == 0,
+		})
+	}
+	if err := s.AddChanges(batch); err != nil {
+		t.Fatalf("AddChanges: %v", err)
+	}
+
+	page, err := s.QueryChanges(ChangeFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("QueryChanges: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != "c5" || page[1].ID != "c4" {
+		t.Fatalf("expected newest-first first page [c5 c4], got %+v", page)
+	}
+	page, _ = s.QueryChanges(ChangeFilter{Limit: 2, Offset: 2})
+	if len(page) != 2 || page[0].ID != "c3" {
+		t.Errorf("expected second page to start at c3, got %+v", page)
+	}
+
+	byEvent, _ := s.QueryChanges(ChangeFilter{EventID: "e1"})
+	if len(byEvent) != 3 {
+		t.Errorf("expected 3 changes for event e1, got %d", len(byEvent))
+	}
+	byMarket, _ := s.QueryChanges(ChangeFilter{MarketID: "e2:m", Since: base.Add(2 * time.Minute), Until: base.Add(5 * time.Minute)})
+	if len(byMarket) != 1 || byMarket[0].ID != "c3" {
+		t.Errorf("expected only c3 in market/time range, got %+v", byMarket)
+	}
+	if !byEvent[2].PassedThreshold || byEvent[1].PassedThreshold {
+		t.Errorf("passed_threshold not persisted: %+v", byEvent)
+	}
+}
+
+func TestStorage_RecordDelivery(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+	for _, id := range []string{"sent", "unsent"} {
+		c := &models.Change{
+			ID: id, EventID: "e1", EventTitle: "T", Magnitude: 0.10,
+			Direction: "increase", OldProbability: 0.60, NewProbability: 0.70,
+			TimeWindow: time.Hour, DetectedAt: now,
+		}
+		if err := s.AddChange(c); err != nil {
+			t.Fatalf("AddChange: %v", err)
+		}
+	}
+	if err := s.RecordDelivery([]string{"sent"}, "telegram:42", "1001", now); err != nil {
+		t.Fatalf("RecordDelivery: %v", err)
+	}
+
+	history, err := s.QueryChanges(ChangeFilter{})
+	if err != nil {
+		t.Fatalf("QueryChanges: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected both changes kept in history, got %d", len(history))
+	}
+	for _, c := range history {
+		switch c.ID {
+		case "sent":
+			if !c.Notified || len(c.Deliveries) != 1 || c.Deliveries[0].Destination != "telegram:42" || c.Deliveries[0].MessageID != "1001" {
+				t.Errorf("delivery not recorded: %+v", c)
+			}
+		case "unsent":
+			if c.Notified || len(c.Deliveries) != 0 {
+				t.Errorf("unsent change should have no deliveries: %+v", c)
+			}
+		}
+	}
+}
+
+func TestStorage_PruneChanges(t *testing.T) {
 	s := newTestStorage(t)
 	now := time.Now()
-	c := &models.Change{
-		ID: "c1", EventID: "e1", EventTitle: "T", Magnitude: 0.10,
-		Direction: "increase", OldProbability: 0.60, NewProbability: 0.70,
-		TimeWindow: time.Hour, DetectedAt: now,
+	for id, age := range map[string]time.Duration{"old": 100 * 24 * time.Hour, "recent": time.Hour} {
+		c := &models.Change{
+			ID: id, EventID: "e1", EventTitle: "T", Magnitude: 0.10,
+			Direction: "increase", OldProbability: 0.60, NewProbability: 0.70,
+			TimeWindow: time.Hour, DetectedAt: now.Add(-age),
+		}
+		if err := s.AddChange(c); err != nil {
+			t.Fatalf("AddChange: %v", err)
+		}
+	}
+	if err := s.RecordDelivery([]string{"old", "recent"}, "slack", "", now); err != nil {
+		t.Fatalf("RecordDelivery: %v", err)
+	}
+
+	n, err := s.PruneChanges(now.Add(-90 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("PruneChanges: %v", err)
+	}
+	history, _ := s.QueryChanges(ChangeFilter{})
+	if n != 1 || len(history) != 1 || history[0].ID != "recent" {
+		t.Fatalf("pruned %d, kept %+v; want only recent kept", n, history)
 	}
-	_ = s.AddChange(c)
-	if err := s.ClearChanges(); err != nil {
-		t.Fatalf("ClearChanges: %v", err)
+	var deliveries int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM deliveries`).Scan(&deliveries); err != nil {
+		t.Fatalf("count deliveries: %v", err)
 	}
-	top, _ := s.GetTopChanges(10)
-	if len(top) != 0 {
-		t.Errorf("expected 0 changes after clear, got %d", len(top))
+	if deliveries != 1 {
+		t.Errorf("deliveries of pruned changes should cascade, %d left", deliveries)
 	}
 }
 
@@ -418,3 +667,461 @@ func TestStorage_DefaultPath(t *testing.T) {
 	}
 	defer s.Close()
 }
+
+func TestStorage_OutcomesAndPerOutcomeSnapshots(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+	m := testMarket("e:m", "e", "m", now)
+	m.Outcomes = []models.Outcome{
+		{Index: 0, Name: "Trump", TokenID: "tok-t", Price: 0.75},
+		{Index: 1, Name: "Harris", TokenID: "tok-h", Price: 0.25},
+	}
+	if err := s.AddMarket(m); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+
+	got, err := s.GetMarket("e:m")
+	if err != nil {
+		t.Fatalf("GetMarket: %v", err)
+	}
+	if len(got.Outcomes) != 2 || got.Outcomes[1].Name != "Harris" || got.Outcomes[1].TokenID != "tok-h" {
+		t.Fatalf("outcomes not persisted: %+v", got.Outcomes)
+	}
+
+	// Updating replaces the outcome set
+	m.Outcomes = []models.Outcome{{Index: 0, Name: "Trump", Price: 0.8}}
+	if err := s.UpdateMarket(m); err != nil {
+		t.Fatalf("UpdateMarket: %v", err)
+	}
+	all, err := s.GetAllMarkets()
+	if err != nil {
+		t.Fatalf("GetAllMarkets: %v", err)
+	}
+	if len(all) != 1 || len(all[0].Outcomes) != 1 || all[0].Outcomes[0].Price != 0.8 {
+		t.Fatalf("outcomes not replaced: %+v", all[0].Outcomes)
+	}
+
+	for i, outcome := range []string{"Trump", "Harris", "Trump"} {
+		snap := &models.Snapshot{
+			ID: fmt.Sprintf("s%d", i), EventID: "e:m", Outcome: outcome,
+			YesProbability: 0.5, NoProbability: 0.5,
+			Timestamp: now.Add(time.Duration(i-5) * time.Minute), Source: "test",
+		}
+		if err := s.AddSnapshot(snap); err != nil {
+			t.Fatalf("AddSnapshot: %v", err)
+		}
+	}
+	trump, err := s.GetOutcomeSnapshots("e:m", "Trump")
+	if err != nil {
+		t.Fatalf("GetOutcomeSnapshots: %v", err)
+	}
+	if len(trump) != 2 || trump[0].Outcome != "Trump" {
+		t.Errorf("Expected 2 Trump snapshots, got %+v", trump)
+	}
+	if primary, _ := s.GetSnapshots("e:m"); len(primary) != 0 {
+		t.Errorf("Expected outcome snapshots to be excluded from the primary series, got %d", len(primary))
+	}
+}
+
+func TestStorage_GetSnapshotsForMarkets(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+	for _, id := range []string{"m1", "m2", "m3"} {
+		if err := s.AddMarket(testMarket(id, "e-"+id, id, now)); err != nil {
+			t.Fatalf("AddMarket(%s): %v", id, err)
+		}
+	}
+	snaps := []models.Snapshot{
+		{ID: "m1-old", EventID: "m1", YesProbability: 0.4, NoProbability: 0.6, Timestamp: now.Add(-3 * time.Hour), Source: "test"},
+		{ID: "m1-new", EventID: "m1", YesProbability: 0.5, NoProbability: 0.5, Timestamp: now.Add(-10 * time.Minute), Source: "test"},
+		{ID: "m2-a", EventID: "m2", Outcome: "Alice", YesProbability: 0.3, NoProbability: 0.7, Timestamp: now.Add(-5 * time.Minute), Source: "test"},
+		{ID: "m2-b", EventID: "m2", Outcome: "Bob", YesProbability: 0.6, NoProbability: 0.4, Timestamp: now.Add(-5 * time.Minute), Source: "test"},
+		{ID: "m3-x", EventID: "m3", YesProbability: 0.9, NoProbability: 0.1, Timestamp: now.Add(-5 * time.Minute), Source: "test"},
+	}
+	if _, err := s.AddSnapshots(snaps); err != nil {
+		t.Fatalf("AddSnapshots: %v", err)
+	}
+
+	all, err := s.GetSnapshotsForMarkets([]string{"m1", "m2"})
+	if err != nil {
+		t.Fatalf("GetSnapshotsForMarkets: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 series, got %d", len(all))
+	}
+	m1 := all[SeriesKey{MarketID: "m1"}]
+	if len(m1) != 2 || m1[0].ID != "m1-old" || m1[1].ID != "m1-new" {
+		t.Errorf("m1 series not in timestamp order: %+v", m1)
+	}
+	if got := all[SeriesKey{MarketID: "m2", Outcome: "Bob"}]; len(got) != 1 || got[0].ID != "m2-b" {
+		t.Errorf("unexpected m2/Bob series: %+v", got)
+	}
+	if _, ok := all[SeriesKey{MarketID: "m3"}]; ok {
+		t.Error("m3 was not requested but was returned")
+	}
+
+	windowed, err := s.GetSnapshotsInWindowForMarkets([]string{"m1", "m2", "m3"}, time.Hour)
+	if err != nil {
+		t.Fatalf("GetSnapshotsInWindowForMarkets: %v", err)
+	}
+	if got := windowed[SeriesKey{MarketID: "m1"}]; len(got) != 1 || got[0].ID != "m1-new" {
+		t.Errorf("window should drop m1-old, got %+v", got)
+	}
+
+	empty, err := s.GetSnapshotsForMarkets(nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("expected empty result for no markets, got %v, %v", empty, err)
+	}
+}
+
+func TestStorage_IngestCycle(t *testing.T) {
+	s, err := New(2, 50, ":memory:")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+
+	first := time.Now().Add(-time.Hour)
+	stale := testMarket("stale", "e-stale", "stale", first.Add(-time.Hour))
+	kept := testMarket("kept", "e-kept", "kept", first)
+	for _, m := range []*models.Market{stale, kept} {
+		if err := s.AddMarket(m); err != nil {
+			t.Fatalf("AddMarket(%s): %v", m.ID, err)
+		}
+	}
+
+	cycle := time.Now().Add(-time.Minute)
+	markets := []models.Market{
+		*testMarket("kept", "e-kept", "kept", cycle),
+		*testMarket("new", "e-new", "new", cycle),
+		*testMarket("bad", "", "bad", cycle),
+	}
+	snaps := []models.Snapshot{
+		{ID: "s-kept", EventID: "kept", YesProbability: 0.75, NoProbability: 0.25, Source: "test"},
+		{ID: "s-new", EventID: "new", YesProbability: 0.75, NoProbability: 0.25, Source: "test"},
+		{ID: "s-bad", EventID: "bad", YesProbability: 0.75, NoProbability: 0.25, Source: "test"},
+	}
+	res, err := s.IngestCycle(cycle, markets, snaps)
+	if err != nil {
+		t.Fatalf("IngestCycle: %v", err)
+	}
+	if res.New != 1 || res.Updated != 1 || res.Snapshots != 2 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if _, ok := res.Rejected["bad"]; !ok || len(res.Rejected) != 1 {
+		t.Errorf("expected only 'bad' to be rejected, got %v", res.Rejected)
+	}
+	if !markets[0].CreatedAt.Equal(kept.CreatedAt) {
+		t.Errorf("existing market CreatedAt = %v, want %v", markets[0].CreatedAt, kept.CreatedAt)
+	}
+	if !markets[1].CreatedAt.Equal(cycle) {
+		t.Errorf("new market CreatedAt = %v, want cycle time", markets[1].CreatedAt)
+	}
+
+	// The cap of 2 is enforced once, evicting the least recently updated market.
+	all, err := s.GetAllMarkets()
+	if err != nil {
+		t.Fatalf("GetAllMarkets: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 markets after cap, got %d", len(all))
+	}
+	if _, err := s.GetMarket("stale"); err == nil {
+		t.Error("expected the stale market to be evicted by the cap")
+	}
+	got, err := s.GetSnapshots("new")
+	if err != nil || len(got) != 1 {
+		t.Fatalf("GetSnapshots(new) = %v, %v", got, err)
+	}
+	if !got[0].Timestamp.Equal(cycle) {
+		t.Errorf("snapshot timestamp = %v, want cycle time %v", got[0].Timestamp, cycle)
+	}
+}
+
+func TestStorage_IngestCycle_RollsBackOnFailure(t *testing.T) {
+	s := newTestStorage(t)
+	cycle := time.Now().Add(-time.Minute)
+	markets := []models.Market{*testMarket("a", "e-a", "a", cycle)}
+	// Duplicate snapshot IDs violate the primary key mid-transaction.
+	snaps := []models.Snapshot{
+		{ID: "dup", EventID: "a", YesProbability: 0.75, NoProbability: 0.25, Source: "test"},
+		{ID: "dup", EventID: "a", YesProbability: 0.75, NoProbability: 0.25, Source: "test"},
+	}
+	if _, err := s.IngestCycle(cycle, markets, snaps); err == nil {
+		t.Fatal("expected error for duplicate snapshot IDs")
+	}
+	if _, err := s.GetMarket("a"); err == nil {
+		t.Error("market from failed cycle should not be stored")
+	}
+}
+
+func TestStorage_Subscriptions(t *testing.T) {
+	s := newTestStorage(t)
+
+	if sub, err := s.GetSubscription(42); err != nil || sub != nil {
+		t.Fatalf("GetSubscription on empty store = %v, %v; want nil, nil", sub, err)
+	}
+
+	sub := &models.Subscription{ChatID: 42, Categories: []string{"crypto"}, MinScore: 0.1, TopK: 3}
+	if err := s.SaveSubscription(sub); err != nil {
+		t.Fatalf("SaveSubscription: %v", err)
+	}
+	created := sub.CreatedAt
+
+	sub.EventIDs = []string{"btc-100k"}
+	if err := s.SaveSubscription(sub); err != nil {
+		t.Fatalf("SaveSubscription (update): %v", err)
+	}
+	if err := s.SaveSubscription(&models.Subscription{ChatID: 7}); err != nil {
+		t.Fatalf("SaveSubscription: %v", err)
+	}
+
+	got, err := s.GetSubscription(42)
+	if err != nil || got == nil {
+		t.Fatalf("GetSubscription = %v, %v", got, err)
+	}
+	if got.Categories[0] != "crypto" || got.EventIDs[0] != "btc-100k" || got.MinScore != 0.1 || got.TopK != 3 {
+		t.Errorf("unexpected subscription: %+v", got)
+	}
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("CreatedAt changed on update: %v -> %v", created, got.CreatedAt)
+	}
+
+	all, err := s.ListSubscriptions()
+	if err != nil || len(all) != 2 || all[0].ChatID != 7 {
+		t.Fatalf("ListSubscriptions = %v, %v", all, err)
+	}
+	if len(all[0].Categories) != 0 {
+		t.Errorf("expected no categories for chat 7, got %v", all[0].Categories)
+	}
+
+	if err := s.DeleteSubscription(42); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+	if got, _ := s.GetSubscription(42); got != nil {
+		t.Error("subscription still present after delete")
+	}
+	if err := s.SaveSubscription(&models.Subscription{ChatID: 0}); err == nil {
+		t.Error("expected validation error for zero chat ID")
+	}
+}
+
+func TestStorage_SearchMarketsAndTopScoredChanges(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	btc := testMarket("e1:m1", "e1", "m1", now)
+	btc.Title, btc.MarketQuestion, btc.Volume24hr = "Bitcoin price", "Will BTC hit 100% of ATH?", 10
+	eth := testMarket("e2:m2", "e2", "m2", now)
+	eth.Title, eth.MarketQuestion, eth.Volume24hr = "Ethereum", "Will bitcoin flip ETH?", 50
+	other := testMarket("e3:m3", "e3", "m3", now)
+	other.Title = "Election"
+	for _, m := range []*models.Market{btc, eth, other} {
+		if err := s.AddMarket(m); err != nil {
+			t.Fatalf("AddMarket: %v", err)
+		}
+	}
+
+	if n, err := s.CountMarkets(); err != nil || n != 3 {
+		t.Errorf("CountMarkets = %d, %v; want 3", n, err)
+	}
+	found, err := s.SearchMarkets("bitcoin", 10)
+	if err != nil {
+		t.Fatalf("SearchMarkets: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != "e2:m2" || found[1].ID != "e1:m1" {
+		t.Errorf("expected both bitcoin markets by volume, got %+v", found)
+	}
+	if found, _ := s.SearchMarkets("100%", 10); len(found) != 1 || found[0].ID != "e1:m1" {
+		t.Errorf("%% should match literally, got %+v", found)
+	}
+
+	for _, c := range []*models.Change{
+		{ID: "old", EventID: "e1", SignalScore: 9, Direction: "increase", TimeWindow: time.Hour, DetectedAt: now.Add(-48 * time.Hour)},
+		{ID: "low", EventID: "e2", SignalScore: 0.1, Direction: "increase", TimeWindow: time.Hour, DetectedAt: now},
+		{ID: "high", EventID: "e3", SignalScore: 0.5, Direction: "decrease", TimeWindow: time.Hour, DetectedAt: now,
+			Breakdown: &models.ScoreBreakdown{KL: 0.1, VolumeWeight: 1.2, SNR: 2, TrajectoryConsistency: 0.9, SpreadWeight: 1, MinScore: 0.02, ConfirmationBypass: true}},
+	} {
+		if err := s.AddChange(c); err != nil {
+			t.Fatalf("AddChange: %v", err)
+		}
+	}
+	top, err := s.TopScoredChanges(now.Add(-24*time.Hour), 5)
+	if err != nil {
+		t.Fatalf("TopScoredChanges: %v", err)
+	}
+	if len(top) != 2 || top[0].ID != "high" || top[1].ID != "low" {
+		t.Errorf("unexpected top changes: %+v", top)
+	}
+
+	if got, err := s.GetChange("high"); err != nil || got == nil || got.SignalScore != 0.5 {
+		t.Errorf("GetChange = %+v, %v", got, err)
+	} else if b := got.Breakdown; b == nil || b.SNR != 2 || b.MinScore != 0.02 || !b.ConfirmationBypass {
+		t.Errorf("score breakdown not persisted: %+v", b)
+	}
+	if top[1].Breakdown != nil {
+		t.Errorf("change without a breakdown should load with none, got %+v", top[1].Breakdown)
+	}
+	if got, err := s.GetChange("missing"); err != nil || got != nil {
+		t.Errorf("GetChange(missing) = %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestStorage_QueryMarketsSnapshotsAndChanges(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	small := testMarket("e1:m1", "e1", "m1", now)
+	small.Volume24hr = 100
+	big := testMarket("e1:m2", "e1", "m2", now)
+	big.MarketQuestion, big.Volume24hr = "Will the Fed cut?", 5000
+	crypto := testMarket("e2:m3", "e2", "m3", now)
+	crypto.Category, crypto.Volume24hr = "Crypto", 9000
+	for _, m := range []*models.Market{small, big, crypto} {
+		if err := s.AddMarket(m); err != nil {
+			t.Fatalf("AddMarket: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		f    MarketFilter
+		want []string
+	}{
+		{"all by volume", MarketFilter{}, []string{"e2:m3", "e1:m2", "e1:m1"}},
+		{"category ignores case", MarketFilter{Category: "crypto"}, []string{"e2:m3"}},
+		{"min volume", MarketFilter{Category: "politics", MinVolume: 1000}, []string{"e1:m2"}},
+		{"search", MarketFilter{Search: "fed"}, []string{"e1:m2"}},
+		{"event", MarketFilter{EventID: "e1"}, []string{"e1:m2", "e1:m1"}},
+		{"page", MarketFilter{Limit: 1, Offset: 1}, []string{"e1:m2"}},
+		{"none", MarketFilter{Category: "sports"}, []string{}},
+	} {
+		got, err := s.QueryMarkets(tc.f)
+		if err != nil {
+			t.Fatalf("%s: QueryMarkets: %v", tc.name, err)
+		}
+		ids := []string{}
+		for _, m := range got {
+			ids = append(ids, m.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: got %v, want %v", tc.name, ids, tc.want)
+		}
+	}
+
+	for i, ts := range []time.Duration{-3 * time.Hour, -2 * time.Hour, -time.Hour} {
+		snap := &models.Snapshot{ID: fmt.Sprintf("s%d", i), EventID: "e1:m1", YesProbability: 0.5, NoProbability: 0.5,
+			Timestamp: now.Add(ts), Source: "test"}
+		if err := s.AddSnapshot(snap); err != nil {
+			t.Fatalf("AddSnapshot: %v", err)
+		}
+	}
+	snaps, err := s.GetSnapshotsInRange("e1:m1", now.Add(-150*time.Minute), now.Add(-time.Hour))
+	if err != nil || len(snaps) != 1 || snaps[0].ID != "s1" {
+		t.Errorf("GetSnapshotsInRange = %+v, %v; want only s1", snaps, err)
+	}
+	if snaps, _ := s.GetSnapshotsInRange("e1:m1", time.Time{}, time.Time{}); len(snaps) != 3 {
+		t.Errorf("open range should return every snapshot, got %d", len(snaps))
+	}
+	if n, err := s.CountSnapshots(); err != nil || n != 3 {
+		t.Errorf("CountSnapshots = %d, %v; want 3", n, err)
+	}
+	if size, err := s.DBSize(); err != nil || size <= 0 {
+		t.Errorf("DBSize = %d, %v; want a positive size", size, err)
+	}
+
+	for _, c := range []*models.Change{
+		{ID: "weak", EventID: "e1:m1", SignalScore: 0.01, Direction: "increase", TimeWindow: time.Hour, DetectedAt: now},
+		{ID: "strong", EventID: "e1:m1", SignalScore: 0.2, PassedThreshold: true, Direction: "increase", TimeWindow: time.Hour, DetectedAt: now},
+		{ID: "failed", EventID: "e1:m1", SignalScore: 0.3, Direction: "increase", TimeWindow: time.Hour, DetectedAt: now},
+	} {
+		if err := s.AddChange(c); err != nil {
+			t.Fatalf("AddChange: %v", err)
+		}
+	}
+	if got, err := s.QueryChanges(ChangeFilter{MinScore: 0.1}); err != nil || len(got) != 2 {
+		t.Errorf("MinScore filter = %d changes, %v; want 2", len(got), err)
+	}
+	passed := true
+	if got, err := s.QueryChanges(ChangeFilter{Passed: &passed}); err != nil || len(got) != 1 || got[0].ID != "strong" {
+		t.Errorf("Passed filter = %+v, %v; want only strong", got, err)
+	}
+}
+
+func TestStorage_WatchesAndMutes(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	if err := s.SaveWatch(&models.Watch{Kind: models.TargetEvent, Target: "btc-100k"}); err != nil {
+		t.Fatalf("SaveWatch: %v", err)
+	}
+	if err := s.SaveWatch(&models.Watch{Kind: models.TargetEvent, Target: "btc-100k", Threshold: 0.02}); err != nil {
+		t.Fatalf("SaveWatch (update): %v", err)
+	}
+	watches, err := s.ListWatches()
+	if err != nil || len(watches) != 1 || watches[0].Threshold != 0.02 {
+		t.Fatalf("ListWatches = %+v, %v", watches, err)
+	}
+	if ok, err := s.DeleteWatch(models.TargetEvent, "btc-100k"); err != nil || !ok {
+		t.Errorf("DeleteWatch = %v, %v; want true", ok, err)
+	}
+	if ok, _ := s.DeleteWatch(models.TargetEvent, "btc-100k"); ok {
+		t.Error("deleting a missing watch should report false")
+	}
+
+	for _, m := range []*models.Mute{
+		{Kind: models.TargetCategory, Target: "sports", Until: now.Add(2 * time.Hour)},
+		{Kind: models.TargetMarket, Target: "m1", Until: now.Add(time.Hour)},
+		{Kind: models.TargetEvent, Target: "old", Until: now.Add(-time.Minute)},
+	} {
+		if err := s.SaveMute(m); err != nil {
+			t.Fatalf("SaveMute: %v", err)
+		}
+	}
+	mutes, err := s.ListMutes(now)
+	if err != nil || len(mutes) != 2 || mutes[0].Target != "m1" || mutes[1].Target != "sports" {
+		t.Fatalf("ListMutes = %+v, %v", mutes, err)
+	}
+	if n, err := s.ExpireMutes(now); err != nil || n != 1 {
+		t.Errorf("ExpireMutes = %d, %v; want 1", n, err)
+	}
+	if ok, err := s.DeleteMute(models.TargetCategory, "sports"); err != nil || !ok {
+		t.Errorf("DeleteMute = %v, %v; want true", ok, err)
+	}
+	if err := s.SaveMute(&models.Mute{Kind: models.TargetCategory, Target: "x"}); err == nil {
+		t.Error("expected validation error for a mute without an end time")
+	}
+}
+
+func TestStorage_AlertThreads(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Now()
+
+	threads := []models.AlertThread{
+		{ChatID: 1, Key: "e1:m1", MessageID: 10, Direction: "increase", StartProb: 0.4, LastProb: 0.5, StartedAt: now, UpdatedAt: now},
+		{ChatID: 1, Key: "e2:m2|Bob", MessageID: 11, Direction: "decrease", StartProb: 0.6, LastProb: 0.5, StartedAt: now, UpdatedAt: now.Add(-2 * time.Hour)},
+		{ChatID: 2, Key: "e1:m1", MessageID: 12, Direction: "increase", StartProb: 0.4, LastProb: 0.5, StartedAt: now, UpdatedAt: now},
+	}
+	if err := s.SaveAlertThreads(threads); err != nil {
+		t.Fatalf("SaveAlertThreads: %v", err)
+	}
+	threads[0].LastProb, threads[0].MessageID = 0.55, 10
+	if err := s.SaveAlertThreads(threads[:1]); err != nil {
+		t.Fatalf("SaveAlertThreads (update): %v", err)
+	}
+
+	got, err := s.GetAlertThreads(1, []string{"e1:m1", "e2:m2|Bob", "missing"})
+	if err != nil {
+		t.Fatalf("GetAlertThreads: %v", err)
+	}
+	if len(got) != 2 || got["e1:m1"].LastProb != 0.55 || got["e2:m2|Bob"].MessageID != 11 {
+		t.Errorf("unexpected threads: %+v", got)
+	}
+
+	if n, err := s.ExpireAlertThreads(now.Add(-time.Hour)); err != nil || n != 1 {
+		t.Errorf("ExpireAlertThreads = %d, %v; want 1", n, err)
+	}
+	if err := s.SaveAlertThreads([]models.AlertThread{{ChatID: 1, Key: "x"}}); err == nil {
+		t.Error("expected validation error for a thread without a message ID")
+	}
+}
@@ -0,0 +1,350 @@
+// Package stream ingests real-time prices from the Polymarket CLOB WebSocket
+// market channel and writes them as snapshots between polling cycles.
+//
+// The poll loop only sees a market once per poll_interval, so news-driven moves
+// that happen and reverse between polls are invisible. The Ingestor subscribes
+// to book and price_change events for every tracked market's Yes token (or each
+// outcome token of a named-outcome market) and records the top-of-book midpoint
+// as a snapshot, throttled per series.
+//
+// The connection is re-established with exponential backoff on failure, and
+// re-subscribed whenever the tracked set changes (via SetMarkets).
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/polymarket"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+// Source is the snapshot source identifier for streamed prices.
+const Source = "polymarket-clob-ws"
+
+// errResubscribe ends a session because the tracked set changed.
+var errResubscribe = errors.New("tracked markets changed")
+
+// Config holds WebSocket ingestion settings.
+type Config struct {
+	URL                 string        // market channel URL
+	MinSnapshotInterval time.Duration // minimum time between streamed snapshots of one market
+	PingInterval        time.Duration // keep-alive PING interval; reads time out after 3 intervals
+	ReconnectMin        time.Duration // initial reconnect backoff
+	ReconnectMax        time.Duration // maximum reconnect backoff
+}
+
+// Ingestor maintains a market-channel subscription for the tracked markets.
+type Ingestor struct {
+	cfg   Config
+	store *storage.Storage
+
+	mu        sync.Mutex
+	assets    map[string]series    // CLOB token ID → snapshot series
+	lastWrite map[series]time.Time // last streamed snapshot time per series
+	lastMid   map[series]float64   // last streamed midpoint per series
+	changed   chan struct{}        // signalled when assets changes
+}
+
+// series identifies one snapshot series: a market and, for named-outcome
+// markets, one of its outcomes.
+type series struct {
+	marketID string // composite market ID
+	outcome  string // "" for the Yes series of a Yes/No market
+}
+
+// New creates an Ingestor. Call SetMarkets before or after Run to choose what
+// to subscribe to.
+func New(store *storage.Storage, cfg Config) *Ingestor {
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 10 * time.Second
+	}
+	if cfg.ReconnectMin <= 0 {
+		cfg.ReconnectMin = time.Second
+	}
+	if cfg.ReconnectMax < cfg.ReconnectMin {
+		cfg.ReconnectMax = cfg.ReconnectMin
+	}
+	return &Ingestor{
+		cfg:       cfg,
+		store:     store,
+		assets:    make(map[string]series),
+		lastWrite: make(map[series]time.Time),
+		lastMid:   make(map[series]float64),
+		changed:   make(chan struct{}, 1),
+	}
+}
+
+// SetMarkets replaces the tracked set with the given markets' Yes tokens, or
+// every outcome token for named-outcome markets. Tokens that are unknown are
+// ignored. When the set differs from the current one, the active connection is
+// re-subscribed.
+func (in *Ingestor) SetMarkets(markets []*models.Market) {
+	assets := make(map[string]series, len(markets))
+	for _, m := range markets {
+		if m.Closed {
+			continue
+		}
+		if m.IsYesNo() {
+			if m.ClobTokenID != "" {
+				assets[m.ClobTokenID] = series{marketID: m.ID}
+			}
+			continue
+		}
+		for _, o := range m.NamedSeries() {
+			if o.TokenID != "" {
+				assets[o.TokenID] = series{marketID: m.ID, outcome: o.Name}
+			}
+		}
+	}
+
+	in.mu.Lock()
+	same := len(assets) == len(in.assets)
+	if same {
+		for token, id := range assets {
+			if in.assets[token] != id {
+				same = false
+				break
+			}
+		}
+	}
+	if !same {
+		in.assets = assets
+	}
+	in.mu.Unlock()
+
+	if !same {
+		select {
+		case in.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run connects and ingests until ctx is cancelled. It blocks; start it in a goroutine.
+func (in *Ingestor) Run(ctx context.Context) {
+	backoff := in.cfg.ReconnectMin
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		tokens := in.tokenIDs()
+		if len(tokens) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-in.changed:
+				continue
+			}
+		}
+
+		connected, err := in.session(ctx, tokens)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errResubscribe) {
+			logger.Debug("Stream: tracked markets changed, resubscribing")
+			backoff = in.cfg.ReconnectMin
+			continue
+		}
+		if connected {
+			backoff = in.cfg.ReconnectMin
+		}
+		logger.Warn("Stream: connection lost: %v (reconnecting in %v)", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > in.cfg.ReconnectMax {
+			backoff = in.cfg.ReconnectMax
+		}
+	}
+}
+
+func (in *Ingestor) tokenIDs() []string {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	tokens := make([]string, 0, len(in.assets))
+	for token := range in.assets {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// session runs one connection: subscribe, then read until error, ctx
+// cancellation or a tracked-set change. connected reports whether the dial and
+// subscription succeeded (used to reset the backoff).
+func (in *Ingestor) session(ctx context.Context, tokens []string) (connected bool, err error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, in.cfg.URL, nil)
+	if err != nil {
+		return false, fmt.Errorf("dial %s: %w", in.cfg.URL, err)
+	}
+	defer conn.Close()
+
+	sub := map[string]any{"type": "market", "assets_ids": tokens}
+	if err := conn.WriteJSON(sub); err != nil {
+		return false, fmt.Errorf("subscribe: %w", err)
+	}
+	logger.Info("Stream: subscribed to %d tokens", len(tokens))
+
+	// The watcher owns all writes after the subscription: keep-alive pings,
+	// and closing the connection to unblock the reader on ctx/resubscribe.
+	done := make(chan struct{})
+	var stopReason error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(in.cfg.PingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				_ = conn.Close()
+				return
+			case <-in.changed:
+				stopReason = errResubscribe
+				_ = conn.Close()
+				return
+			case <-ticker.C:
+				_ = conn.SetWriteDeadline(time.Now().Add(in.cfg.PingInterval))
+				if err := conn.WriteMessage(websocket.TextMessage, []byte("PING")); err != nil {
+					_ = conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	readErr := in.readLoop(conn)
+	close(done)
+	wg.Wait()
+	if stopReason != nil {
+		return true, stopReason
+	}
+	return true, readErr
+}
+
+func (in *Ingestor) readLoop(conn *websocket.Conn) error {
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(3 * in.cfg.PingInterval))
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if string(data) == "PONG" {
+			continue
+		}
+		in.handleMessage(data, time.Now())
+	}
+}
+
+// wsEvent is the subset of market-channel event fields used for pricing.
+type wsEvent struct {
+	EventType    string                  `json:"event_type"`
+	AssetID      string                  `json:"asset_id"`
+	Bids         []polymarket.OrderLevel `json:"bids"`
+	Asks         []polymarket.OrderLevel `json:"asks"`
+	PriceChanges []struct {
+		AssetID string `json:"asset_id"`
+		BestBid string `json:"best_bid"`
+		BestAsk string `json:"best_ask"`
+	} `json:"price_changes"`
+}
+
+// handleMessage decodes a frame (a single event or an array of events) and
+// records a snapshot for each priced event. Undecodable frames are ignored.
+func (in *Ingestor) handleMessage(data []byte, now time.Time) {
+	var events []wsEvent
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &events); err != nil {
+			logger.Debug("Stream: ignoring undecodable frame: %v", err)
+			return
+		}
+	} else {
+		var ev wsEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			logger.Debug("Stream: ignoring undecodable frame: %v", err)
+			return
+		}
+		events = []wsEvent{ev}
+	}
+
+	for _, ev := range events {
+		switch ev.EventType {
+		case "book":
+			book := polymarket.OrderBook{AssetID: ev.AssetID, Bids: ev.Bids, Asks: ev.Asks}
+			if stats, ok := book.Stats(); ok {
+				in.record(ev.AssetID, stats, now)
+			}
+		case "price_change":
+			for _, pc := range ev.PriceChanges {
+				bid, errBid := strconv.ParseFloat(pc.BestBid, 64)
+				ask, errAsk := strconv.ParseFloat(pc.BestAsk, 64)
+				if errBid != nil || errAsk != nil || ask < bid {
+					continue
+				}
+				in.record(pc.AssetID, polymarket.BookStats{
+					BestBid:  bid,
+					BestAsk:  ask,
+					Spread:   ask - bid,
+					Midpoint: (bid + ask) / 2,
+				}, now)
+			}
+		}
+	}
+}
+
+// record writes a snapshot for the series owning assetID, at most once per
+// MinSnapshotInterval and only when the midpoint moved since the last write.
+func (in *Ingestor) record(assetID string, stats polymarket.BookStats, now time.Time) {
+	in.mu.Lock()
+	sr, ok := in.assets[assetID]
+	if !ok {
+		in.mu.Unlock()
+		return
+	}
+	if last, seen := in.lastWrite[sr]; seen {
+		if now.Sub(last) < in.cfg.MinSnapshotInterval || in.lastMid[sr] == stats.Midpoint {
+			in.mu.Unlock()
+			return
+		}
+	}
+	in.lastWrite[sr] = now
+	in.lastMid[sr] = stats.Midpoint
+	in.mu.Unlock()
+
+	snapshot := &models.Snapshot{
+		ID:             uuid.New().String(),
+		EventID:        sr.marketID,
+		Outcome:        sr.outcome,
+		YesProbability: stats.Midpoint,
+		NoProbability:  1 - stats.Midpoint,
+		BestBid:        stats.BestBid,
+		BestAsk:        stats.BestAsk,
+		Spread:         stats.Spread,
+		Midpoint:       stats.Midpoint,
+		BidDepth:       stats.BidDepth,
+		AskDepth:       stats.AskDepth,
+		Timestamp:      now,
+		Source:         Source,
+	}
+	if err := in.store.AddSnapshot(snapshot); err != nil {
+		logger.Warn("Stream: failed to add snapshot for market %s: %v", sr.marketID, err)
+	}
+}
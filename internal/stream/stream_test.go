@@ -0,0 +1,224 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+func mustStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	s, err := storage.New(100, 100, ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func testMarket(id, tokenID string) *models.Market {
+	now := time.Now()
+	return &models.Market{
+		ID:             id,
+		EventID:        "event",
+		MarketID:       id,
+		ClobTokenID:    tokenID,
+		Title:          "Test",
+		Category:       "test",
+		YesProbability: 0.5,
+		NoProbability:  0.5,
+		LastUpdated:    now,
+		CreatedAt:      now,
+	}
+}
+
+// wsServer is a local stand-in for the market channel. Each accepted
+// connection reports its subscription on subs and then runs script.
+type wsServer struct {
+	*httptest.Server
+	subs chan []string
+}
+
+func newWSServer(t *testing.T, script func(conn *websocket.Conn, n int)) *wsServer {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	s := &wsServer{subs: make(chan []string, 10)}
+	var n atomic.Int32
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		var sub struct {
+			Type      string   `json:"type"`
+			AssetsIDs []string `json:"assets_ids"`
+		}
+		if err := conn.ReadJSON(&sub); err != nil {
+			return
+		}
+		if sub.Type != "market" {
+			t.Errorf("Expected subscription type market, got %q", sub.Type)
+		}
+		sort.Strings(sub.AssetsIDs)
+		s.subs <- sub.AssetsIDs
+
+		script(conn, int(n.Add(1)))
+	}))
+	return s
+}
+
+func wsURL(s *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// drain keeps reading until the client closes the connection.
+func drain(conn *websocket.Conn) {
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met before deadline")
+}
+
+func TestIngestor_WritesSnapshotsFromEvents(t *testing.T) {
+	store := mustStorage(t)
+	for _, m := range []*models.Market{testMarket("e:m1", "tok-1"), testMarket("e:m2", "tok-2")} {
+		if err := store.AddMarket(m); err != nil {
+			t.Fatalf("AddMarket: %v", err)
+		}
+	}
+
+	server := newWSServer(t, func(conn *websocket.Conn, _ int) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`[{"event_type":"book","asset_id":"tok-1",`+
+			`"bids":[{"price":"0.40","size":"100"}],"asks":[{"price":"0.44","size":"50"}]}]`))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"event_type":"price_change","price_changes":[`+
+			`{"asset_id":"tok-2","best_bid":"0.70","best_ask":"0.72"},`+
+			`{"asset_id":"tok-unknown","best_bid":"0.10","best_ask":"0.12"}]}`))
+		drain(conn)
+	})
+	defer server.Close()
+
+	in := New(store, Config{URL: wsURL(server.Server), MinSnapshotInterval: time.Minute})
+	in.SetMarkets([]*models.Market{testMarket("e:m1", "tok-1"), testMarket("e:m2", "tok-2")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go in.Run(ctx)
+
+	if sub := <-server.subs; len(sub) != 2 || sub[0] != "tok-1" || sub[1] != "tok-2" {
+		t.Errorf("Unexpected subscription: %v", sub)
+	}
+
+	waitFor(t, func() bool {
+		a, _ := store.GetSnapshots("e:m1")
+		b, _ := store.GetSnapshots("e:m2")
+		return len(a) == 1 && len(b) == 1
+	})
+
+	snaps, _ := store.GetSnapshots("e:m1")
+	if snaps[0].Source != Source {
+		t.Errorf("Expected source %q, got %q", Source, snaps[0].Source)
+	}
+	if snaps[0].YesProbability < 0.419 || snaps[0].YesProbability > 0.421 {
+		t.Errorf("Expected midpoint 0.42, got %f", snaps[0].YesProbability)
+	}
+	if snaps[0].BidDepth != 40 {
+		t.Errorf("Expected bid depth 40 from book event, got %f", snaps[0].BidDepth)
+	}
+	snaps, _ = store.GetSnapshots("e:m2")
+	if snaps[0].Spread < 0.019 || snaps[0].Spread > 0.021 {
+		t.Errorf("Expected spread 0.02 from price_change event, got %f", snaps[0].Spread)
+	}
+}
+
+func TestIngestor_ReconnectsAndResubscribes(t *testing.T) {
+	store := mustStorage(t)
+
+	server := newWSServer(t, func(conn *websocket.Conn, n int) {
+		if n == 1 {
+			return // drop the first connection immediately to force a reconnect
+		}
+		drain(conn)
+	})
+	defer server.Close()
+
+	in := New(store, Config{
+		URL:          wsURL(server.Server),
+		ReconnectMin: 10 * time.Millisecond,
+		ReconnectMax: 50 * time.Millisecond,
+	})
+	in.SetMarkets([]*models.Market{testMarket("e:m1", "tok-1")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go in.Run(ctx)
+
+	recv := func() []string {
+		select {
+		case sub := <-server.subs:
+			return sub
+		case <-time.After(3 * time.Second):
+			t.Fatal("timed out waiting for subscription")
+			return nil
+		}
+	}
+
+	if sub := recv(); len(sub) != 1 || sub[0] != "tok-1" {
+		t.Errorf("Unexpected first subscription: %v", sub)
+	}
+	if sub := recv(); len(sub) != 1 || sub[0] != "tok-1" {
+		t.Errorf("Expected reconnect with same subscription, got %v", sub)
+	}
+
+	in.SetMarkets([]*models.Market{testMarket("e:m1", "tok-1"), testMarket("e:m3", "tok-3")})
+	if sub := recv(); len(sub) != 2 || sub[1] != "tok-3" {
+		t.Errorf("Expected resubscription with new token, got %v", sub)
+	}
+}
+
+func TestIngestor_ThrottlesPerMarket(t *testing.T) {
+	store := mustStorage(t)
+	if err := store.AddMarket(testMarket("e:m1", "tok-1")); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+	in := New(store, Config{MinSnapshotInterval: time.Minute})
+	in.SetMarkets([]*models.Market{testMarket("e:m1", "tok-1")})
+
+	frame := func(bid, ask string) []byte {
+		return []byte(`{"event_type":"price_change","price_changes":[{"asset_id":"tok-1","best_bid":"` + bid + `","best_ask":"` + ask + `"}]}`)
+	}
+	now := time.Now().Add(-10 * time.Minute)
+	in.handleMessage(frame("0.40", "0.42"), now)
+	in.handleMessage(frame("0.45", "0.47"), now.Add(30*time.Second)) // within interval: dropped
+	in.handleMessage(frame("0.40", "0.42"), now.Add(2*time.Minute))  // unchanged midpoint: dropped
+	in.handleMessage(frame("0.50", "0.52"), now.Add(3*time.Minute))  // written
+
+	snaps, _ := store.GetSnapshots("e:m1")
+	if len(snaps) != 2 {
+		t.Fatalf("Expected 2 throttled snapshots, got %d", len(snaps))
+	}
+}
@@ -0,0 +1,270 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rewired-gh/polyoracle/internal/chart"
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// Inline keyboard actions. Callback data is "<action>:<ref>", where ref is the
+// Polymarket event ID for mute and watch, and the ID of the group's
+// best-scoring change for explain and chart.
+const (
+	actionMute    = "mute"
+	actionWatch   = "watch"
+	actionExplain = "explain"
+	actionChart   = "chart"
+
+	callbackDataLimit = 64 // bytes, set by the Bot API
+)
+
+// alertKeyboard returns one row of action buttons per event group; groups
+// start at index first of the alert, and labels carry the entry number when
+// numbered. Mute and Watch change the routing of every alert, so they are only
+// offered in the configured chat (withRouting).
+func alertKeyboard(groups []models.Event, first int, numbered, withRouting bool) *tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, group := range groups {
+		prefix := ""
+		if numbered {
+			prefix = fmt.Sprintf("%d· ", first+i+1)
+		}
+		var row []tgbotapi.InlineKeyboardButton
+		add := func(label, action, ref string) {
+			data := action + ":" + ref
+			if ref == "" || len(data) > callbackDataLimit {
+				return
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(prefix+label, data))
+		}
+		if withRouting {
+			add("🔕 Mute 24h", actionMute, group.ID)
+			add("👁 Watch", actionWatch, group.ID)
+		}
+		if len(group.Markets) > 0 {
+			add("❓ Explain", actionExplain, group.Markets[0].ID)
+			add("📊 Chart", actionChart, group.Markets[0].ID)
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &markup
+}
+
+// handleCallback performs the action of a pressed alert button. Mute and watch
+// are confirmed in a toast and on the button itself; explain and chart answer
+// with a reply to the alert (the chart as a photo).
+func (c *Client) handleCallback(q *tgbotapi.CallbackQuery) {
+	action, ref, _ := strings.Cut(q.Data, ":")
+	if q.Message == nil || q.Message.Chat == nil {
+		c.answerCallback(q, "")
+		return
+	}
+	if !c.chatAllowed(q.Message.Chat.ID) {
+		c.answerCallback(q, notAllowedReply)
+		return
+	}
+	if c.store == nil {
+		c.answerCallback(q, "Actions are not available on this bot.")
+		return
+	}
+	chatID := q.Message.Chat.ID
+	now := time.Now()
+
+	switch action {
+	case actionMute, actionWatch:
+		if chatID != c.chatID {
+			c.answerCallback(q, "Watches and mutes can only be changed from the main chat.")
+			return
+		}
+		var toast, label string
+		var err error
+		if action == actionMute {
+			m := &models.Mute{Kind: models.TargetEvent, Target: ref, Until: now.Add(defaultMuteDuration), CreatedAt: now}
+			err = c.store.SaveMute(m)
+			toast = "Muted this event until " + m.Until.Format("2006-01-02 15:04")
+			label = "🔕 Muted"
+		} else {
+			err = c.store.SaveWatch(&models.Watch{Kind: models.TargetEvent, Target: ref})
+			toast = "Watching this event: " + describeThreshold(0)
+			label = "👁 Watching"
+		}
+		if err != nil {
+			logger.Error("Failed to apply %s action for event %s: %v", action, ref, err)
+			c.answerCallback(q, errorReply)
+			return
+		}
+		c.answerCallback(q, toast)
+		c.relabelButton(q, label)
+	case actionExplain, actionChart:
+		change, err := c.store.GetChange(ref)
+		if err != nil {
+			logger.Error("Failed to load change %s: %v", ref, err)
+			c.answerCallback(q, errorReply)
+			return
+		}
+		if change == nil {
+			c.answerCallback(q, "This alert is no longer in the history.")
+			return
+		}
+		c.answerCallback(q, "")
+		var reply tgbotapi.Chattable
+		if action == actionExplain {
+			reply = replyText(chatID, q.Message.MessageID, explainChange(change))
+		} else {
+			reply = c.changeChartReply(chatID, q.Message.MessageID, change, now)
+		}
+		if _, err := c.bot.Send(reply); err != nil {
+			logger.Warn("Failed to answer %s action: %v", action, err)
+		}
+	default:
+		c.answerCallback(q, "")
+	}
+}
+
+func (c *Client) answerCallback(q *tgbotapi.CallbackQuery, text string) {
+	if _, err := c.bot.Request(tgbotapi.NewCallback(q.ID, text)); err != nil {
+		logger.Warn("Failed to answer callback query: %v", err)
+	}
+}
+
+// relabelButton replaces the label of the pressed button so the alert shows
+// that the action was taken.
+func (c *Client) relabelButton(q *tgbotapi.CallbackQuery, label string) {
+	markup := q.Message.ReplyMarkup
+	if markup == nil {
+		return
+	}
+	changed := false
+	for _, row := range markup.InlineKeyboard {
+		for i, button := range row {
+			if button.CallbackData != nil && *button.CallbackData == q.Data {
+				if prefix, _, ok := strings.Cut(button.Text, "· "); ok {
+					label = prefix + "· " + label
+				}
+				row[i].Text = label
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return
+	}
+	edit := tgbotapi.NewEditMessageReplyMarkup(q.Message.Chat.ID, q.Message.MessageID, *markup)
+	if _, err := c.bot.Request(edit); err != nil {
+		logger.Warn("Failed to update alert buttons: %v", err)
+	}
+}
+
+// explainChange describes how a change was scored: its factors, the
+// thresholds and pre-filters applied and any watch or mute that decided it.
+func explainChange(ch *models.Change) string {
+	var b strings.Builder
+	b.WriteString(ch.EventTitle + "\n")
+	if ch.MarketQuestion != "" && ch.MarketQuestion != ch.EventTitle {
+		fmt.Fprintf(&b, "🎯 %s\n", ch.MarketQuestion)
+	}
+	if ch.Outcome != "" {
+		fmt.Fprintf(&b, "🏷 %s\n", ch.Outcome)
+	}
+	fmt.Fprintf(&b, "\n%s %.1f%% (%.1f%% → %.1f%%) over %s, detected %s\n",
+		directionEmoji(ch.Direction), ch.Magnitude*100, ch.OldProbability*100, ch.NewProbability*100,
+		formatDuration(ch.TimeWindow), ch.DetectedAt.Format("2006-01-02 15:04"))
+
+	verdict := "below the alert bar"
+	if ch.PassedThreshold {
+		verdict = "passed the alert bar"
+	}
+	bd := ch.Breakdown
+	if bd == nil {
+		fmt.Fprintf(&b, "Signal score: %.4f (%s)\n", ch.SignalScore, verdict)
+		b.WriteString("No breakdown was recorded for this change.\n")
+		b.WriteString("score = KL divergence × volume weight × historical SNR × trajectory consistency × spread weight")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Signal score: %.4f (%s %.4f)\n\n", ch.SignalScore, verdict, bd.MinScore)
+	fmt.Fprintf(&b, "KL divergence: %.4f\n", bd.KL)
+	fmt.Fprintf(&b, "× Volume weight: %.2f\n", bd.VolumeWeight)
+	fmt.Fprintf(&b, "× Historical SNR: %.2f\n", bd.SNR)
+	fmt.Fprintf(&b, "× Trajectory consistency: %.2f\n", bd.TrajectoryConsistency)
+	fmt.Fprintf(&b, "× Spread weight: %.2f\n", bd.SpreadWeight)
+	if bd.HistoryUnavailable {
+		b.WriteString("(history failed to load: SNR and trajectory defaulted to 1)\n")
+	}
+
+	var filters []string
+	if bd.MinAbsChange > 0 {
+		f := fmt.Sprintf("min change %.1fpp", bd.MinAbsChange*100)
+		if bd.ConfirmationBypass {
+			f += " (bypassed: entered the >95%/<5% confirmation zone)"
+		}
+		filters = append(filters, f)
+	}
+	if bd.MinBaseProb > 0 {
+		filters = append(filters, fmt.Sprintf("min base probability %.1f%%", bd.MinBaseProb*100))
+	}
+	if bd.WatchBypass {
+		filters = append(filters, "bypassed for a watched market")
+	}
+	if len(filters) > 0 {
+		fmt.Fprintf(&b, "\nPre-filters passed: %s\n", strings.Join(filters, ", "))
+	}
+	switch bd.Routing {
+	case models.RoutingWatch:
+		fmt.Fprintf(&b, "Routing: alerted through a watch (%s)\n", describeThreshold(bd.WatchThreshold))
+	case models.RoutingMute:
+		b.WriteString("Routing: silenced by a mute\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// breakdownLine summarises a score breakdown on one line for alerts.
+func breakdownLine(score float64, bd *models.ScoreBreakdown) string {
+	line := fmt.Sprintf("KL %.4f × vol %.2f × SNR %.2f × TC %.2f × spread %.2f = %.4f (bar %.4f)",
+		bd.KL, bd.VolumeWeight, bd.SNR, bd.TrajectoryConsistency, bd.SpreadWeight, score, bd.MinScore)
+	if bd.ConfirmationBypass {
+		line += ", confirmation bypass"
+	}
+	if bd.WatchBypass {
+		line += ", watch bypass"
+	}
+	if bd.Routing == models.RoutingWatch {
+		line += ", watched"
+	}
+	return line
+}
+
+// changeChartReply returns the chart of a change as a photo replying to
+// messageID, or a text reply when it cannot be drawn.
+func (c *Client) changeChartReply(chatID int64, messageID int, change *models.Change, now time.Time) tgbotapi.Chattable {
+	png, err := c.renderChangeChart(change, now)
+	if errors.Is(err, chart.ErrNotEnoughData) {
+		return replyText(chatID, messageID, "Not enough price history to chart yet.")
+	}
+	if err != nil {
+		logger.Error("Failed to render chart for %s: %v", change.EventID, err)
+		return replyText(chatID, messageID, errorReply)
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: png})
+	photo.Caption = chartCaption(change.EventTitle, change.MarketQuestion, change.Outcome, change.TimeWindow)
+	photo.ReplyToMessageID = messageID
+	return photo
+}
+
+func replyText(chatID int64, messageID int, text string) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyToMessageID = messageID
+	return msg
+}
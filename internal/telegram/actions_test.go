@@ -0,0 +1,123 @@
+package telegram
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+func TestAlertKeyboard(t *testing.T) {
+	groups := []models.Event{
+		{ID: "e1", Markets: []models.Change{{ID: "c1"}}},
+		{ID: "e2", Markets: []models.Change{{ID: "c2"}, {ID: "c3"}}},
+	}
+	kb := alertKeyboard(groups, 0, true, true)
+	if kb == nil || len(kb.InlineKeyboard) != 2 {
+		t.Fatalf("expected one row per group, got %+v", kb)
+	}
+	row := kb.InlineKeyboard[1]
+	var data []string
+	for _, b := range row {
+		data = append(data, *b.CallbackData)
+	}
+	if got := strings.Join(data, " "); got != "mute:e2 watch:e2 explain:c2 chart:c2" {
+		t.Errorf("unexpected callback data: %s", got)
+	}
+	if !strings.HasPrefix(row[0].Text, "2· ") {
+		t.Errorf("buttons should be numbered when there are several groups: %q", row[0].Text)
+	}
+
+	kb = alertKeyboard(groups[:1], 0, false, false)
+	if len(kb.InlineKeyboard[0]) != 2 || strings.Contains(kb.InlineKeyboard[0][0].Text, "·") {
+		t.Errorf("subscriber keyboard should only explain and chart, unnumbered: %+v", kb.InlineKeyboard[0])
+	}
+	if alertKeyboard([]models.Event{{ID: strings.Repeat("x", 70)}}, 0, false, true) != nil {
+		t.Error("buttons whose callback data exceeds 64 bytes should be dropped")
+	}
+}
+
+func TestHandleCallback(t *testing.T) {
+	bot, api := newFakeBot(t)
+	store := mustStorage(t)
+	c, err := newClient(bot, "100", Options{MaxRetries: 1, RetryDelayBase: time.Millisecond, Store: store})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+
+	now := time.Now()
+	market := &models.Market{
+		ID: "e1:m1", EventID: "e1", MarketID: "m1", Title: "Bitcoin above 100k?", Category: "crypto",
+		YesProbability: 0.6, NoProbability: 0.4, Active: true, LastUpdated: now, CreatedAt: now.Add(-time.Hour),
+	}
+	if err := store.AddMarket(market); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+	if _, err := store.AddSnapshots([]models.Snapshot{
+		{ID: "s1", EventID: "e1:m1", YesProbability: 0.4, NoProbability: 0.6, Timestamp: now.Add(-2 * time.Hour), Source: "test"},
+		{ID: "s2", EventID: "e1:m1", YesProbability: 0.6, NoProbability: 0.4, Timestamp: now.Add(-time.Minute), Source: "test"},
+	}); err != nil {
+		t.Fatalf("AddSnapshots: %v", err)
+	}
+	change := models.Change{
+		ID: "c1", EventID: "e1:m1", OriginalEventID: "e1", EventTitle: "Bitcoin above 100k?", MarketID: "m1",
+		Magnitude: 0.2, Direction: "increase", OldProbability: 0.4, NewProbability: 0.6,
+		TimeWindow: time.Hour, DetectedAt: now, SignalScore: 0.12, PassedThreshold: true,
+	}
+	if err := store.AddChange(&change); err != nil {
+		t.Fatalf("AddChange: %v", err)
+	}
+	groups := []models.Event{{ID: "e1", Title: change.EventTitle, Markets: []models.Change{change}}}
+	if _, err := c.Send(groups); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(api.lastCall("sendMessage").Get("reply_markup")), &markup); err != nil {
+		t.Fatalf("alert has no inline keyboard: %v", err)
+	}
+
+	press := func(chatID int64, data string) {
+		c.handleCallback(&tgbotapi.CallbackQuery{
+			ID: "q", Data: data,
+			Message: &tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: chatID}, ReplyMarkup: &markup},
+		})
+	}
+
+	press(100, "mute:e1")
+	if mutes, _ := store.ListMutes(now); len(mutes) != 1 || mutes[0].Target != "e1" || mutes[0].Kind != models.TargetEvent {
+		t.Errorf("mute button did not mute the event: %+v", mutes)
+	}
+	if got := api.lastCall("answerCallbackQuery").Get("text"); !strings.HasPrefix(got, "Muted this event") {
+		t.Errorf("unexpected toast: %q", got)
+	}
+	if edit := api.lastCall("editMessageReplyMarkup"); edit == nil || !strings.Contains(edit.Get("reply_markup"), "Muted") {
+		t.Errorf("mute button should be relabelled, got %v", edit)
+	}
+
+	press(200, "watch:e1")
+	if watches, _ := store.ListWatches(); len(watches) != 0 {
+		t.Errorf("watch from another chat should be refused: %+v", watches)
+	}
+
+	press(100, "explain:c1")
+	if msgs := api.messages(100); !strings.Contains(msgs[len(msgs)-1], "Signal score: 0.1200 (passed the alert bar)") {
+		t.Errorf("unexpected explanation: %q", msgs[len(msgs)-1])
+	}
+	if reply := api.lastCall("sendMessage").Get("reply_to_message_id"); reply != "1" {
+		t.Errorf("explanation should reply to the alert, got reply_to_message_id=%q", reply)
+	}
+
+	press(100, "chart:c1")
+	if photo := api.lastCall("sendPhoto"); photo == nil || photo.Get("reply_to_message_id") != "1" ||
+		!strings.Contains(photo.Get("caption"), "Shaded: detection window (1h)") {
+		t.Errorf("chart should be a photo replying to the alert, got %v", photo)
+	}
+
+	press(100, "chart:missing")
+	if got := api.lastCall("answerCallbackQuery").Get("text"); !strings.Contains(got, "no longer in the history") {
+		t.Errorf("unexpected toast for a missing change: %q", got)
+	}
+}
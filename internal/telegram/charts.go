@@ -0,0 +1,191 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rewired-gh/polyoracle/internal/chart"
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+const (
+	chartLookback   = 24 * time.Hour // minimum history drawn around a change
+	maxAlbum        = 10             // photos per media group, set by the Bot API
+	maxCaptionRunes = 1024
+)
+
+// alertChart is the rendered chart of the best-scoring change of one event group.
+type alertChart struct {
+	changeID string
+	caption  string
+	png      []byte
+}
+
+// renderAlertCharts renders one chart per event group, for its best-scoring
+// change, when charts are enabled. Groups without enough history are skipped.
+func (c *Client) renderAlertCharts(groups []models.Event, now time.Time) []alertChart {
+	if !c.charts || c.store == nil {
+		return nil
+	}
+	var charts []alertChart
+	for _, group := range groups {
+		if len(group.Markets) == 0 || len(charts) == maxAlbum {
+			continue
+		}
+		change := &group.Markets[0]
+		png, err := c.renderChangeChart(change, now)
+		if err != nil {
+			if !errors.Is(err, chart.ErrNotEnoughData) {
+				logger.Warn("Failed to render chart for %s: %v", change.EventID, err)
+			}
+			continue
+		}
+		caption := chartCaption(change.EventTitle, change.MarketQuestion, change.Outcome, change.TimeWindow)
+		charts = append(charts, alertChart{changeID: change.ID, caption: caption, png: png})
+	}
+	return charts
+}
+
+// renderChangeChart draws the price series a change was detected on, with its
+// detection window shaded.
+func (c *Client) renderChangeChart(change *models.Change, now time.Time) ([]byte, error) {
+	lookback := max(chartLookback, 4*change.TimeWindow)
+	series, err := c.store.GetOutcomeSnapshotsInWindow(change.EventID, change.Outcome, lookback)
+	if err != nil {
+		return nil, err
+	}
+	return chart.Render(series, chart.Options{Window: change.TimeWindow, End: now})
+}
+
+// chartsFor returns the charts belonging to groups, in order. When groups
+// form a numbered alert, captions carry the number of their entry.
+func chartsFor(charts []alertChart, groups []models.Event) []alertChart {
+	entries := make(map[string]int, len(groups))
+	for i, g := range groups {
+		if len(g.Markets) > 0 {
+			entries[g.Markets[0].ID] = i + 1
+		}
+	}
+	var result []alertChart
+	for _, ch := range charts {
+		n, ok := entries[ch.changeID]
+		if !ok {
+			continue
+		}
+		if len(groups) > 1 {
+			ch.caption = truncateCaption(fmt.Sprintf("%d. %s", n, ch.caption))
+		}
+		result = append(result, ch)
+	}
+	return result
+}
+
+// sendCharts sends charts to chatID in reply to the (first) alert message of
+// replyTo, as returned by sendAlert: a single photo, or a media group for
+// several. Charts are supplementary, so failures are logged and not retried.
+func (c *Client) sendCharts(chatID int64, charts []alertChart, replyTo string) {
+	first, _, _ := strings.Cut(replyTo, ",")
+	replyToID, _ := strconv.Atoi(first)
+	var err error
+	switch len(charts) {
+	case 0:
+		return
+	case 1:
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: charts[0].png})
+		photo.Caption = charts[0].caption
+		photo.ReplyToMessageID = replyToID
+		_, err = c.bot.Send(photo)
+	default:
+		media := make([]any, len(charts))
+		for i, ch := range charts {
+			photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileBytes{Name: fmt.Sprintf("chart%d.png", i+1), Bytes: ch.png})
+			photo.Caption = ch.caption
+			media[i] = photo
+		}
+		album := tgbotapi.NewMediaGroup(chatID, media)
+		album.ReplyToMessageID = replyToID
+		_, err = c.bot.SendMediaGroup(album)
+	}
+	if err != nil {
+		logger.Warn("Failed to send %d chart(s) to chat %d: %v", len(charts), chatID, err)
+	}
+}
+
+// sendMarketChart answers /chart <query> with the last 24h of the best
+// matching tracked market.
+func (c *Client) sendMarketChart(chatID int64, args string) {
+	reply := func(text string) {
+		c.bot.Send(tgbotapi.NewMessage(chatID, text)) //nolint:errcheck
+	}
+	if c.store == nil {
+		reply("Charts are not available on this bot.")
+		return
+	}
+	query := strings.TrimSpace(args)
+	if query == "" {
+		reply("Usage: /chart <search text>, e.g. /chart bitcoin")
+		return
+	}
+	markets, err := c.store.SearchMarkets(query, 1)
+	if err != nil {
+		logger.Error("Failed to search markets: %v", err)
+		reply(errorReply)
+		return
+	}
+	if len(markets) == 0 {
+		reply(fmt.Sprintf("No tracked market matches %q.", query))
+		return
+	}
+	m := markets[0]
+	outcome := m.TrackedOutcomes()[0]
+	series, err := c.store.GetOutcomeSnapshotsInWindow(m.ID, outcome, chartLookback)
+	if err != nil {
+		logger.Error("Failed to load snapshots for %s: %v", m.ID, err)
+		reply(errorReply)
+		return
+	}
+	png, err := chart.Render(series, chart.Options{End: time.Now()})
+	if errors.Is(err, chart.ErrNotEnoughData) {
+		reply("Not enough price history to chart " + m.Title + " yet.")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to render chart for %s: %v", m.ID, err)
+		reply(errorReply)
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: png})
+	photo.Caption = chartCaption(m.Title, m.MarketQuestion, outcome, 0) + "\nLast 24h"
+	if _, err := c.bot.Send(photo); err != nil {
+		logger.Warn("Failed to send chart to chat %d: %v", chatID, err)
+	}
+}
+
+// chartCaption names the charted series; window, when set, is explained as
+// the shaded area.
+func chartCaption(title, question, outcome string, window time.Duration) string {
+	caption := title
+	if question != "" && question != title {
+		caption += "\n🎯 " + question
+	}
+	if outcome != "" {
+		caption += "\n🏷 " + outcome
+	}
+	if window > 0 {
+		caption += "\nShaded: detection window (" + formatDuration(window) + ")"
+	}
+	return truncateCaption(caption)
+}
+
+// truncateCaption shortens caption to Telegram's caption limit.
+func truncateCaption(caption string) string {
+	if r := []rune(caption); len(r) > maxCaptionRunes {
+		caption = string(r[:maxCaptionRunes-1]) + "…"
+	}
+	return caption
+}
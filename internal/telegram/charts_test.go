@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+func TestSend_AttachesCharts(t *testing.T) {
+	bot, api := newFakeBot(t)
+	store := mustStorage(t)
+	c, err := newClient(bot, "100", Options{MaxRetries: 1, RetryDelayBase: time.Millisecond, AllowedChatIDs: []int64{200}, Store: store, Charts: true})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+
+	now := time.Now()
+	var groups []models.Event
+	for _, id := range []string{"e1", "e2", "e3"} {
+		market := &models.Market{
+			ID: id + ":m", EventID: id, MarketID: "m", Title: "Event " + id, Category: "crypto",
+			YesProbability: 0.6, NoProbability: 0.4, Active: true, LastUpdated: now, CreatedAt: now.Add(-time.Hour),
+		}
+		if err := store.AddMarket(market); err != nil {
+			t.Fatalf("AddMarket: %v", err)
+		}
+		if id != "e3" { // e3 has no history to chart
+			if _, err := store.AddSnapshots([]models.Snapshot{
+				{ID: id + "-1", EventID: market.ID, YesProbability: 0.4, NoProbability: 0.6, Timestamp: now.Add(-time.Hour), Source: "test"},
+				{ID: id + "-2", EventID: market.ID, YesProbability: 0.6, NoProbability: 0.4, Timestamp: now.Add(-time.Minute), Source: "test"},
+			}); err != nil {
+				t.Fatalf("AddSnapshots: %v", err)
+			}
+		}
+		groups = append(groups, models.Event{ID: id, Title: market.Title, Category: id, BestScore: 1, Markets: []models.Change{{
+			ID: "c-" + id, EventID: market.ID, EventTitle: market.Title, Direction: "increase",
+			OldProbability: 0.4, NewProbability: 0.6, Magnitude: 0.2, TimeWindow: 45 * time.Minute, DetectedAt: now,
+		}}})
+	}
+	if err := store.SaveSubscription(&models.Subscription{ChatID: 200, Categories: []string{"e2"}}); err != nil {
+		t.Fatalf("SaveSubscription: %v", err)
+	}
+
+	messageID, err := c.Send(groups)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	album := api.lastCall("sendMediaGroup")
+	if album == nil || album.Get("chat_id") != "100" || album.Get("reply_to_message_id") != messageID {
+		t.Fatalf("expected a media group replying to alert %s, got %v", messageID, album)
+	}
+	if media := album.Get("media"); strings.Count(media, `"type":"photo"`) != 2 || !strings.Contains(media, "2. Event e2") {
+		t.Errorf("album should hold the two chartable groups: %s", media)
+	}
+
+	photo := api.lastCall("sendPhoto")
+	if photo == nil || photo.Get("chat_id") != "200" || !strings.HasPrefix(photo.Get("caption"), "Event e2") {
+		t.Errorf("subscriber should get the chart of its matching group as a photo, got %v", photo)
+	}
+}
+
+func TestChartCommand(t *testing.T) {
+	bot, api := newFakeBot(t)
+	store := mustStorage(t)
+	c, err := newClient(bot, "100", Options{AllowedChatIDs: []int64{300}, Store: store})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	now := time.Now()
+	if err := store.AddMarket(&models.Market{
+		ID: "e1:m1", EventID: "e1", MarketID: "m1", Title: "Bitcoin above 100k?", Category: "crypto",
+		YesProbability: 0.6, NoProbability: 0.4, Active: true, LastUpdated: now, CreatedAt: now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+
+	chart := func(args string) {
+		text := "/chart " + args
+		c.handleCommand(&tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: 300},
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/chart")}},
+		})
+	}
+	chart("bitcoin")
+	if msgs := api.messages(300); len(msgs) != 1 || !strings.Contains(msgs[0], "Not enough price history") {
+		t.Errorf("expected a not-enough-history reply, got %v", msgs)
+	}
+
+	if _, err := store.AddSnapshots([]models.Snapshot{
+		{ID: "s1", EventID: "e1:m1", YesProbability: 0.5, NoProbability: 0.5, Timestamp: now.Add(-time.Hour), Source: "test"},
+		{ID: "s2", EventID: "e1:m1", YesProbability: 0.6, NoProbability: 0.4, Timestamp: now.Add(-time.Minute), Source: "test"},
+	}); err != nil {
+		t.Fatalf("AddSnapshots: %v", err)
+	}
+	chart("bitcoin")
+	if photo := api.lastCall("sendPhoto"); photo == nil || !strings.HasPrefix(photo.Get("caption"), "Bitcoin above 100k?") {
+		t.Errorf("expected a chart photo, got %v", photo)
+	}
+}
@@ -8,35 +8,80 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rewired-gh/polyoracle/internal/logger"
 	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/status"
+	"github.com/rewired-gh/polyoracle/internal/storage"
 )
 
-// Client handles Telegram notifications
+// Client handles Telegram notifications. It implements notify.Notifier.
+//
+// Every alert goes to the configured chat. When a store is set, allowed chats
+// that subscribe through bot commands also receive the alerts matching their
+// filters.
 type Client struct {
 	bot            *tgbotapi.BotAPI
 	chatID         int64
+	allowedChats   map[int64]bool // chats besides chatID that may use commands
 	maxRetries     int
 	retryDelayBase time.Duration
+	store          *storage.Storage // nil = no subscriptions or data commands
+	status         *status.Tracker  // nil = no /status
+	charts         bool             // attach price charts to alerts
+	explainScores  bool             // add a score breakdown line per market to alerts
+
+	threadMu     sync.Mutex    // guards the thread settings, which follow config reloads
+	threadWindow time.Duration // how long alerted markets stay threaded (0 = no threads)
+	followUpMove float64       // minimum further move reported as a follow-up
+}
+
+// Options configures a Client. Store and Status are optional; commands that
+// need a missing one reply that they are unavailable.
+type Options struct {
+	MaxRetries     int
+	RetryDelayBase time.Duration
+	// AllowedChatIDs are the chats besides the configured one that may
+	// subscribe, use data commands and press alert buttons. Other chats can
+	// only use /start, /help and /ping.
+	AllowedChatIDs []int64
+	Store          *storage.Storage // subscriptions, /top and /market
+	Status         *status.Tracker  // /status
+	Charts         bool             // attach a price chart per event group to alerts (needs Store)
+	ExplainScores  bool             // add a score breakdown line per market to alerts
+	// ThreadWindow enables alert threads (needs Store): for this long after a
+	// market was last reported in the main chat, further moves are posted as
+	// replies to its alert. 0 disables threads.
+	ThreadWindow time.Duration
+	// FollowUpMinMove is the further move, in probability, since the last
+	// report that FollowUp posts to a thread.
+	FollowUpMinMove float64
 }
 
 // NewClient creates a new Telegram client
-func NewClient(botToken, chatID string, maxRetries int, retryDelayBase time.Duration) (*Client, error) {
+func NewClient(botToken, chatID string, opts Options) (*Client, error) {
 	bot, err := tgbotapi.NewBotAPI(botToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Telegram bot: %w", err)
 	}
+	return newClient(bot, chatID, opts)
+}
+
+func newClient(bot *tgbotapi.BotAPI, chatID string, opts Options) (*Client, error) {
 
 	chatIDInt, err := strconv.ParseInt(chatID, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("invalid chat ID: %w", err)
 	}
 
+	maxRetries, retryDelayBase := opts.MaxRetries, opts.RetryDelayBase
 	if maxRetries <= 0 {
 		maxRetries = 3
 	}
@@ -44,15 +89,28 @@ func NewClient(botToken, chatID string, maxRetries int, retryDelayBase time.Dura
 		retryDelayBase = time.Second
 	}
 
+	allowedChats := make(map[int64]bool, len(opts.AllowedChatIDs))
+	for _, id := range opts.AllowedChatIDs {
+		allowedChats[id] = true
+	}
+
 	return &Client{
 		bot:            bot,
 		chatID:         chatIDInt,
+		allowedChats:   allowedChats,
 		maxRetries:     maxRetries,
 		retryDelayBase: retryDelayBase,
+		store:          opts.Store,
+		status:         opts.Status,
+		charts:         opts.Charts,
+		explainScores:  opts.ExplainScores,
+		threadWindow:   opts.ThreadWindow,
+		followUpMove:   opts.FollowUpMinMove,
 	}, nil
 }
 
-// ListenForCommands starts a goroutine that polls for Telegram updates and handles bot commands.
+// ListenForCommands starts a goroutine that polls for Telegram updates and handles
+// bot commands and alert button presses.
 // It returns immediately; the goroutine stops when ctx is cancelled.
 func (c *Client) ListenForCommands(ctx context.Context) {
 	u := tgbotapi.NewUpdate(0)
@@ -72,17 +130,85 @@ func (c *Client) ListenForCommands(ctx context.Context) {
 				if update.Message != nil && update.Message.IsCommand() {
 					c.handleCommand(update.Message)
 				}
+				if update.CallbackQuery != nil {
+					c.handleCallback(update.CallbackQuery)
+				}
 			}
 		}
 	}()
 }
 
 func (c *Client) handleCommand(msg *tgbotapi.Message) {
-	switch msg.Command() {
+	cmd := msg.Command()
+	if !c.chatAllowed(msg.Chat.ID) && !openCommands[cmd] {
+		if isCommand(cmd) {
+			logger.Warn("Refused /%s from chat %d, which is not in telegram.allowed_chat_ids", cmd, msg.Chat.ID)
+			c.bot.Send(tgbotapi.NewMessage(msg.Chat.ID, notAllowedReply)) //nolint:errcheck
+		}
+		return
+	}
+
+	var text string
+	switch cmd {
 	case "ping":
-		reply := tgbotapi.NewMessage(msg.Chat.ID, "Pong")
-		c.bot.Send(reply) //nolint:errcheck
+		text = "Pong"
+	case "start", "help":
+		text = helpText
+	case "status":
+		text = c.statusReply(time.Now())
+	case "top":
+		text = c.topReply(msg.CommandArguments(), time.Now())
+	case "market":
+		text = c.marketReply(msg.CommandArguments(), time.Now())
+	case "explain":
+		text = c.explainReply(msg.CommandArguments())
+	case "chart":
+		c.sendMarketChart(msg.Chat.ID, msg.CommandArguments())
+		return
+	default:
+		if changesRules, ok := routingCommands[cmd]; ok {
+			if c.store == nil {
+				text = "Watches and mutes are not available on this bot."
+			} else if changesRules && msg.Chat.ID != c.chatID {
+				text = "Watches and mutes apply to every alert and can only be changed from the main chat."
+			} else {
+				text = handleRoutingCommand(c.store, cmd, msg.CommandArguments(), time.Now())
+			}
+			break
+		}
+		if !subscriptionCommands[cmd] {
+			return
+		}
+		if c.store == nil {
+			text = "Subscriptions are not available on this bot."
+		} else if msg.Chat.ID == c.chatID {
+			text = "This chat receives every alert; subscriptions are for other chats."
+		} else {
+			text = handleSubscriptionCommand(c.store, msg.Chat.ID, cmd, msg.CommandArguments())
+		}
 	}
+	c.bot.Send(tgbotapi.NewMessage(msg.Chat.ID, text)) //nolint:errcheck
+}
+
+// openCommands may be used from any chat.
+var openCommands = map[string]bool{"start": true, "help": true, "ping": true}
+
+// dataCommands read the alert history or the monitoring state.
+var dataCommands = map[string]bool{"status": true, "top": true, "market": true, "explain": true, "chart": true}
+
+// notAllowedReply answers commands and button presses from chats that are not allowed.
+const notAllowedReply = "This chat is not allowed to use this bot."
+
+// isCommand reports whether cmd is a command this bot handles.
+func isCommand(cmd string) bool {
+	_, routing := routingCommands[cmd]
+	return openCommands[cmd] || dataCommands[cmd] || routing || subscriptionCommands[cmd]
+}
+
+// chatAllowed reports whether chatID may use subscription and data commands
+// and alert buttons: the configured chat and those in AllowedChatIDs.
+func (c *Client) chatAllowed(chatID int64) bool {
+	return chatID == c.chatID || c.allowedChats[chatID]
 }
 
 // SendError sends a monitoring error notification to Telegram.
@@ -122,83 +248,281 @@ func (c *Client) SendRecovery(failureCount int) error {
 	return fmt.Errorf("failed to send recovery message after %d retries: %w", c.maxRetries, lastErr)
 }
 
-// Send sends a notification with the detected event groups
-func (c *Client) Send(groups []models.Event) error {
-	message := c.formatMessage(groups)
+// Destination identifies the chat alerts are sent to, for the alert history.
+func (c *Client) Destination() string {
+	return "telegram:" + strconv.FormatInt(c.chatID, 10)
+}
+
+// Send sends a notification with the detected event groups to the configured
+// chat and returns its Telegram message ID (IDs, comma-separated, when the
+// alert is split across messages). Groups whose markets all have an active
+// alert thread are posted as updates replying to it instead (see threads.go).
+// Subscribed chats then receive the groups matching their filters. When
+// charts are enabled, each group's chart follows its alert as a reply.
+func (c *Client) Send(groups []models.Event) (string, error) {
+	now := time.Now()
+	charts := c.renderAlertCharts(groups, now)
+	defer c.sendToSubscribers(groups, charts)
+
+	fresh, updates, threads := c.splitThreaded(groups, now)
+	var ids []string
+	if len(fresh) > 0 {
+		sent, err := c.sendChunks(c.chatID, fresh)
+		c.startThreads(sent, now)
+		ids = append(ids, messageIDs(sent)...)
+		if err != nil {
+			return strings.Join(ids, ","), err
+		}
+		c.sendCharts(c.chatID, chartsFor(charts, fresh), strings.Join(ids, ","))
+	}
+	updateIDs, err := c.sendUpdates(updates, threads, now)
+	ids = append(ids, updateIDs...)
+	if err != nil && len(ids) == 0 {
+		return "", err
+	}
+	if err != nil {
+		logger.Warn("Failed to post alert update: %v", err)
+	}
+	return strings.Join(ids, ","), nil
+}
+
+// sentChunk is an alert message that was delivered, with the groups it holds.
+type sentChunk struct {
+	messageID int
+	groups    []models.Event
+}
+
+// messageIDs returns the message IDs of sent, as strings.
+func messageIDs(sent []sentChunk) []string {
+	ids := make([]string, len(sent))
+	for i, s := range sent {
+		ids[i] = strconv.Itoa(s.messageID)
+	}
+	return ids
+}
+
+// sendAlert sends groups to one chat as MarkdownV2 messages, with retry, and
+// returns the message IDs, comma-separated when the alert was split (see
+// formatMessages). A failure after the first message returns the IDs sent so far.
+func (c *Client) sendAlert(chatID int64, groups []models.Event) (string, error) {
+	sent, err := c.sendChunks(chatID, groups)
+	return strings.Join(messageIDs(sent), ","), err
+}
+
+// sendChunks is sendAlert returning each delivered message with its groups.
+func (c *Client) sendChunks(chatID int64, groups []models.Event) ([]sentChunk, error) {
+	chunks := c.formatMessages(groups)
+	sent := make([]sentChunk, 0, len(chunks))
+	for n, chunk := range chunks {
+		id, err := c.sendChunk(chatID, chunk)
+		if err != nil {
+			if len(chunks) > 1 {
+				err = fmt.Errorf("part %d of %d: %w", n+1, len(chunks), err)
+			}
+			return sent, err
+		}
+		sent = append(sent, sentChunk{messageID: id, groups: chunk.groups})
+	}
+	return sent, nil
+}
 
-	// Create message
-	msg := tgbotapi.NewMessage(c.chatID, message)
+// sendChunk sends one message of an alert, with its action buttons.
+func (c *Client) sendChunk(chatID int64, chunk alertChunk) (int, error) {
+	msg := tgbotapi.NewMessage(chatID, chunk.text)
 	msg.ParseMode = "MarkdownV2" // Use MarkdownV2 for better escaping support
+	// Button actions need the store
+	if c.store != nil {
+		if keyboard := alertKeyboard(chunk.groups, chunk.first, chunk.numbered, chatID == c.chatID); keyboard != nil {
+			msg.ReplyMarkup = keyboard
+		}
+	}
+	return c.sendWithRetry(msg)
+}
 
-	// Send with retry
+// sendWithRetry sends msg, retrying transient failures, and returns its message ID.
+func (c *Client) sendWithRetry(msg tgbotapi.MessageConfig) (int, error) {
 	var lastErr error
 
 	for i := 0; i < c.maxRetries; i++ {
-		_, err := c.bot.Send(msg)
+		sent, err := c.bot.Send(msg)
 		if err == nil {
-			return nil
+			return sent.MessageID, nil
 		}
 		lastErr = err
+		if isPermanent(err) {
+			break
+		}
 		time.Sleep(c.retryDelayBase * time.Duration(i+1))
 	}
 
-	return fmt.Errorf("failed to send message after %d retries: %w", c.maxRetries, lastErr)
+	return 0, fmt.Errorf("failed to send message after %d retries: %w", c.maxRetries, lastErr)
+}
+
+// isPermanent reports whether a Bot API error will not go away on retry: the
+// bot was blocked or removed from the chat (403), or the request was rejected
+// (400), e.g. because the chat does not exist or the message is malformed.
+func isPermanent(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && (apiErr.Code == 403 || apiErr.Code == 400)
+}
+
+// isUnreachable reports whether a Bot API error means the chat can no longer
+// receive messages: the bot was blocked or removed (403) or the chat does not
+// exist. Other rejections, such as formatting errors, say nothing about the chat.
+func isUnreachable(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == 403 || strings.Contains(strings.ToLower(apiErr.Message), "chat not found")
 }
 
-// formatMessage formats event groups into a Telegram MarkdownV2 message.
-// Each group is one numbered entry; markets within the group appear as sub-bullets.
-func (c *Client) formatMessage(groups []models.Event) string {
-	message := "🚨 *Notable Odds Movements*\n\n"
+// maxMessageLength is Telegram's limit on the text of one message.
+const maxMessageLength = 4096
 
+// alertChunk is one Telegram message of an alert: the groups starting at
+// index first of the alert, formatted as MarkdownV2.
+type alertChunk struct {
+	text     string
+	first    int
+	groups   []models.Event
+	numbered bool // the alert has several groups, so entries and buttons carry numbers
+}
+
+// formatMessages formats event groups into Telegram MarkdownV2 messages of at
+// most maxMessageLength characters. Each group is one numbered entry; markets
+// within the group appear as sub-bullets. Messages are split only between
+// groups, so every message is valid MarkdownV2 on its own; a group too long for
+// one message lists its best-scoring markets and collapses the rest into a
+// "+N more" line. Numbering continues across messages.
+func (c *Client) formatMessages(groups []models.Event) []alertChunk {
+	header := "🚨 *Notable Odds Movements*\n\n"
 	// Show detected time once at the top (from the first market of the first group)
 	if len(groups) > 0 && len(groups[0].Markets) > 0 {
 		dateStr := escapeMarkdownV2(groups[0].Markets[0].DetectedAt.Format("2006-01-02 15:04:05"))
-		message += fmt.Sprintf("📅 Detected: %s\n\n", dateStr)
+		header += fmt.Sprintf("📅 Detected: %s\n\n", dateStr)
 	}
+	const continued = "🚨 *Notable Odds Movements* \\(continued\\)\n\n"
+	budget := maxMessageLength - max(textLength(header), textLength(continued))
 
+	numbered := len(groups) > 1
+	chunks := []alertChunk{{text: header, numbered: numbered}}
 	for i, group := range groups {
-		// Create clickable hyperlink for event title
-		var titleLink string
-		if group.URL != "" {
-			escapedQuestion := escapeMarkdownV2(group.Title)
-			titleLink = fmt.Sprintf("[%s](%s)", escapedQuestion, group.URL)
-		} else {
-			titleLink = escapeMarkdownV2(group.Title)
+		block := c.formatGroup(i, group, budget)
+		last := &chunks[len(chunks)-1]
+		if len(last.groups) > 0 && textLength(last.text)+textLength(block) > maxMessageLength {
+			chunks = append(chunks, alertChunk{text: continued, first: i, numbered: numbered})
+			last = &chunks[len(chunks)-1]
 		}
+		last.text += block
+		last.groups = append(last.groups, group)
+	}
+	return chunks
+}
 
-		message += fmt.Sprintf("%d\\. %s\n", i+1, titleLink)
+// formatGroup formats the i-th event group of an alert. When the full group
+// exceeds budget characters, trailing markets are replaced by a "+N more" line.
+func (c *Client) formatGroup(i int, group models.Event, budget int) string {
+	title := fmt.Sprintf("%d\\. %s\n", i+1, groupTitleLink(group))
+	lines := make([]string, len(group.Markets))
+	for j := range group.Markets {
+		lines[j] = c.formatChange(&group.Markets[j], group.Title)
+	}
+	return collapseBlock(title, lines, budget)
+}
 
-		for _, change := range group.Markets {
-			directionEmoji := "📈"
-			if change.Direction == "decrease" {
-				directionEmoji = "📉"
-			}
+// groupTitleLink returns the group title as MarkdownV2, linked to the event when it has a URL.
+func groupTitleLink(group models.Event) string {
+	if group.URL != "" {
+		return fmt.Sprintf("[%s](%s)", escapeMarkdownV2(group.Title), group.URL)
+	}
+	return escapeMarkdownV2(group.Title)
+}
 
-			magnitudePct := change.Magnitude * 100
-			oldPct := change.OldProbability * 100
-			newPct := change.NewProbability * 100
+// collapseBlock joins a group title and its market lines into one block of at
+// most budget characters, replacing trailing lines by a "+N more" line as needed.
+func collapseBlock(title string, lines []string, budget int) string {
+	total := textLength(title) + 1
+	for _, line := range lines {
+		total += textLength(line)
+	}
 
-			magnitudeStr := escapeMarkdownV2(fmt.Sprintf("%.1f%%", magnitudePct))
-			oldPctStr := escapeMarkdownV2(fmt.Sprintf("%.1f%%", oldPct))
-			newPctStr := escapeMarkdownV2(fmt.Sprintf("%.1f%%", newPct))
-			windowStr := escapeMarkdownV2(formatDuration(change.TimeWindow))
+	shown := len(lines)
+	for total > budget && shown > 0 {
+		shown--
+		total -= textLength(lines[shown])
+		if shown == len(lines)-1 {
+			total += textLength(moreLine(len(lines)))
+		}
+	}
+	block := title + strings.Join(lines[:shown], "")
+	if shown < len(lines) {
+		block += moreLine(len(lines) - shown)
+	}
+	return block + "\n"
+}
 
-			// Show market question as sub-bullet when it differs from the event question
-			if change.MarketQuestion != "" && change.MarketQuestion != group.Title {
-				escapedMarketQ := escapeMarkdownV2(change.MarketQuestion)
-				message += fmt.Sprintf("   🎯 %s\n", escapedMarketQ)
-			}
+// moreLine is the placeholder for n markets left out of an oversized group.
+func moreLine(n int) string {
+	noun := "markets"
+	if n == 1 {
+		noun = "market"
+	}
+	return fmt.Sprintf("   … \\+%d more %s\n", n, noun)
+}
 
-			message += fmt.Sprintf("   %s *%s* \\(%s → %s\\) ⏱ %s\n",
-				directionEmoji, magnitudeStr, oldPctStr, newPctStr, windowStr)
-		}
+// formatChange formats one market change of a group as sub-bullet lines.
+func (c *Client) formatChange(change *models.Change, groupTitle string) string {
+	var message string
+	directionEmoji := "📈"
+	if change.Direction == "decrease" {
+		directionEmoji = "📉"
+	}
+
+	magnitudePct := change.Magnitude * 100
+	oldPct := change.OldProbability * 100
+	newPct := change.NewProbability * 100
 
-		message += "\n"
+	magnitudeStr := escapeMarkdownV2(fmt.Sprintf("%.1f%%", magnitudePct))
+	oldPctStr := escapeMarkdownV2(fmt.Sprintf("%.1f%%", oldPct))
+	newPctStr := escapeMarkdownV2(fmt.Sprintf("%.1f%%", newPct))
+	windowStr := escapeMarkdownV2(formatDuration(change.TimeWindow))
+
+	// Show market question as sub-bullet when it differs from the event question
+	if change.MarketQuestion != "" && change.MarketQuestion != groupTitle {
+		escapedMarketQ := escapeMarkdownV2(change.MarketQuestion)
+		message += fmt.Sprintf("   🎯 %s\n", escapedMarketQ)
+	}
+
+	// Name the outcome that moved for non Yes/No markets
+	if change.Outcome != "" {
+		message += fmt.Sprintf("   🏷 %s\n", escapeMarkdownV2(change.Outcome))
 	}
 
+	message += fmt.Sprintf("   %s *%s* \\(%s → %s\\) ⏱ %s\n",
+		directionEmoji, magnitudeStr, oldPctStr, newPctStr, windowStr)
+
+	if c.explainScores && change.Breakdown != nil {
+		message += fmt.Sprintf("   🧮 %s\n", escapeMarkdownV2(breakdownLine(change.SignalScore, change.Breakdown)))
+	}
 	return message
 }
 
+// textLength returns the length of s as Telegram counts it, in UTF-16 code
+// units. Measured on MarkdownV2 source, it over-estimates the rendered text
+// (escapes and link URLs are not shown), so it is safe against the limit.
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		if r > 0xFFFF {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
 // escapeMarkdownV2 escapes special characters for Telegram MarkdownV2.
 // Characters that need escaping: _ * [ ] ( ) ~ ` > # + - = | { } . !
 func escapeMarkdownV2(text string) string {
@@ -1,8 +1,12 @@
 package telegram
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
 )
 
 func TestFormatDuration(t *testing.T) {
@@ -61,8 +65,160 @@ func TestNewClient_InvalidChatID(t *testing.T) {
 	// Note: This test exercises the chat ID parsing error path
 	// The bot token validation happens first (network call), so we use a clearly
 	// invalid format to test the error handling flow
-	_, err := NewClient("", "not-a-number", 3, time.Second)
+	_, err := NewClient("", "not-a-number", Options{MaxRetries: 3, RetryDelayBase: time.Second})
 	if err == nil {
 		t.Error("Expected error for invalid chat ID, got nil")
 	}
 }
+
+func TestFormatMessage_NamesOutcome(t *testing.T) {
+	c := &Client{}
+	groups := []models.Event{{
+		ID:    "event-1",
+		Title: "Election",
+		Markets: []models.Change{{
+			MarketQuestion: "Who will win?",
+			Outcome:        "Harris",
+			Direction:      "increase",
+			Magnitude:      0.1,
+			OldProbability: 0.4,
+			NewProbability: 0.5,
+			TimeWindow:     time.Hour,
+			DetectedAt:     time.Now(),
+		}},
+	}}
+	msg := c.formatMessages(groups)[0].text
+	if !strings.Contains(msg, "🏷 Harris") {
+		t.Errorf("Expected message to name the outcome, got:\n%s", msg)
+	}
+}
+
+func TestFormatMessage_ExplainScores(t *testing.T) {
+	groups := []models.Event{{
+		ID:    "event-1",
+		Title: "Election",
+		Markets: []models.Change{{
+			Direction: "increase", Magnitude: 0.1, OldProbability: 0.4, NewProbability: 0.5,
+			TimeWindow: time.Hour, DetectedAt: time.Now(), SignalScore: 0.0231,
+			Breakdown: &models.ScoreBreakdown{KL: 0.0123, VolumeWeight: 1.1, SNR: 2.3, TrajectoryConsistency: 0.8, SpreadWeight: 0.95, MinScore: 0.02},
+		}},
+	}}
+	if msg := (&Client{}).formatMessages(groups)[0].text; strings.Contains(msg, "🧮") {
+		t.Errorf("breakdown should be off by default:\n%s", msg)
+	}
+	msg := (&Client{explainScores: true}).formatMessages(groups)[0].text
+	want := `🧮 KL 0\.0123 × vol 1\.10 × SNR 2\.30 × TC 0\.80 × spread 0\.95 \= 0\.0231 \(bar 0\.0200\)`
+	if !strings.Contains(msg, want) {
+		t.Errorf("expected escaped breakdown line %q in:\n%s", want, msg)
+	}
+}
+
+// checkMarkdownV2 fails the test when text has an unescaped bold marker or
+// link bracket without its pair, which Telegram rejects.
+func checkMarkdownV2(t *testing.T, text string) {
+	t.Helper()
+	stars, open, close := 0, 0, 0
+	escaped := false
+	for _, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			stars++
+		case r == '[':
+			open++
+		case r == ']':
+			close++
+		}
+	}
+	if stars%2 != 0 || open != close || escaped {
+		t.Errorf("unbalanced MarkdownV2 (%d *, %d [, %d ]):\n%s", stars, open, close, text)
+	}
+}
+
+func longGroup(id string, markets int) models.Event {
+	g := models.Event{ID: id, Title: "Event " + id + " (who wins?)", URL: "https://polymarket.com/event/" + id}
+	for j := 0; j < markets; j++ {
+		g.Markets = append(g.Markets, models.Change{
+			MarketQuestion: fmt.Sprintf("Will candidate #%d-%s win the [primary] by 1.5%%?", j, id),
+			Direction:      "increase", Magnitude: 0.1, OldProbability: 0.4, NewProbability: 0.5,
+			TimeWindow: time.Hour, DetectedAt: time.Now(),
+		})
+	}
+	return g
+}
+
+func TestFormatMessages_SplitsOnGroupBoundaries(t *testing.T) {
+	var groups []models.Event
+	for i := 0; i < 10; i++ {
+		groups = append(groups, longGroup(fmt.Sprint(i), 12))
+	}
+	chunks := (&Client{}).formatMessages(groups)
+	if len(chunks) < 2 {
+		t.Fatalf("expected the alert to be split, got %d message(s)", len(chunks))
+	}
+
+	seen := 0
+	for n, chunk := range chunks {
+		if l := textLength(chunk.text); l > maxMessageLength {
+			t.Errorf("message %d is %d characters long", n, l)
+		}
+		checkMarkdownV2(t, chunk.text)
+		if chunk.first != seen {
+			t.Errorf("message %d starts at group %d, want %d", n, chunk.first, seen)
+		}
+		for i := range chunk.groups {
+			if !strings.Contains(chunk.text, fmt.Sprintf("%d\\. [Event %d", seen+i+1, seen+i)) {
+				t.Errorf("message %d lacks entry %d", n, seen+i+1)
+			}
+		}
+		seen += len(chunk.groups)
+		if n > 0 && !strings.HasPrefix(chunk.text, "🚨 *Notable Odds Movements* \\(continued\\)") {
+			t.Errorf("message %d lacks the continuation header", n)
+		}
+		if strings.Contains(chunk.text, "more market") {
+			t.Errorf("groups that fit in a message should not be collapsed")
+		}
+	}
+	if seen != len(groups) {
+		t.Errorf("messages hold %d groups, want %d", seen, len(groups))
+	}
+}
+
+func TestFormatMessages_CollapsesOversizedGroup(t *testing.T) {
+	chunks := (&Client{}).formatMessages([]models.Event{longGroup("big", 200)})
+	if len(chunks) != 1 {
+		t.Fatalf("a single group should stay in one message, got %d", len(chunks))
+	}
+	text := chunks[0].text
+	if l := textLength(text); l > maxMessageLength {
+		t.Errorf("message is %d characters long", l)
+	}
+	checkMarkdownV2(t, text)
+	shown := strings.Count(text, "🎯")
+	if !strings.Contains(text, fmt.Sprintf("… \\+%d more markets", 200-shown)) || shown == 0 {
+		t.Errorf("expected %d markets shown and the rest collapsed:\n%s", shown, text[len(text)-200:])
+	}
+}
+
+func TestSend_SplitAlert(t *testing.T) {
+	bot, api := newFakeBot(t)
+	c, err := newClient(bot, "100", Options{MaxRetries: 1, RetryDelayBase: time.Millisecond})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	var groups []models.Event
+	for i := 0; i < 10; i++ {
+		groups = append(groups, longGroup(fmt.Sprint(i), 12))
+	}
+	ids, err := c.Send(groups)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	msgs := api.messages(100)
+	if len(msgs) < 2 || strings.Count(ids, ",") != len(msgs)-1 || !strings.HasPrefix(ids, "1,2") {
+		t.Errorf("expected one ID per message, got %q for %d messages", ids, len(msgs))
+	}
+}
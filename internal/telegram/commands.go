@@ -0,0 +1,237 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+const (
+	defaultTopN   = 5
+	maxTopN       = 20
+	topLookback   = 24 * time.Hour
+	marketResults = 5
+)
+
+// errorReply answers a command that failed on our side, e.g. a storage error.
+const errorReply = "Something went wrong, please try again later."
+
+// statusReply describes the monitoring loop state for /status.
+func (c *Client) statusReply(now time.Time) string {
+	if c.status == nil {
+		return "Status is not available on this bot."
+	}
+	s := c.status.Snapshot()
+	var b strings.Builder
+	b.WriteString("Polyoracle status\n\n")
+	fmt.Fprintf(&b, "Uptime: %s\n", roundDuration(now.Sub(s.StartedAt)))
+	if s.Cycles == 0 {
+		b.WriteString("Last cycle: none yet\n")
+	} else {
+		fmt.Fprintf(&b, "Last cycle: %s (%s ago), took %s\n",
+			s.LastCycleStart.Format("2006-01-02 15:04:05"), roundDuration(now.Sub(s.LastCycleStart)),
+			s.LastCycleDuration.Round(time.Millisecond))
+		if s.LastError == "" {
+			b.WriteString("Last result: ok\n")
+		} else {
+			fmt.Fprintf(&b, "Last result: failed: %s\n", s.LastError)
+		}
+	}
+	fmt.Fprintf(&b, "Consecutive failures: %d\n", s.ConsecutiveFailures)
+	if c.store != nil {
+		if n, err := c.store.CountMarkets(); err == nil {
+			fmt.Fprintf(&b, "Markets tracked: %d\n", n)
+		}
+	}
+	if !s.NextRun.IsZero() {
+		fmt.Fprintf(&b, "Next run: %s (in %s)\n", s.NextRun.Format("15:04:05"), roundDuration(s.NextRun.Sub(now)))
+	}
+	return b.String()
+}
+
+// topReply lists the highest-scoring recent changes for /top [n], including
+// those below the alert bar.
+func (c *Client) topReply(args string, now time.Time) string {
+	if c.store == nil {
+		return "Alert history is not available on this bot."
+	}
+	n := defaultTopN
+	if arg := strings.TrimSpace(args); arg != "" {
+		v, err := strconv.Atoi(arg)
+		if err != nil || v < 1 {
+			return fmt.Sprintf("Usage: /top [n], e.g. /top 10 (max %d)", maxTopN)
+		}
+		n = min(v, maxTopN)
+	}
+	changes, err := c.store.TopScoredChanges(now.Add(-topLookback), n)
+	if err != nil {
+		logger.Error("Failed to load top changes: %v", err)
+		return errorReply
+	}
+	if len(changes) == 0 {
+		return "No scored changes in the last 24h."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Top %d scored changes (last 24h)\n\n", len(changes))
+	for i, ch := range changes {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, ch.EventTitle)
+		if ch.MarketQuestion != "" && ch.MarketQuestion != ch.EventTitle {
+			fmt.Fprintf(&b, "   🎯 %s\n", ch.MarketQuestion)
+		}
+		if ch.Outcome != "" {
+			fmt.Fprintf(&b, "   🏷 %s\n", ch.Outcome)
+		}
+		bar := "below bar"
+		if ch.PassedThreshold {
+			bar = "passed"
+		}
+		fmt.Fprintf(&b, "   %s %.1f%% (%.1f%% → %.1f%%) ⏱ %s · score %.4f (%s)\n",
+			directionEmoji(ch.Direction), ch.Magnitude*100, ch.OldProbability*100, ch.NewProbability*100,
+			formatDuration(ch.TimeWindow), ch.SignalScore, bar)
+	}
+	return b.String()
+}
+
+// marketReply searches tracked markets for /market <query>.
+func (c *Client) marketReply(args string, now time.Time) string {
+	if c.store == nil {
+		return "Market search is not available on this bot."
+	}
+	query := strings.TrimSpace(args)
+	if query == "" {
+		return "Usage: /market <search text>, e.g. /market bitcoin"
+	}
+	markets, err := c.store.SearchMarkets(query, marketResults)
+	if err != nil {
+		logger.Error("Failed to search markets: %v", err)
+		return errorReply
+	}
+	if len(markets) == 0 {
+		return fmt.Sprintf("No tracked market matches %q.", query)
+	}
+
+	ids := make([]string, len(markets))
+	for i, m := range markets {
+		ids[i] = m.ID
+	}
+	history, err := c.store.GetSnapshotsInWindowForMarkets(ids, 24*time.Hour)
+	if err != nil {
+		logger.Warn("Failed to load market history: %v", err)
+	}
+
+	var b strings.Builder
+	for i, m := range markets {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(m.Title + "\n")
+		if m.MarketQuestion != "" && m.MarketQuestion != m.Title {
+			fmt.Fprintf(&b, "🎯 %s\n", m.MarketQuestion)
+		}
+		if m.IsYesNo() {
+			fmt.Fprintf(&b, "Yes: %.1f%%\n", m.YesProbability*100)
+		} else {
+			prices := make([]string, len(m.Outcomes))
+			for j, o := range m.Outcomes {
+				prices[j] = fmt.Sprintf("%s %.1f%%", o.Name, o.Price*100)
+			}
+			b.WriteString(strings.Join(prices, " · ") + "\n")
+		}
+		fmt.Fprintf(&b, "Volume: 24h %s · 1w %s · 1m %s\n",
+			formatUSD(m.Volume24hr), formatUSD(m.Volume1wk), formatUSD(m.Volume1mo))
+		series := history[storage.SeriesKey{MarketID: m.ID, Outcome: m.TrackedOutcomes()[0]}]
+		fmt.Fprintf(&b, "Move: 1h %s · 24h %s\n", movement(series, now, time.Hour), movement(series, now, 24*time.Hour))
+		if m.EventURL != "" {
+			b.WriteString(m.EventURL + "\n")
+		}
+	}
+	return b.String()
+}
+
+// explainReply explains the most recent scored change of the best-matching
+// market for /explain <query>; a change ID is also accepted.
+func (c *Client) explainReply(args string) string {
+	if c.store == nil {
+		return "Score explanations are not available on this bot."
+	}
+	query := strings.TrimSpace(args)
+	if query == "" {
+		return "Usage: /explain <search text>, e.g. /explain bitcoin"
+	}
+	change, err := c.store.GetChange(query)
+	if err != nil {
+		logger.Error("Failed to load change %s: %v", query, err)
+		return errorReply
+	}
+	if change != nil {
+		return explainChange(change)
+	}
+
+	markets, err := c.store.SearchMarkets(query, 1)
+	if err != nil {
+		logger.Error("Failed to search markets: %v", err)
+		return errorReply
+	}
+	if len(markets) == 0 {
+		return fmt.Sprintf("No tracked market matches %q.", query)
+	}
+	changes, err := c.store.QueryChanges(storage.ChangeFilter{MarketID: markets[0].ID, Limit: 1})
+	if err != nil {
+		logger.Error("Failed to load changes for %s: %v", markets[0].ID, err)
+		return errorReply
+	}
+	if len(changes) == 0 {
+		return fmt.Sprintf("%s has no scored change yet.", markets[0].Title)
+	}
+	return explainChange(&changes[0])
+}
+
+// movement returns the change in percentage points between the first snapshot
+// at or after now−d and the latest one, or "n/a" without enough history.
+func movement(series []models.Snapshot, now time.Time, d time.Duration) string {
+	cutoff := now.Add(-d)
+	for _, snap := range series {
+		if snap.Timestamp.Before(cutoff) {
+			continue
+		}
+		last := series[len(series)-1]
+		if !last.Timestamp.After(snap.Timestamp) {
+			break
+		}
+		return fmt.Sprintf("%+.1fpp", (last.YesProbability-snap.YesProbability)*100)
+	}
+	return "n/a"
+}
+
+func directionEmoji(direction string) string {
+	if direction == "decrease" {
+		return "📉"
+	}
+	return "📈"
+}
+
+// formatUSD abbreviates a dollar amount: $950, $12K, $1.2M.
+func formatUSD(v float64) string {
+	switch {
+	case v >= 1e6:
+		return fmt.Sprintf("$%.1fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("$%.0fK", v/1e3)
+	default:
+		return fmt.Sprintf("$%.0f", v)
+	}
+}
+
+// roundDuration rounds d for display: seconds under a minute, minutes otherwise.
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Minute {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Minute)
+}
@@ -0,0 +1,139 @@
+package telegram
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/status"
+)
+
+func TestStatusReply(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := status.New(start)
+	c := &Client{status: tracker, store: mustStorage(t)}
+
+	now := start.Add(5 * time.Minute)
+	if got := c.statusReply(now); !strings.Contains(got, "Last cycle: none yet") {
+		t.Errorf("expected no cycles yet, got %q", got)
+	}
+
+	tracker.CycleFinished(now.Add(-2*time.Second), now, errors.New("api down"))
+	tracker.SetNextRun(now.Add(time.Minute))
+	got := c.statusReply(now)
+	for _, want := range []string{"took 2s", "failed: api down", "Consecutive failures: 1", "Markets tracked: 0", "in 1m0s"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("status reply missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := (&Client{}).statusReply(now); !strings.Contains(got, "not available") {
+		t.Errorf("expected unavailable reply without a tracker, got %q", got)
+	}
+}
+
+func TestTopAndMarketReplies(t *testing.T) {
+	store := mustStorage(t)
+	c := &Client{store: store}
+	now := time.Now()
+
+	m := &models.Market{
+		ID: "e1:m1", EventID: "e1", MarketID: "m1", Title: "Bitcoin above 100k?", Category: "crypto", EventURL: "https://polymarket.com/event/btc",
+		YesProbability: 0.6, NoProbability: 0.4, Volume24hr: 1_500_000, Volume1wk: 25_000, Volume1mo: 900,
+		Active: true, LastUpdated: now, CreatedAt: now.Add(-48 * time.Hour),
+	}
+	if err := store.AddMarket(m); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+	if _, err := store.AddSnapshots([]models.Snapshot{
+		{ID: "s1", EventID: "e1:m1", YesProbability: 0.50, NoProbability: 0.50, Timestamp: now.Add(-20 * time.Hour), Source: "test"},
+		{ID: "s2", EventID: "e1:m1", YesProbability: 0.55, NoProbability: 0.45, Timestamp: now.Add(-30 * time.Minute), Source: "test"},
+		{ID: "s3", EventID: "e1:m1", YesProbability: 0.60, NoProbability: 0.40, Timestamp: now.Add(-time.Minute), Source: "test"},
+	}); err != nil {
+		t.Fatalf("AddSnapshots: %v", err)
+	}
+
+	got := c.marketReply("bitcoin", now)
+	for _, want := range []string{"Yes: 60.0%", "24h $1.5M · 1w $25K · 1m $900", "1h +5.0pp · 24h +10.0pp", m.EventURL} {
+		if !strings.Contains(got, want) {
+			t.Errorf("market reply missing %q:\n%s", want, got)
+		}
+	}
+	if got := c.marketReply("nothing", now); !strings.Contains(got, "No tracked market") {
+		t.Errorf("unexpected reply for unknown market: %q", got)
+	}
+
+	if got := c.topReply("", now); !strings.Contains(got, "No scored changes") {
+		t.Errorf("expected empty top reply, got %q", got)
+	}
+	for _, ch := range []*models.Change{
+		{ID: "c1", EventID: "e1:m1", EventTitle: "Below bar", SignalScore: 0.01, Direction: "increase", TimeWindow: time.Hour, DetectedAt: now},
+		{ID: "c2", EventID: "e1:m1", EventTitle: "Alerted", SignalScore: 0.2, PassedThreshold: true, Direction: "decrease", TimeWindow: time.Hour, DetectedAt: now},
+	} {
+		if err := store.AddChange(ch); err != nil {
+			t.Fatalf("AddChange: %v", err)
+		}
+	}
+	got = c.topReply("1", now)
+	if !strings.Contains(got, "1. Alerted") || strings.Contains(got, "Below bar") || !strings.Contains(got, "(passed)") {
+		t.Errorf("unexpected /top 1 reply:\n%s", got)
+	}
+	if got := c.topReply("", now); !strings.Contains(got, "2. Below bar") || !strings.Contains(got, "(below bar)") {
+		t.Errorf("/top should include changes below the alert bar:\n%s", got)
+	}
+	if got := c.topReply("x", now); !strings.HasPrefix(got, "Usage") {
+		t.Errorf("expected usage for bad argument, got %q", got)
+	}
+}
+
+func TestExplainReply(t *testing.T) {
+	store := mustStorage(t)
+	c := &Client{store: store}
+	now := time.Now()
+
+	if err := store.AddMarket(&models.Market{
+		ID: "e1:m1", EventID: "e1", MarketID: "m1", Title: "Bitcoin above 100k?", Category: "crypto",
+		YesProbability: 0.96, NoProbability: 0.04, Active: true, LastUpdated: now, CreatedAt: now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("AddMarket: %v", err)
+	}
+	if got := c.explainReply("bitcoin"); !strings.Contains(got, "no scored change yet") {
+		t.Errorf("unexpected reply without changes: %q", got)
+	}
+
+	for _, ch := range []*models.Change{
+		{ID: "old", EventID: "e1:m1", EventTitle: "Bitcoin above 100k?", Direction: "increase",
+			OldProbability: 0.5, NewProbability: 0.6, Magnitude: 0.1, TimeWindow: time.Hour, DetectedAt: now.Add(-time.Hour)},
+		{ID: "new", EventID: "e1:m1", EventTitle: "Bitcoin above 100k?", Direction: "increase",
+			OldProbability: 0.94, NewProbability: 0.96, Magnitude: 0.02, TimeWindow: time.Hour, DetectedAt: now,
+			SignalScore: 0.03, PassedThreshold: true,
+			Breakdown: &models.ScoreBreakdown{
+				KL: 0.02, VolumeWeight: 1.5, SNR: 1, TrajectoryConsistency: 1, SpreadWeight: 1,
+				MinScore: 0.04, MinAbsChange: 0.03, MinBaseProb: 0.05, ConfirmationBypass: true,
+				Routing: models.RoutingWatch, WatchThreshold: 0.01,
+			}},
+	} {
+		if err := store.AddChange(ch); err != nil {
+			t.Fatalf("AddChange: %v", err)
+		}
+	}
+
+	got := c.explainReply("bitcoin")
+	for _, want := range []string{
+		"Signal score: 0.0300 (passed the alert bar 0.0400)",
+		"KL divergence: 0.0200",
+		"× Volume weight: 1.50",
+		"min change 3.0pp (bypassed: entered the >95%/<5% confirmation zone)",
+		"min base probability 5.0%",
+		"Routing: alerted through a watch (alerts at score ≥ 0.01)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("explanation missing %q:\n%s", want, got)
+		}
+	}
+	if got := c.explainReply("old"); !strings.Contains(got, "No breakdown was recorded") {
+		t.Errorf("explaining by change ID should work for changes without a breakdown:\n%s", got)
+	}
+}
@@ -0,0 +1,173 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+const defaultMuteDuration = 24 * time.Hour
+
+// routingCommands are the commands handled by handleRoutingCommand. The value
+// reports whether the command changes the rules; those are only accepted from
+// the configured chat because watches and mutes apply to every alert.
+var routingCommands = map[string]bool{
+	"watch": true, "unwatch": true, "mute": true, "unmute": true,
+	"watchlist": false, "mutes": false,
+}
+
+// handleRoutingCommand applies one watchlist or mute command and returns the
+// plain-text reply.
+func handleRoutingCommand(store *storage.Storage, cmd, args string, now time.Time) string {
+	fields := strings.Fields(args)
+	switch cmd {
+	case "watchlist":
+		watches, err := store.ListWatches()
+		if err != nil {
+			logger.Error("Failed to load watchlist: %v", err)
+			return errorReply
+		}
+		if len(watches) == 0 {
+			return "The watchlist is empty. Use /watch <event|market> <id> [threshold]."
+		}
+		var b strings.Builder
+		b.WriteString("Watchlist\n\n")
+		for _, w := range watches {
+			fmt.Fprintf(&b, "• %s %s – %s\n", w.Kind, w.Target, describeThreshold(w.Threshold))
+		}
+		return b.String()
+	case "mutes":
+		mutes, err := store.ListMutes(now)
+		if err != nil {
+			logger.Error("Failed to load mutes: %v", err)
+			return errorReply
+		}
+		if len(mutes) == 0 {
+			return "Nothing is muted. Use /mute <event|market|category> <id> [duration]."
+		}
+		var b strings.Builder
+		b.WriteString("Mutes\n\n")
+		for _, m := range mutes {
+			fmt.Fprintf(&b, "• %s %s – until %s (%s left)\n", m.Kind, m.Target,
+				m.Until.Format("2006-01-02 15:04"), roundDuration(m.Until.Sub(now)))
+		}
+		return b.String()
+	case "watch":
+		if len(fields) < 2 || len(fields) > 3 || !isWatchKind(fields[0]) {
+			return "Usage: /watch <event|market> <id, slug or URL> [threshold], e.g. /watch market 253591 0.01"
+		}
+		w := &models.Watch{Kind: fields[0], Target: routingTarget(fields[0], fields[1])}
+		if len(fields) == 3 {
+			threshold, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil || threshold < 0 {
+				return "The threshold must be a score ≥ 0, e.g. 0.01."
+			}
+			w.Threshold = threshold
+		}
+		if err := store.SaveWatch(w); err != nil {
+			logger.Error("Failed to save watch: %v", err)
+			return errorReply
+		}
+		return fmt.Sprintf("Watching %s %s – %s.", w.Kind, w.Target, describeThreshold(w.Threshold))
+	case "unwatch":
+		if len(fields) != 2 || !isWatchKind(fields[0]) {
+			return "Usage: /unwatch <event|market> <id, slug or URL>"
+		}
+		target := routingTarget(fields[0], fields[1])
+		ok, err := store.DeleteWatch(fields[0], target)
+		if err != nil {
+			logger.Error("Failed to delete watch: %v", err)
+			return errorReply
+		}
+		if !ok {
+			return fmt.Sprintf("%s %s is not on the watchlist.", fields[0], target)
+		}
+		return fmt.Sprintf("Stopped watching %s %s.", fields[0], target)
+	case "mute":
+		if len(fields) < 2 || len(fields) > 3 || !isMuteKind(fields[0]) {
+			return "Usage: /mute <event|market|category> <id, slug or name> [duration], e.g. /mute category sports 12h"
+		}
+		d := defaultMuteDuration
+		if len(fields) == 3 {
+			var err error
+			if d, err = parseMuteDuration(fields[2]); err != nil {
+				return "The duration must be positive, e.g. 30m, 12h or 7d."
+			}
+		}
+		m := &models.Mute{Kind: fields[0], Target: routingTarget(fields[0], fields[1]), Until: now.Add(d), CreatedAt: now}
+		if err := store.SaveMute(m); err != nil {
+			logger.Error("Failed to save mute: %v", err)
+			return errorReply
+		}
+		return fmt.Sprintf("Muted %s %s until %s.", m.Kind, m.Target, m.Until.Format("2006-01-02 15:04"))
+	case "unmute":
+		if len(fields) != 2 || !isMuteKind(fields[0]) {
+			return "Usage: /unmute <event|market|category> <id, slug or name>"
+		}
+		target := routingTarget(fields[0], fields[1])
+		ok, err := store.DeleteMute(fields[0], target)
+		if err != nil {
+			logger.Error("Failed to delete mute: %v", err)
+			return errorReply
+		}
+		if !ok {
+			return fmt.Sprintf("%s %s is not muted.", fields[0], target)
+		}
+		return fmt.Sprintf("Unmuted %s %s.", fields[0], target)
+	}
+	return ""
+}
+
+func isWatchKind(kind string) bool {
+	return kind == models.TargetEvent || kind == models.TargetMarket
+}
+
+func isMuteKind(kind string) bool {
+	return isWatchKind(kind) || kind == models.TargetCategory
+}
+
+// routingTarget normalises a target argument: event URLs are reduced to their
+// slug and categories are lower-cased; market IDs are kept as given.
+func routingTarget(kind, arg string) string {
+	switch kind {
+	case models.TargetEvent:
+		return eventRef(arg)
+	case models.TargetCategory:
+		return strings.ToLower(arg)
+	}
+	return arg
+}
+
+func describeThreshold(threshold float64) string {
+	if threshold == 0 {
+		return "every scored change alerts"
+	}
+	return fmt.Sprintf("alerts at score ≥ %g", threshold)
+}
+
+// parseMuteDuration parses a Go duration, additionally accepting whole days
+// ("7d"). The result must be positive.
+func parseMuteDuration(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive: %s", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,82 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+func TestHandleRoutingCommand(t *testing.T) {
+	store := mustStorage(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if reply := handleRoutingCommand(store, "watch", "market", now); !strings.HasPrefix(reply, "Usage") {
+		t.Errorf("expected usage, got %q", reply)
+	}
+	handleRoutingCommand(store, "watch", "event https://polymarket.com/event/btc-100k", now)
+	if reply := handleRoutingCommand(store, "watch", "market 253591 0.01", now); !strings.Contains(reply, "score ≥ 0.01") {
+		t.Errorf("unexpected watch reply: %q", reply)
+	}
+	reply := handleRoutingCommand(store, "watchlist", "", now)
+	if !strings.Contains(reply, "event btc-100k – every scored change") || !strings.Contains(reply, "market 253591") {
+		t.Errorf("unexpected watchlist:\n%s", reply)
+	}
+
+	handleRoutingCommand(store, "mute", "category Sports", now)
+	handleRoutingCommand(store, "mute", "event election 2d", now)
+	if reply := handleRoutingCommand(store, "mute", "event x -1h", now); !strings.Contains(reply, "positive") {
+		t.Errorf("expected duration error, got %q", reply)
+	}
+	mutes, err := store.ListMutes(now)
+	if err != nil || len(mutes) != 2 {
+		t.Fatalf("ListMutes = %+v, %v", mutes, err)
+	}
+	if mutes[0].Target != "sports" || !mutes[0].Until.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("category mute should default to 24h: %+v", mutes[0])
+	}
+	if !mutes[1].Until.Equal(now.Add(48 * time.Hour)) {
+		t.Errorf("2d mute ends at %v", mutes[1].Until)
+	}
+	if reply := handleRoutingCommand(store, "mutes", "", now); !strings.Contains(reply, "category sports – until 2026-01-02 12:00 (24h0m0s left)") {
+		t.Errorf("unexpected mutes list:\n%s", reply)
+	}
+
+	if reply := handleRoutingCommand(store, "unmute", "category sports", now); !strings.HasPrefix(reply, "Unmuted") {
+		t.Errorf("unexpected unmute reply: %q", reply)
+	}
+	if reply := handleRoutingCommand(store, "unwatch", "market 1", now); !strings.Contains(reply, "not on the watchlist") {
+		t.Errorf("unexpected unwatch reply: %q", reply)
+	}
+}
+
+func TestHandleCommand_RoutingChangesOnlyFromMainChat(t *testing.T) {
+	bot, api := newFakeBot(t)
+	store := mustStorage(t)
+	c, err := newClient(bot, "100", Options{AllowedChatIDs: []int64{200}, Store: store})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+
+	command := func(chatID int64, text string) {
+		cmdLen := len(strings.Fields(text)[0])
+		c.handleCommand(&tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: cmdLen}},
+		})
+	}
+	command(200, "/mute category sports")
+	command(200, "/mutes")
+	command(100, "/mute category sports")
+
+	other := api.messages(200)
+	if len(other) != 2 || !strings.Contains(other[0], "only be changed from the main chat") || !strings.HasPrefix(other[1], "Nothing is muted") {
+		t.Errorf("unexpected replies to another chat: %v", other)
+	}
+	if mutes, _ := store.ListMutes(time.Now()); len(mutes) != 1 || mutes[0].Kind != models.TargetCategory {
+		t.Errorf("main chat mute not stored: %+v", mutes)
+	}
+}
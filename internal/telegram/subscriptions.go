@@ -0,0 +1,192 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+const helpText = `Polyoracle alerts for Polymarket odds movements.
+
+/subscribe – receive alerts in this chat
+/unsubscribe – stop receiving alerts
+/settings – show this chat's filters
+/categories <name…|all> – only these categories (e.g. crypto tech)
+/events <id or URL…|all> – only these Polymarket events
+/minscore <score> – minimum event score (0 = default bar)
+/topk <n> – at most n events per alert (0 = all)
+/watch <event|market> <id> [threshold] – alert on it at a lower bar (main chat)
+/mute <event|market|category> <id> [duration] – silence it, default 24h (main chat)
+/unwatch, /unmute – remove a watch or mute
+/watchlist, /mutes – list watches and active mutes
+/status – monitoring health and schedule
+/top [n] – highest-scoring changes of the last 24h
+/market <text> – search tracked markets
+/chart <text> – 24h price chart of a tracked market
+/explain <text> – how the latest change of a market was scored
+/ping – check the bot is alive`
+
+// subscriptionCommands are the commands handled by handleSubscriptionCommand.
+var subscriptionCommands = map[string]bool{
+	"subscribe": true, "unsubscribe": true, "settings": true,
+	"categories": true, "events": true, "minscore": true, "topk": true,
+}
+
+// handleSubscriptionCommand applies one subscription command for chatID and
+// returns the plain-text reply.
+func handleSubscriptionCommand(store *storage.Storage, chatID int64, cmd, args string) string {
+	sub, err := store.GetSubscription(chatID)
+	if err != nil {
+		logger.Error("Failed to load subscription for chat %d: %v", chatID, err)
+		return errorReply
+	}
+
+	switch cmd {
+	case "subscribe":
+		if sub != nil {
+			return "This chat is already subscribed.\n\n" + describeSubscription(sub)
+		}
+		sub = &models.Subscription{ChatID: chatID}
+		if err := store.SaveSubscription(sub); err != nil {
+			logger.Error("Failed to save subscription for chat %d: %v", chatID, err)
+			return errorReply
+		}
+		return "Subscribed. This chat will receive every alert until you add filters.\n\n" + helpText
+	case "unsubscribe":
+		if sub == nil {
+			return "This chat is not subscribed."
+		}
+		if err := store.DeleteSubscription(chatID); err != nil {
+			logger.Error("Failed to delete subscription for chat %d: %v", chatID, err)
+			return errorReply
+		}
+		return "Unsubscribed. Send /subscribe to start again."
+	}
+
+	if sub == nil {
+		return "This chat is not subscribed. Send /subscribe first."
+	}
+	fields := strings.Fields(args)
+
+	switch cmd {
+	case "settings":
+		return describeSubscription(sub)
+	case "categories":
+		if len(fields) == 0 {
+			return "Usage: /categories <name…|all>"
+		}
+		sub.Categories = nil
+		if !isAll(fields) {
+			for _, f := range fields {
+				sub.Categories = append(sub.Categories, strings.ToLower(strings.Trim(f, ",")))
+			}
+		}
+	case "events":
+		if len(fields) == 0 {
+			return "Usage: /events <id or URL…|all>"
+		}
+		sub.EventIDs = nil
+		if !isAll(fields) {
+			for _, f := range fields {
+				sub.EventIDs = append(sub.EventIDs, eventRef(f))
+			}
+		}
+	case "minscore":
+		score, err := strconv.ParseFloat(strings.Join(fields, ""), 64)
+		if err != nil || score < 0 {
+			return "Usage: /minscore <score>, e.g. /minscore 0.05"
+		}
+		sub.MinScore = score
+	case "topk":
+		k, err := strconv.Atoi(strings.Join(fields, ""))
+		if err != nil || k < 0 {
+			return "Usage: /topk <n>, e.g. /topk 3 (0 = all)"
+		}
+		sub.TopK = k
+	}
+
+	if err := store.SaveSubscription(sub); err != nil {
+		logger.Error("Failed to save subscription for chat %d: %v", chatID, err)
+		return errorReply
+	}
+	return "Updated.\n\n" + describeSubscription(sub)
+}
+
+func isAll(fields []string) bool {
+	return len(fields) == 1 && strings.EqualFold(fields[0], "all")
+}
+
+// eventRef normalises an event argument: a Polymarket event URL is reduced to
+// its slug; IDs and slugs are kept as given.
+func eventRef(arg string) string {
+	arg = strings.Trim(arg, ",")
+	if slug := (&models.Event{URL: arg}).Slug(); slug != "" {
+		return slug
+	}
+	return arg
+}
+
+func describeSubscription(sub *models.Subscription) string {
+	orAll := func(values []string) string {
+		if len(values) == 0 {
+			return "all"
+		}
+		return strings.Join(values, ", ")
+	}
+	topK := "all"
+	if sub.TopK > 0 {
+		topK = strconv.Itoa(sub.TopK)
+	}
+	return fmt.Sprintf("Categories: %s\nEvents: %s\nMin score: %g\nTop K: %s",
+		orAll(sub.Categories), orAll(sub.EventIDs), sub.MinScore, topK)
+}
+
+// sendToSubscribers sends each subscribed chat the groups matching its filters,
+// with their charts, and records the deliveries. Chats that are no longer
+// allowed are skipped; chats that blocked the bot or no longer exist are
+// unsubscribed.
+func (c *Client) sendToSubscribers(groups []models.Event, charts []alertChart) {
+	if c.store == nil {
+		return
+	}
+	subs, err := c.store.ListSubscriptions()
+	if err != nil {
+		logger.Warn("Failed to load Telegram subscriptions: %v", err)
+		return
+	}
+	for _, sub := range subs {
+		if sub.ChatID == c.chatID || !c.chatAllowed(sub.ChatID) {
+			continue
+		}
+		matched := sub.Filter(groups)
+		if len(matched) == 0 {
+			continue
+		}
+		messageID, err := c.sendAlert(sub.ChatID, matched)
+		if err != nil {
+			logger.Warn("Failed to send alert to subscriber %d: %v", sub.ChatID, err)
+			if isUnreachable(err) {
+				if err := c.store.DeleteSubscription(sub.ChatID); err == nil {
+					logger.Info("Removed subscription of unreachable chat %d", sub.ChatID)
+				}
+			}
+			continue
+		}
+		c.sendCharts(sub.ChatID, chartsFor(charts, matched), messageID)
+		var ids []string
+		for _, g := range matched {
+			for _, change := range g.Markets {
+				ids = append(ids, change.ID)
+			}
+		}
+		destination := "telegram:" + strconv.FormatInt(sub.ChatID, 10)
+		if err := c.store.RecordDelivery(ids, destination, messageID, time.Now()); err != nil {
+			logger.Warn("Failed to record delivery to %s: %v", destination, err)
+		}
+	}
+}
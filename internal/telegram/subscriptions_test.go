@@ -0,0 +1,236 @@
+package telegram
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rewired-gh/polyoracle/internal/models"
+	"github.com/rewired-gh/polyoracle/internal/storage"
+)
+
+// fakeBotAPI is a minimal Bot API server recording sendMessage calls.
+type fakeBotAPI struct {
+	mu      sync.Mutex
+	sent    map[int64][]string // chat ID -> message texts
+	blocked map[int64]bool     // chats answering 403
+	broken  map[int64]bool     // chats answering 400 for a malformed message
+	nextID  int
+	calls   []fakeCall // every request, in order
+}
+
+// fakeCall is one recorded Bot API request.
+type fakeCall struct {
+	method string
+	form   url.Values
+}
+
+func newFakeBot(t *testing.T) (*tgbotapi.BotAPI, *fakeBotAPI) {
+	t.Helper()
+	f := &fakeBotAPI{sent: map[int64][]string{}, blocked: map[int64]bool{}, broken: map[int64]bool{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = r.ParseMultipartForm(1 << 20) // falls back to ParseForm for url-encoded bodies
+		f.mu.Lock()
+		f.calls = append(f.calls, fakeCall{method: r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], form: r.PostForm})
+		f.mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getMe"):
+			fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"bot","username":"test_bot"}}`)
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			chatID, _ := strconv.ParseInt(r.PostForm.Get("chat_id"), 10, 64)
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if f.blocked[chatID] {
+				fmt.Fprint(w, `{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`)
+				return
+			}
+			if f.broken[chatID] {
+				fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities"}`)
+				return
+			}
+			f.nextID++
+			f.sent[chatID] = append(f.sent[chatID], r.PostForm.Get("text"))
+			fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d,"chat":{"id":%d}}}`, f.nextID, chatID)
+		case strings.HasSuffix(r.URL.Path, "/sendPhoto"):
+			f.mu.Lock()
+			f.nextID++
+			fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d}}`, f.nextID)
+			f.mu.Unlock()
+		case strings.HasSuffix(r.URL.Path, "/sendMediaGroup"):
+			fmt.Fprint(w, `{"ok":true,"result":[{"message_id":1},{"message_id":2}]}`)
+		default:
+			fmt.Fprint(w, `{"ok":true,"result":true}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", srv.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("NewBotAPIWithAPIEndpoint: %v", err)
+	}
+	return bot, f
+}
+
+func (f *fakeBotAPI) messages(chatID int64) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sent[chatID]...)
+}
+
+// lastCall returns the form of the most recent request to method, or nil.
+func (f *fakeBotAPI) lastCall(method string) url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.calls) - 1; i >= 0; i-- {
+		if f.calls[i].method == method {
+			return f.calls[i].form
+		}
+	}
+	return nil
+}
+
+func mustStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	s, err := storage.New(100, 50, ":memory:")
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestHandleSubscriptionCommand(t *testing.T) {
+	store := mustStorage(t)
+	const chat = int64(-100123)
+
+	if reply := handleSubscriptionCommand(store, chat, "topk", "3"); !strings.Contains(reply, "/subscribe first") {
+		t.Errorf("expected prompt to subscribe, got %q", reply)
+	}
+	handleSubscriptionCommand(store, chat, "subscribe", "")
+	handleSubscriptionCommand(store, chat, "categories", "Crypto, tech")
+	handleSubscriptionCommand(store, chat, "events", "https://polymarket.com/event/btc-100k?tid=1 12345")
+	handleSubscriptionCommand(store, chat, "minscore", "0.2")
+	reply := handleSubscriptionCommand(store, chat, "topk", "3")
+	if !strings.Contains(reply, "Categories: crypto, tech") || !strings.Contains(reply, "Events: btc-100k, 12345") {
+		t.Errorf("unexpected settings reply:\n%s", reply)
+	}
+
+	sub, err := store.GetSubscription(chat)
+	if err != nil || sub == nil {
+		t.Fatalf("GetSubscription = %v, %v", sub, err)
+	}
+	if sub.MinScore != 0.2 || sub.TopK != 3 {
+		t.Errorf("unexpected subscription: %+v", sub)
+	}
+
+	if reply := handleSubscriptionCommand(store, chat, "minscore", "-1"); !strings.HasPrefix(reply, "Usage") {
+		t.Errorf("expected usage for negative score, got %q", reply)
+	}
+	handleSubscriptionCommand(store, chat, "categories", "all")
+	if sub, _ := store.GetSubscription(chat); len(sub.Categories) != 0 {
+		t.Errorf("categories not cleared: %v", sub.Categories)
+	}
+	handleSubscriptionCommand(store, chat, "unsubscribe", "")
+	if sub, _ := store.GetSubscription(chat); sub != nil {
+		t.Error("subscription still present after /unsubscribe")
+	}
+}
+
+func TestSend_FansOutToSubscribers(t *testing.T) {
+	bot, api := newFakeBot(t)
+	store := mustStorage(t)
+	c, err := newClient(bot, "100", Options{MaxRetries: 1, RetryDelayBase: time.Millisecond, AllowedChatIDs: []int64{201, 202, 203, 204}, Store: store})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+
+	for _, sub := range []*models.Subscription{
+		{ChatID: 201, Categories: []string{"crypto"}},
+		{ChatID: 202, Categories: []string{"sports"}},
+		{ChatID: 203},
+		{ChatID: 204},
+		{ChatID: 205}, // subscribed before it was removed from the allowed chats
+	} {
+		if err := store.SaveSubscription(sub); err != nil {
+			t.Fatalf("SaveSubscription: %v", err)
+		}
+	}
+	api.blocked[203] = true
+	api.broken[204] = true
+
+	now := time.Now()
+	groups := []models.Event{
+		{ID: "e1", Title: "Crypto event", Category: "crypto", BestScore: 1, Markets: []models.Change{{ID: "c1", EventID: "e1:m1", Direction: "increase", DetectedAt: now}}},
+		{ID: "e2", Title: "World event", Category: "world", BestScore: 1, Markets: []models.Change{{ID: "c2", EventID: "e2:m2", Direction: "increase", DetectedAt: now}}},
+	}
+	if _, err := c.Send(groups); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if main := api.messages(100); len(main) != 1 || !strings.Contains(main[0], "World event") {
+		t.Errorf("configured chat should get every group: %v", main)
+	}
+	crypto := api.messages(201)
+	if len(crypto) != 1 || !strings.Contains(crypto[0], "Crypto event") || strings.Contains(crypto[0], "World event") {
+		t.Errorf("crypto subscriber got %v", crypto)
+	}
+	if got := api.messages(202); len(got) != 0 {
+		t.Errorf("sports subscriber should get nothing, got %v", got)
+	}
+	if sub, _ := store.GetSubscription(203); sub != nil {
+		t.Error("subscription of a chat that blocked the bot should be removed")
+	}
+	if sub, _ := store.GetSubscription(204); sub == nil {
+		t.Error("a formatting error should not remove the subscription")
+	}
+	if got := api.messages(205); len(got) != 0 {
+		t.Errorf("chat that is no longer allowed should get nothing, got %v", got)
+	}
+}
+
+func TestHandleCommand_RefusesChatsNotAllowed(t *testing.T) {
+	bot, api := newFakeBot(t)
+	store := mustStorage(t)
+	c, err := newClient(bot, "100", Options{AllowedChatIDs: []int64{200}, Store: store})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+
+	command := func(chatID int64, text string) {
+		c.handleCommand(&tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(strings.Fields(text)[0])}},
+		})
+	}
+	for _, text := range []string{"/subscribe", "/top", "/status", "/help", "/other_bot_command"} {
+		command(300, text)
+	}
+	command(200, "/subscribe")
+
+	replies := api.messages(300)
+	if len(replies) != 4 {
+		t.Fatalf("want 3 refusals and the help text, got %v", replies)
+	}
+	for _, reply := range replies[:3] {
+		if reply != notAllowedReply {
+			t.Errorf("unexpected reply to a chat that is not allowed: %q", reply)
+		}
+	}
+	if replies[3] != helpText {
+		t.Errorf("/help should work in any chat, got %q", replies[3])
+	}
+	if sub, _ := store.GetSubscription(300); sub != nil {
+		t.Error("chat that is not allowed was subscribed")
+	}
+	if sub, _ := store.GetSubscription(200); sub == nil {
+		t.Error("allowed chat could not subscribe")
+	}
+}
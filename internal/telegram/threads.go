@@ -0,0 +1,266 @@
+package telegram
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/rewired-gh/polyoracle/internal/logger"
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+// Alert threads keep one evolving story per market in the main chat. Every
+// market of an alert is linked to the message that carried it; while the
+// thread is active (reported within threadWindow), later moves of the market
+// are posted as replies to that message:
+//   - Send posts a group as an update when all of its markets are threaded,
+//     e.g. a reversal let through the monitor's cooldown;
+//   - FollowUp posts continuing moves the cooldown suppressed, once they have
+//     gone at least followUpMove further since the last report.
+
+// threadsEnabled reports whether alert threads are configured.
+func (c *Client) threadsEnabled() bool {
+	window, _ := c.threadSettings()
+	return window > 0 && c.store != nil
+}
+
+// SetThreadSettings updates how long alerted markets stay threaded and the
+// further move FollowUp reports, e.g. after a configuration reload. It does
+// not enable threads on a client created without them.
+func (c *Client) SetThreadSettings(window time.Duration, followUpMinMove float64) {
+	c.threadMu.Lock()
+	defer c.threadMu.Unlock()
+	if c.threadWindow == 0 || window <= 0 {
+		return
+	}
+	c.threadWindow, c.followUpMove = window, followUpMinMove
+}
+
+// threadSettings returns the thread window (0 = no threads) and the follow-up move.
+func (c *Client) threadSettings() (window time.Duration, followUpMove float64) {
+	c.threadMu.Lock()
+	defer c.threadMu.Unlock()
+	return c.threadWindow, c.followUpMove
+}
+
+// activeThreads returns the active main-chat threads of the markets in
+// groups, keyed by market key, after expiring stale ones.
+func (c *Client) activeThreads(groups []models.Event, now time.Time) (map[string]models.AlertThread, error) {
+	window, _ := c.threadSettings()
+	cutoff := now.Add(-window)
+	if _, err := c.store.ExpireAlertThreads(cutoff); err != nil {
+		logger.Warn("Failed to expire alert threads: %v", err)
+	}
+	var keys []string
+	for _, g := range groups {
+		for i := range g.Markets {
+			keys = append(keys, g.Markets[i].Key())
+		}
+	}
+	threads, err := c.store.GetAlertThreads(c.chatID, keys)
+	if err != nil {
+		return nil, err
+	}
+	for key, t := range threads {
+		if t.UpdatedAt.Before(cutoff) {
+			delete(threads, key)
+		}
+	}
+	return threads, nil
+}
+
+// splitThreaded separates groups whose markets all have an active thread
+// (updates) from those to send as a new alert (fresh). Without threads, or
+// when they cannot be loaded, every group is fresh.
+func (c *Client) splitThreaded(groups []models.Event, now time.Time) (fresh, updates []models.Event, threads map[string]models.AlertThread) {
+	if !c.threadsEnabled() {
+		return groups, nil, nil
+	}
+	threads, err := c.activeThreads(groups, now)
+	if err != nil {
+		logger.Warn("Failed to load alert threads: %v", err)
+		return groups, nil, nil
+	}
+	for _, g := range groups {
+		threaded := len(g.Markets) > 0
+		for i := range g.Markets {
+			if _, ok := threads[g.Markets[i].Key()]; !ok {
+				threaded = false
+				break
+			}
+		}
+		if threaded {
+			updates = append(updates, g)
+		} else {
+			fresh = append(fresh, g)
+		}
+	}
+	return fresh, updates, threads
+}
+
+// startThreads links every market of the sent alert messages to its message.
+func (c *Client) startThreads(sent []sentChunk, now time.Time) {
+	if !c.threadsEnabled() || len(sent) == 0 {
+		return
+	}
+	var threads []models.AlertThread
+	for _, s := range sent {
+		for _, g := range s.groups {
+			for i := range g.Markets {
+				change := &g.Markets[i]
+				threads = append(threads, models.AlertThread{
+					ChatID:    c.chatID,
+					Key:       change.Key(),
+					MessageID: s.messageID,
+					Direction: change.Direction,
+					StartProb: change.OldProbability,
+					LastProb:  change.NewProbability,
+					StartedAt: now,
+					UpdatedAt: now,
+				})
+			}
+		}
+	}
+	if err := c.store.SaveAlertThreads(threads); err != nil {
+		logger.Warn("Failed to save alert threads: %v", err)
+	}
+}
+
+// sendUpdates posts each group as an update replying to the thread of its
+// best-scoring market, advances the threads of the posted markets, and
+// returns the IDs of the posted messages. Groups that fail are skipped; the
+// last error is returned.
+func (c *Client) sendUpdates(groups []models.Event, threads map[string]models.AlertThread, now time.Time) ([]string, error) {
+	var ids []string
+	var advanced []models.AlertThread
+	var lastErr error
+	for _, g := range groups {
+		root := threads[g.Markets[0].Key()]
+		msg := tgbotapi.NewMessage(c.chatID, formatUpdate(g, threads, now))
+		msg.ParseMode = "MarkdownV2"
+		msg.ReplyToMessageID = root.MessageID
+		msg.AllowSendingWithoutReply = true
+		id, err := c.sendWithRetry(msg)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		ids = append(ids, strconv.Itoa(id))
+		for i := range g.Markets {
+			change := &g.Markets[i]
+			t := threads[change.Key()]
+			t.Direction = change.Direction
+			t.LastProb = change.NewProbability
+			t.UpdatedAt = now
+			advanced = append(advanced, t)
+		}
+	}
+	if len(advanced) > 0 {
+		if err := c.store.SaveAlertThreads(advanced); err != nil {
+			logger.Warn("Failed to update alert threads: %v", err)
+		}
+	}
+	return ids, lastErr
+}
+
+// FollowUp posts moves that continue a recent alert, as suppressed by the
+// monitor's cooldown, as replies to the alert's thread. A market is posted
+// once it has moved at least FollowUpMinMove further in its thread's
+// direction since the last report; the rest are dropped. Posted changes are
+// recorded as delivered to this destination. It implements notify.FollowUpper.
+func (c *Client) FollowUp(groups []models.Event) error {
+	if !c.threadsEnabled() || len(groups) == 0 {
+		return nil
+	}
+	now := time.Now()
+	threads, err := c.activeThreads(groups, now)
+	if err != nil {
+		return fmt.Errorf("failed to load alert threads: %w", err)
+	}
+	_, minMove := c.threadSettings()
+
+	var due []models.Event
+	for _, g := range groups {
+		var moved []models.Change
+		for _, change := range g.Markets {
+			t, ok := threads[change.Key()]
+			if !ok || t.Direction != change.Direction {
+				continue
+			}
+			further := change.NewProbability - t.LastProb
+			if change.Direction == "decrease" {
+				further = -further
+			}
+			if further > 0 && further >= minMove {
+				moved = append(moved, change)
+			}
+		}
+		if len(moved) > 0 {
+			group := g
+			group.Markets = moved
+			due = append(due, group)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	ids, sendErr := c.sendUpdates(due, threads, now)
+	if len(ids) > 0 {
+		var changeIDs []string
+		for _, g := range due {
+			for _, change := range g.Markets {
+				changeIDs = append(changeIDs, change.ID)
+			}
+		}
+		if err := c.store.RecordDelivery(changeIDs, c.Destination(), strings.Join(ids, ","), now); err != nil {
+			logger.Warn("Failed to record follow-up delivery: %v", err)
+		}
+	}
+	if sendErr != nil {
+		return fmt.Errorf("failed to post follow-up: %w", sendErr)
+	}
+	return nil
+}
+
+// formatUpdate formats a thread update for one event group as MarkdownV2:
+// each market's trajectory since its first alert and its move since the last
+// report.
+func formatUpdate(group models.Event, threads map[string]models.AlertThread, now time.Time) string {
+	header := "🔄 *Update*\n\n"
+	title := groupTitleLink(group) + "\n"
+	lines := make([]string, len(group.Markets))
+	for i := range group.Markets {
+		lines[i] = formatThreadMove(&group.Markets[i], group.Title, threads[group.Markets[i].Key()], now)
+	}
+	return header + collapseBlock(title, lines, maxMessageLength-textLength(header))
+}
+
+// formatThreadMove formats one market of a thread update.
+func formatThreadMove(change *models.Change, groupTitle string, t models.AlertThread, now time.Time) string {
+	var message string
+	if change.MarketQuestion != "" && change.MarketQuestion != groupTitle {
+		message += fmt.Sprintf("   🎯 %s\n", escapeMarkdownV2(change.MarketQuestion))
+	}
+	if change.Outcome != "" {
+		message += fmt.Sprintf("   🏷 %s\n", escapeMarkdownV2(change.Outcome))
+	}
+
+	emoji := "📈"
+	if change.Direction == "decrease" {
+		emoji = "📉"
+	}
+	if t.Direction != "" && t.Direction != change.Direction {
+		emoji = "↩️"
+	}
+	move := escapeMarkdownV2(fmt.Sprintf("%.1fpp", math.Abs(change.NewProbability-t.LastProb)*100))
+	trajectory := escapeMarkdownV2(fmt.Sprintf("%.1f%% → %.1f%% → %.1f%%",
+		t.StartProb*100, t.LastProb*100, change.NewProbability*100))
+	since := escapeMarkdownV2(formatDuration(roundDuration(now.Sub(t.StartedAt))))
+	message += fmt.Sprintf("   %s *%s* since last report \\(%s\\) ⏱ %s since first alert\n",
+		emoji, move, trajectory, since)
+	return message
+}
@@ -0,0 +1,132 @@
+package telegram
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rewired-gh/polyoracle/internal/models"
+)
+
+func threadGroup(id, direction string, oldProb, newProb float64) models.Event {
+	return models.Event{ID: "e1", Title: "Bitcoin above 100k?", BestScore: 1, Markets: []models.Change{{
+		ID: id, EventID: "e1", MarketID: "m1", EventTitle: "Bitcoin above 100k?",
+		Direction: direction, OldProbability: oldProb, NewProbability: newProb,
+		Magnitude: newProb - oldProb, SignalScore: 1, TimeWindow: time.Hour, DetectedAt: time.Now(),
+	}}}
+}
+
+func TestSend_ThreadsReversalAsReply(t *testing.T) {
+	bot, api := newFakeBot(t)
+	store := mustStorage(t)
+	c, err := newClient(bot, "100", Options{Store: store, ThreadWindow: time.Hour})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+
+	first, err := c.Send([]models.Event{threadGroup("c1", "increase", 0.40, 0.55)})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	threads, err := store.GetAlertThreads(100, []string{"e1"})
+	if err != nil || threads["e1"].MessageID != mustAtoi(t, first) {
+		t.Fatalf("alert should start a thread on message %s, got %+v (%v)", first, threads, err)
+	}
+
+	reply, err := c.Send([]models.Event{threadGroup("c2", "decrease", 0.55, 0.45)})
+	if err != nil {
+		t.Fatalf("Send reversal: %v", err)
+	}
+	msg := api.lastCall("sendMessage")
+	if msg.Get("reply_to_message_id") != first {
+		t.Fatalf("reversal should reply to alert %s, got %v", first, msg)
+	}
+	text := msg.Get("text")
+	if !strings.Contains(text, "Update") || !strings.Contains(text, "40\\.0% → 55\\.0% → 45\\.0%") {
+		t.Errorf("update should show the trajectory: %s", text)
+	}
+	checkMarkdownV2(t, text)
+
+	threads, _ = store.GetAlertThreads(100, []string{"e1"})
+	if th := threads["e1"]; th.MessageID != mustAtoi(t, first) || th.Direction != "decrease" || th.LastProb != 0.45 {
+		t.Errorf("thread should advance and keep its root message, got %+v (reply %s)", th, reply)
+	}
+}
+
+func TestFollowUp(t *testing.T) {
+	bot, api := newFakeBot(t)
+	store := mustStorage(t)
+	c, err := newClient(bot, "100", Options{Store: store, ThreadWindow: time.Hour, FollowUpMinMove: 0.03})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	first, err := c.Send([]models.Event{threadGroup("c1", "increase", 0.40, 0.55)})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	sent := len(api.messages(100))
+
+	// Too small a further move is dropped
+	if err := c.FollowUp([]models.Event{threadGroup("c2", "increase", 0.42, 0.57)}); err != nil {
+		t.Fatalf("FollowUp: %v", err)
+	}
+	if got := len(api.messages(100)); got != sent {
+		t.Fatalf("a 2pp further move should not be posted, got %d messages", got)
+	}
+
+	follow := threadGroup("c3", "increase", 0.45, 0.62)
+	if err := store.AddChanges(follow.Markets); err != nil {
+		t.Fatalf("AddChanges: %v", err)
+	}
+	if err := c.FollowUp([]models.Event{follow}); err != nil {
+		t.Fatalf("FollowUp: %v", err)
+	}
+	msg := api.lastCall("sendMessage")
+	if msg.Get("reply_to_message_id") != first || !strings.Contains(msg.Get("text"), "55\\.0% → 62\\.0%") {
+		t.Fatalf("follow-up should reply to alert %s with the trajectory, got %v", first, msg)
+	}
+	change, err := store.GetChange("c3")
+	if err != nil || change == nil || len(change.Deliveries) != 1 || change.Deliveries[0].Destination != "telegram:100" {
+		t.Errorf("follow-up should be recorded as delivered, got %+v (%v)", change, err)
+	}
+
+	// Without threads, FollowUp is a no-op
+	plain, _ := newClient(bot, "100", Options{Store: store})
+	before := len(api.messages(100))
+	if err := plain.FollowUp([]models.Event{threadGroup("c4", "increase", 0.5, 0.9)}); err != nil || len(api.messages(100)) != before {
+		t.Errorf("FollowUp without threads should do nothing, got err %v", err)
+	}
+}
+
+func mustAtoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatalf("message ID %q: %v", s, err)
+	}
+	return n
+}
+
+func TestSetThreadSettings(t *testing.T) {
+	bot, _ := newFakeBot(t)
+	store := mustStorage(t)
+
+	c, err := newClient(bot, "100", Options{Store: store, ThreadWindow: time.Hour, FollowUpMinMove: 0.03})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	c.SetThreadSettings(2*time.Hour, 0.05)
+	if window, move := c.threadSettings(); window != 2*time.Hour || move != 0.05 {
+		t.Errorf("threadSettings() = %v, %v; want 2h, 0.05", window, move)
+	}
+
+	off, err := newClient(bot, "100", Options{Store: store})
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	off.SetThreadSettings(2*time.Hour, 0.05)
+	if off.threadsEnabled() {
+		t.Error("SetThreadSettings must not enable threads")
+	}
+}